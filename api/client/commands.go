@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -15,14 +16,17 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"text/tabwriter"
 	"text/template"
 	"time"
 
 	"github.com/docker/docker/api"
+	"github.com/docker/docker/api/stats"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/graph"
@@ -77,6 +81,9 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	noCache := cmd.Bool([]string{"#no-cache", "-no-cache"}, false, "Do not use cache when building the image")
 	rm := cmd.Bool([]string{"#rm", "-rm"}, true, "Remove intermediate containers after a successful build")
 	forceRm := cmd.Bool([]string{"-force-rm"}, false, "Always remove intermediate containers, even after unsuccessful builds")
+	squash := cmd.Bool([]string{"-squash"}, false, "Squash the layers of the build into one layer on top of the FROM image")
+	flBuildArg := opts.NewListOpts(opts.ValidateEnv)
+	cmd.Var(&flBuildArg, []string{"-build-arg"}, "Set build-time variables (e.g. KEY=VALUE), available to RUN and variable expansion")
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
@@ -208,6 +215,23 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		v.Set("forcerm", "1")
 	}
 
+	if *squash {
+		v.Set("squash", "1")
+	}
+
+	if buildArgs := flBuildArg.GetAll(); len(buildArgs) > 0 {
+		buildArgsMap := make(map[string]string, len(buildArgs))
+		for _, arg := range buildArgs {
+			parts := strings.SplitN(arg, "=", 2)
+			buildArgsMap[parts[0]] = parts[1]
+		}
+		buf, err := json.Marshal(buildArgsMap)
+		if err != nil {
+			return err
+		}
+		v.Set("buildargs", string(buf))
+	}
+
 	cli.LoadConfigFile()
 
 	headers := http.Header(make(map[string][]string))
@@ -496,6 +520,37 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 		}
 	}
 
+	var mirrors []struct {
+		Endpoint     string
+		Healthy      bool
+		Failures     int
+		LastError    string
+		RetryAt      time.Time
+		LastPull     string
+		LastPullTime time.Time
+	}
+	if remoteInfo.Exists("RegistryMirrors") {
+		if err := remoteInfo.GetJson("RegistryMirrors", &mirrors); err != nil {
+			return err
+		}
+	}
+	if len(mirrors) > 0 {
+		fmt.Fprintf(cli.out, "Registry Mirrors:\n")
+		for _, m := range mirrors {
+			status := "healthy"
+			if !m.Healthy {
+				status = fmt.Sprintf("%d failures, last error: %s", m.Failures, m.LastError)
+				if retry := m.RetryAt.Sub(time.Now()); retry > 0 {
+					status += fmt.Sprintf(", skipped for %s", units.HumanDuration(retry))
+				}
+			}
+			fmt.Fprintf(cli.out, " %s: %s\n", m.Endpoint, status)
+			if m.LastPull != "" {
+				fmt.Fprintf(cli.out, "  Last pull: %s, %s ago\n", m.LastPull, units.HumanDuration(time.Now().UTC().Sub(m.LastPullTime)))
+			}
+		}
+	}
+
 	if len(remoteInfo.GetList("IndexServerAddress")) != 0 {
 		cli.LoadConfigFile()
 		u := cli.configFile.Configs[remoteInfo.Get("IndexServerAddress")].Username
@@ -732,6 +787,26 @@ func (cli *DockerCli) CmdPause(args ...string) error {
 	return encounteredError
 }
 
+func (cli *DockerCli) CmdRename(args ...string) error {
+	cmd := cli.Subcmd("rename", "OLD_NAME NEW_NAME", "Rename a container")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+	oldName := cmd.Arg(0)
+	newName := cmd.Arg(1)
+
+	if _, _, err := readBody(cli.call("POST", fmt.Sprintf("/containers/%s/rename?name=%s", oldName, url.QueryEscape(newName)), nil, false)); err != nil {
+		fmt.Fprintf(cli.err, "%s\n", err)
+		return fmt.Errorf("Error: failed to rename container named %s", oldName)
+	}
+	return nil
+}
+
 func (cli *DockerCli) CmdInspect(args ...string) error {
 	cmd := cli.Subcmd("inspect", "CONTAINER|IMAGE [CONTAINER|IMAGE...]", "Return low-level information on a container or image")
 	tmplStr := cmd.String([]string{"f", "#format", "-format"}, "", "Format the output using the given go template.")
@@ -847,6 +922,166 @@ func (cli *DockerCli) CmdTop(args ...string) error {
 	return nil
 }
 
+type containerStats struct {
+	Name             string
+	CpuPercentage    float64
+	Memory           float64
+	MemoryLimit      float64
+	MemoryPercentage float64
+	NetworkRx        float64
+	NetworkTx        float64
+	mu               sync.RWMutex
+	err              error
+}
+
+func (s *containerStats) Collect(cli *DockerCli) {
+	stream, _, err := cli.call("GET", "/containers/"+s.Name+"/stats", nil, false)
+	if err != nil {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		return
+	}
+	defer stream.Close()
+	var (
+		previousCpu    uint64
+		previousSystem uint64
+		start          = true
+		dec            = json.NewDecoder(stream)
+	)
+	for {
+		var v *stats.Stats
+		if err := dec.Decode(&v); err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("container is not running")
+			}
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+			return
+		}
+		var (
+			memPercent = 0.0
+			cpuPercent = 0.0
+		)
+		if v.MemoryStats.Limit != 0 {
+			memPercent = float64(v.MemoryStats.Usage) / float64(v.MemoryStats.Limit) * 100.0
+		}
+		if !start {
+			cpuPercent = calculateCpuPercent(previousCpu, previousSystem, v)
+		}
+		start = false
+		s.mu.Lock()
+		s.CpuPercentage = cpuPercent
+		s.Memory = float64(v.MemoryStats.Usage)
+		s.MemoryLimit = float64(v.MemoryStats.Limit)
+		s.MemoryPercentage = memPercent
+		s.NetworkRx = float64(v.Network.RxBytes)
+		s.NetworkTx = float64(v.Network.TxBytes)
+		s.mu.Unlock()
+		previousCpu = v.CpuStats.CpuUsage.TotalUsage
+		previousSystem = v.CpuStats.SystemUsage
+	}
+}
+
+func (s *containerStats) Display(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.err != nil {
+		return s.err
+	}
+	limit := "unlimited"
+	if s.MemoryLimit != 0 {
+		limit = units.HumanSize(int64(s.MemoryLimit))
+	}
+	fmt.Fprintf(w, "%s\t%.2f%%\t%s/%s\t%.2f%%\t%s/%s\n",
+		s.Name,
+		s.CpuPercentage,
+		units.HumanSize(int64(s.Memory)), limit,
+		s.MemoryPercentage,
+		units.HumanSize(int64(s.NetworkRx)), units.HumanSize(int64(s.NetworkTx)))
+	return nil
+}
+
+// calculateCpuPercent returns the share of the host's CPU time the container
+// used between two samples, scaled by the number of cores so that a container
+// saturating two cores reports 200%.
+func calculateCpuPercent(previousCpu, previousSystem uint64, v *stats.Stats) float64 {
+	var (
+		cpuPercent = 0.0
+		// calculate the change for the cpu usage of the container in between readings
+		cpuDelta = float64(v.CpuStats.CpuUsage.TotalUsage) - float64(previousCpu)
+		// calculate the change for the entire system between readings
+		systemDelta = float64(v.CpuStats.SystemUsage) - float64(previousSystem)
+	)
+
+	if systemDelta > 0.0 && cpuDelta > 0.0 {
+		cpuPercent = (cpuDelta / systemDelta) * float64(len(v.CpuStats.CpuUsage.PercpuUsage)) * 100.0
+	}
+	return cpuPercent
+}
+
+func (cli *DockerCli) CmdStats(args ...string) error {
+	cmd := cli.Subcmd("stats", "CONTAINER [CONTAINER...]", "Display a live stream of one or more containers' resource usage statistics")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() == 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	names := cmd.Args()
+	sort.Strings(names)
+	var (
+		cStats []*containerStats
+		w      = tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	)
+	printHeader := func() {
+		// clear the screen and move the cursor to the top left
+		fmt.Fprint(cli.out, "\033[2J")
+		fmt.Fprint(cli.out, "\033[H")
+		fmt.Fprintln(w, "CONTAINER\tCPU %\tMEM USAGE/LIMIT\tMEM %\tNET I/O")
+	}
+	for _, n := range names {
+		s := &containerStats{Name: n}
+		cStats = append(cStats, s)
+		go s.Collect(cli)
+	}
+	// give the collectors a moment so that containers which do not exist or
+	// are not running are reported before anything is drawn
+	time.Sleep(500 * time.Millisecond)
+	var errs []string
+	for _, c := range cStats {
+		c.mu.RLock()
+		if c.err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", c.Name, c.err))
+		}
+		c.mu.RUnlock()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	for _ = range time.Tick(500 * time.Millisecond) {
+		printHeader()
+		toRemove := []int{}
+		for i, s := range cStats {
+			if err := s.Display(w); err != nil {
+				toRemove = append(toRemove, i)
+			}
+		}
+		for j := len(toRemove) - 1; j >= 0; j-- {
+			i := toRemove[j]
+			cStats = append(cStats[:i], cStats[i+1:]...)
+		}
+		if len(cStats) == 0 {
+			return nil
+		}
+		w.Flush()
+	}
+	return nil
+}
+
 func (cli *DockerCli) CmdPort(args ...string) error {
 	cmd := cli.Subcmd("port", "CONTAINER [PRIVATE_PORT[/PROTO]]", "List port mappings for the CONTAINER, or lookup the public-facing port that is NAT-ed to the PRIVATE_PORT")
 	if err := cmd.Parse(args); err != nil {
@@ -885,7 +1120,7 @@ func (cli *DockerCli) CmdPort(args ...string) error {
 		natPort := port + "/" + proto
 		if frontends, exists := ports[nat.Port(port+"/"+proto)]; exists && frontends != nil {
 			for _, frontend := range frontends {
-				fmt.Fprintf(cli.out, "%s:%s\n", frontend.HostIp, frontend.HostPort)
+				fmt.Fprintf(cli.out, "%s\n", net.JoinHostPort(frontend.HostIp, frontend.HostPort))
 			}
 			return nil
 		}
@@ -894,7 +1129,7 @@ func (cli *DockerCli) CmdPort(args ...string) error {
 
 	for from, frontends := range ports {
 		for _, frontend := range frontends {
-			fmt.Fprintf(cli.out, "%s -> %s:%s\n", from, frontend.HostIp, frontend.HostPort)
+			fmt.Fprintf(cli.out, "%s -> %s\n", from, net.JoinHostPort(frontend.HostIp, frontend.HostPort))
 		}
 	}
 
@@ -1127,6 +1362,9 @@ func (cli *DockerCli) CmdPush(args ...string) error {
 	cli.LoadConfigFile()
 
 	remote, tag := parsers.ParseRepositoryTag(name)
+	if graph.IsDigest(tag) {
+		return fmt.Errorf("Pushing by digest is not supported, push a tag of %s instead", remote)
+	}
 
 	// Resolve the Repository name from fqn to hostname + name
 	hostname, _, err := registry.ResolveRepositoryName(remote)
@@ -1178,7 +1416,7 @@ func (cli *DockerCli) CmdPush(args ...string) error {
 }
 
 func (cli *DockerCli) CmdPull(args ...string) error {
-	cmd := cli.Subcmd("pull", "NAME[:TAG]", "Pull an image or a repository from the registry")
+	cmd := cli.Subcmd("pull", "NAME[:TAG|@DIGEST]", "Pull an image or a repository from the registry")
 	allTags := cmd.Bool([]string{"a", "-all-tags"}, false, "Download all tagged images in the repository")
 	if err := cmd.Parse(args); err != nil {
 		return nil
@@ -1248,6 +1486,7 @@ func (cli *DockerCli) CmdImages(args ...string) error {
 	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only show numeric IDs")
 	all := cmd.Bool([]string{"a", "-all"}, false, "Show all images (by default filter out the intermediate image layers)")
 	noTrunc := cmd.Bool([]string{"#notrunc", "-no-trunc"}, false, "Don't truncate output")
+	showDigests := cmd.Bool([]string{"-digests"}, false, "Show the content digests of the images")
 	// FIXME: --viz and --tree are deprecated. Remove them in a future version.
 	flViz := cmd.Bool([]string{"#v", "#viz", "#-viz"}, false, "Output graph in graphviz format")
 	flTree := cmd.Bool([]string{"#t", "#tree", "#-tree"}, false, "Output graph in tree format")
@@ -1379,7 +1618,11 @@ func (cli *DockerCli) CmdImages(args ...string) error {
 
 		w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
 		if !*quiet {
-			fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tVIRTUAL SIZE")
+			if *showDigests {
+				fmt.Fprintln(w, "REPOSITORY\tTAG\tDIGEST\tIMAGE ID\tCREATED\tVIRTUAL SIZE")
+			} else {
+				fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tVIRTUAL SIZE")
+			}
 		}
 
 		for _, out := range outs.Data {
@@ -1392,7 +1635,16 @@ func (cli *DockerCli) CmdImages(args ...string) error {
 				}
 
 				if !*quiet {
-					fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\n", repo, tag, outID, units.HumanDuration(time.Now().UTC().Sub(time.Unix(out.GetInt64("Created"), 0))), units.HumanSize(out.GetInt64("VirtualSize")))
+					if *showDigests {
+						digest := out.Get("Digest")
+						if digest == "" {
+							digest = "<none>"
+						}
+						fmt.Fprintf(w, "%s\t%s\t%s\t", repo, tag, digest)
+					} else {
+						fmt.Fprintf(w, "%s\t%s\t", repo, tag)
+					}
+					fmt.Fprintf(w, "%s\t%s ago\t%s\n", outID, units.HumanDuration(time.Now().UTC().Sub(time.Unix(out.GetInt64("Created"), 0))), units.HumanSize(out.GetInt64("VirtualSize")))
 				} else {
 					fmt.Fprintln(w, outID)
 				}
@@ -1601,6 +1853,7 @@ func (cli *DockerCli) CmdCommit(args ...string) error {
 	flPause := cmd.Bool([]string{"p", "-pause"}, true, "Pause container during commit")
 	flComment := cmd.String([]string{"m", "-message"}, "", "Commit message")
 	flAuthor := cmd.String([]string{"a", "#author", "-author"}, "", "Author (e.g., \"John Hannibal Smith <hannibal@a-team.com>\")")
+	flSquash := cmd.Bool([]string{"-squash"}, false, "Flatten the image, the layers of the container's image included, into one layer")
 	// FIXME: --run is deprecated, it will be replaced with inline Dockerfile commands.
 	flConfig := cmd.String([]string{"#run", "#-run"}, "", "This option is deprecated and will be removed in a future version in favor of inline Dockerfile-compatible commands")
 	if err := cmd.Parse(args); err != nil {
@@ -1635,6 +1888,10 @@ func (cli *DockerCli) CmdCommit(args ...string) error {
 		v.Set("pause", "0")
 	}
 
+	if *flSquash {
+		v.Set("squash", "1")
+	}
+
 	var (
 		config *runconfig.Config
 		env    engine.Env
@@ -1661,6 +1918,8 @@ func (cli *DockerCli) CmdEvents(args ...string) error {
 	cmd := cli.Subcmd("events", "", "Get real time events from the server")
 	since := cmd.String([]string{"#since", "-since"}, "", "Show all events created since timestamp")
 	until := cmd.String([]string{"-until"}, "", "Stream events until this timestamp")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values. Valid filters:\nevent=<string> - event to filter\nimage=<string> - image to filter\ncontainer=<string> - container to filter")
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
@@ -1690,6 +1949,24 @@ func (cli *DockerCli) CmdEvents(args ...string) error {
 	if *until != "" {
 		setTime("until", *until)
 	}
+
+	// Consolidate all filter flags, and sanity check them.
+	// They'll get processed in the daemon.
+	eventFilterArgs := filters.Args{}
+	for _, f := range flFilter.GetAll() {
+		var err error
+		eventFilterArgs, err = filters.ParseFlag(f, eventFilterArgs)
+		if err != nil {
+			return err
+		}
+	}
+	if len(eventFilterArgs) > 0 {
+		filterJson, err := filters.ToParam(eventFilterArgs)
+		if err != nil {
+			return err
+		}
+		v.Set("filters", filterJson)
+	}
 	if err := cli.stream("GET", "/events?"+v.Encode(), nil, cli.out, nil); err != nil {
 		return err
 	}
@@ -2305,7 +2582,7 @@ func (cli *DockerCli) CmdRun(args ...string) error {
 }
 
 func (cli *DockerCli) CmdCp(args ...string) error {
-	cmd := cli.Subcmd("cp", "CONTAINER:PATH HOSTPATH", "Copy files/folders from the PATH to the HOSTPATH")
+	cmd := cli.Subcmd("cp", "CONTAINER:PATH HOSTPATH | HOSTPATH CONTAINER:PATH", "Copy files/folders between a container's PATH and the HOSTPATH")
 	if err := cmd.Parse(args); err != nil {
 		return nil
 	}
@@ -2315,22 +2592,30 @@ func (cli *DockerCli) CmdCp(args ...string) error {
 		return nil
 	}
 
-	var copyData engine.Env
-	info := strings.Split(cmd.Arg(0), ":")
-
-	if len(info) != 2 {
+	srcContainer, srcPath := splitCpArg(cmd.Arg(0))
+	dstContainer, dstPath := splitCpArg(cmd.Arg(1))
+	switch {
+	case srcContainer != "" && dstContainer != "":
+		return fmt.Errorf("Error: copying between containers is not supported")
+	case srcContainer == "" && dstContainer == "":
+		return fmt.Errorf("Error: Path not specified")
+	case dstContainer != "":
+		return cli.copyToContainer(srcPath, dstContainer, dstPath)
+	}
+	if srcPath == "" {
 		return fmt.Errorf("Error: Path not specified")
 	}
 
-	copyData.Set("Resource", info[1])
-	copyData.Set("HostPath", cmd.Arg(1))
+	var copyData engine.Env
+	copyData.Set("Resource", srcPath)
+	copyData.Set("HostPath", dstPath)
 
-	stream, statusCode, err := cli.call("POST", "/containers/"+info[0]+"/copy", copyData, false)
+	stream, statusCode, err := cli.call("POST", "/containers/"+srcContainer+"/copy", copyData, false)
 	if stream != nil {
 		defer stream.Close()
 	}
 	if statusCode == 404 {
-		return fmt.Errorf("No such container: %v", info[0])
+		return fmt.Errorf("No such container: %v", srcContainer)
 	}
 	if err != nil {
 		return err
@@ -2344,6 +2629,85 @@ func (cli *DockerCli) CmdCp(args ...string) error {
 	return nil
 }
 
+// splitCpArg splits a `docker cp` argument into its container and path
+// parts. Absolute and explicitly relative paths are always host paths, so
+// that host paths containing a colon can still be used.
+func splitCpArg(arg string) (container, path string) {
+	if filepath.IsAbs(arg) || strings.HasPrefix(arg, ".") {
+		return "", arg
+	}
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) == 1 {
+		return "", arg
+	}
+	return parts[0], parts[1]
+}
+
+// copyToContainer copies the file or directory srcPath on the host to
+// dstPath in the container. As with cp(1), when dstPath is an existing
+// directory the source is copied into it, otherwise it is copied to
+// dstPath under that name.
+func (cli *DockerCli) copyToContainer(srcPath, container, dstPath string) error {
+	if dstPath == "" {
+		return fmt.Errorf("Error: Path not specified")
+	}
+	dstPath = path.Join("/", dstPath)
+
+	srcStat, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	srcBase := filepath.Base(srcPath)
+
+	var (
+		extractDir = path.Dir(dstPath)
+		dstBase    = path.Base(dstPath)
+	)
+	headers, statusCode, err := cli.head(fmt.Sprintf("/containers/%s/archive?path=%s", container, url.QueryEscape(dstPath)))
+	switch {
+	case statusCode == 404 && strings.HasSuffix(dstPath, "/"):
+		return fmt.Errorf("Error: destination directory %s does not exist in container %s", dstPath, container)
+	case statusCode == 404:
+		// dstPath doesn't exist yet, copy to it under its own name
+	case err != nil:
+		return err
+	default:
+		data, err := base64.StdEncoding.DecodeString(headers.Get("X-Docker-Container-Path-Stat"))
+		if err != nil {
+			return err
+		}
+		var dstStat engine.Env
+		if err := dstStat.Decode(bytes.NewReader(data)); err != nil {
+			return err
+		}
+		if os.FileMode(dstStat.GetInt64("Mode")).IsDir() {
+			extractDir, dstBase = dstPath, srcBase
+		} else if srcStat.IsDir() {
+			return fmt.Errorf("Error: cannot copy directory %s to file %s in container %s", srcPath, dstPath, container)
+		}
+	}
+
+	content, err := archive.TarWithOptions(filepath.Dir(srcPath), &archive.TarOptions{
+		Compression: archive.Uncompressed,
+		Includes:    []string{srcBase},
+	})
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	var in io.Reader = content
+	if dstBase != srcBase {
+		rebased := archive.RebaseArchiveEntries(content, srcBase, dstBase)
+		defer rebased.Close()
+		in = rebased
+	}
+
+	headers = http.Header{}
+	headers.Set("Content-Type", "application/x-tar")
+	return cli.stream("PUT", fmt.Sprintf("/containers/%s/archive?path=%s", container, url.QueryEscape(extractDir)), in, nil, headers)
+}
+
 func (cli *DockerCli) CmdSave(args ...string) error {
 	cmd := cli.Subcmd("save", "IMAGE [IMAGE...]", "Save an image(s) to a tar archive (streamed to STDOUT by default)")
 	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file, instead of STDOUT")
@@ -2510,3 +2874,357 @@ func (cli *DockerCli) CmdExec(args ...string) error {
 
 	return nil
 }
+
+func (cli *DockerCli) CmdVolume(args ...string) error {
+	description := "Manage Docker volumes\n\nCommands:\n"
+	commands := [][]string{
+		{"create", "Create a volume"},
+		{"inspect", "Return low-level information on a volume"},
+		{"ls", "List volumes"},
+		{"rm", "Remove a volume"},
+	}
+	for _, command := range commands {
+		description += fmt.Sprintf("    %-10.10s%s\n", command[0], command[1])
+	}
+	description += "\nRun 'docker volume COMMAND --help' for more information on a command."
+
+	cmd := cli.Subcmd("volume", "COMMAND [OPTIONS] [ARG...]", description)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	cmd.Usage()
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeCreate(args ...string) error {
+	cmd := cli.Subcmd("volume create", "", "Create a volume")
+	flName := cmd.String([]string{"-name"}, "", "Specify volume name")
+	flDriver := cmd.String([]string{"d", "-driver"}, "local", "Specify volume driver name")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	volConfig := map[string]string{"Name": *flName, "Driver": *flDriver}
+	body, _, err := readBody(cli.call("POST", "/volumes/create", volConfig, false))
+	if err != nil {
+		return err
+	}
+
+	out := &engine.Env{}
+	if err := out.Decode(bytes.NewReader(body)); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.out, "%s\n", out.Get("Name"))
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeInspect(args ...string) error {
+	cmd := cli.Subcmd("volume inspect", "VOLUME [VOLUME...]", "Return low-level information on a volume")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	indented := new(bytes.Buffer)
+	indented.WriteByte('[')
+	status := 0
+
+	for _, name := range cmd.Args() {
+		obj, _, err := readBody(cli.call("GET", "/volumes/"+name, nil, false))
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		if err = json.Indent(indented, obj, "", "    "); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		indented.WriteString(",")
+	}
+
+	if indented.Len() > 1 {
+		// Remove trailing ','
+		indented.Truncate(indented.Len() - 1)
+	}
+	indented.WriteString("]\n")
+
+	if _, err := io.Copy(cli.out, indented); err != nil {
+		return err
+	}
+	if status != 0 {
+		return &utils.StatusError{StatusCode: status}
+	}
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeLs(args ...string) error {
+	cmd := cli.Subcmd("volume ls", "", "List volumes")
+	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only display volume names")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"f", "-filter"}, "Provide filter values (i.e. 'dangling=true')")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	v := url.Values{}
+	volFilterArgs := filters.Args{}
+	for _, f := range flFilter.GetAll() {
+		var err error
+		volFilterArgs, err = filters.ParseFlag(f, volFilterArgs)
+		if err != nil {
+			return err
+		}
+	}
+	if len(volFilterArgs) > 0 {
+		filterJson, err := filters.ToParam(volFilterArgs)
+		if err != nil {
+			return err
+		}
+		v.Set("filters", filterJson)
+	}
+
+	body, _, err := readBody(cli.call("GET", "/volumes?"+v.Encode(), nil, false))
+	if err != nil {
+		return err
+	}
+
+	outs := engine.NewTable("Name", 0)
+	if _, err := outs.ReadListFrom(body); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	if !*quiet {
+		fmt.Fprintln(w, "DRIVER\tVOLUME NAME\tCONTAINERS\tPATH")
+	}
+	for _, out := range outs.Data {
+		if *quiet {
+			fmt.Fprintln(w, out.Get("Name"))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", out.Get("Driver"), out.Get("Name"), len(out.GetList("Containers")), out.Get("Path"))
+	}
+	w.Flush()
+	return nil
+}
+
+func (cli *DockerCli) CmdVolumeRm(args ...string) error {
+	cmd := cli.Subcmd("volume rm", "VOLUME [VOLUME...]", "Remove a volume")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	var encounteredError error
+	for _, name := range cmd.Args() {
+		if _, _, err := readBody(cli.call("DELETE", "/volumes/"+name, nil, false)); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			encounteredError = fmt.Errorf("Error: failed to remove one or more volumes")
+		} else {
+			fmt.Fprintf(cli.out, "%s\n", name)
+		}
+	}
+	return encounteredError
+}
+
+func (cli *DockerCli) CmdNetwork(args ...string) error {
+	description := "Manage Docker networks\n\nCommands:\n"
+	commands := [][]string{
+		{"connect", "Connect a container to a network"},
+		{"create", "Create a network"},
+		{"disconnect", "Disconnect a container from a network"},
+		{"inspect", "Return low-level information on a network"},
+		{"ls", "List networks"},
+		{"rm", "Remove a network"},
+	}
+	for _, command := range commands {
+		description += fmt.Sprintf("    %-12.12s%s\n", command[0], command[1])
+	}
+	description += "\nRun 'docker network COMMAND --help' for more information on a command."
+
+	cmd := cli.Subcmd("network", "COMMAND [OPTIONS] [ARG...]", description)
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	cmd.Usage()
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkCreate(args ...string) error {
+	cmd := cli.Subcmd("network create", "NETWORK-NAME", "Create a network")
+	flDriver := cmd.String([]string{"d", "-driver"}, "bridge", "Driver to manage the network")
+	flSubnet := cmd.String([]string{"-subnet"}, "", "Subnet in CIDR format, picked automatically if empty")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	netConfig := map[string]string{"Name": cmd.Arg(0), "Driver": *flDriver, "Subnet": *flSubnet}
+	body, _, err := readBody(cli.call("POST", "/networks/create", netConfig, false))
+	if err != nil {
+		return err
+	}
+
+	out := &engine.Env{}
+	if err := out.Decode(bytes.NewReader(body)); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.out, "%s\n", out.Get("Id"))
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkInspect(args ...string) error {
+	cmd := cli.Subcmd("network inspect", "NETWORK [NETWORK...]", "Return low-level information on a network")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	indented := new(bytes.Buffer)
+	indented.WriteByte('[')
+	status := 0
+
+	for _, name := range cmd.Args() {
+		obj, _, err := readBody(cli.call("GET", "/networks/"+name, nil, false))
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		if err = json.Indent(indented, obj, "", "    "); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		indented.WriteString(",")
+	}
+
+	if indented.Len() > 1 {
+		// Remove trailing ','
+		indented.Truncate(indented.Len() - 1)
+	}
+	indented.WriteString("]\n")
+
+	if _, err := io.Copy(cli.out, indented); err != nil {
+		return err
+	}
+	if status != 0 {
+		return &utils.StatusError{StatusCode: status}
+	}
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkLs(args ...string) error {
+	cmd := cli.Subcmd("network ls", "", "List networks")
+	quiet := cmd.Bool([]string{"q", "-quiet"}, false, "Only display network names")
+	noTrunc := cmd.Bool([]string{"#notrunc", "-no-trunc"}, false, "Don't truncate output")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	body, _, err := readBody(cli.call("GET", "/networks", nil, false))
+	if err != nil {
+		return err
+	}
+
+	outs := engine.NewTable("Name", 0)
+	if _, err := outs.ReadListFrom(body); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	if !*quiet {
+		fmt.Fprintln(w, "NETWORK ID\tNAME\tDRIVER\tSUBNET\tCONTAINERS")
+	}
+	for _, out := range outs.Data {
+		if *quiet {
+			fmt.Fprintln(w, out.Get("Name"))
+			continue
+		}
+		id := out.Get("Id")
+		if !*noTrunc {
+			id = utils.TruncateID(id)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", id, out.Get("Name"), out.Get("Driver"), out.Get("Subnet"), len(out.GetList("Containers")))
+	}
+	w.Flush()
+	return nil
+}
+
+func (cli *DockerCli) CmdNetworkRm(args ...string) error {
+	cmd := cli.Subcmd("network rm", "NETWORK [NETWORK...]", "Remove a network")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() < 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	var encounteredError error
+	for _, name := range cmd.Args() {
+		if _, _, err := readBody(cli.call("DELETE", "/networks/"+name, nil, false)); err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			encounteredError = fmt.Errorf("Error: failed to remove one or more networks")
+		} else {
+			fmt.Fprintf(cli.out, "%s\n", name)
+		}
+	}
+	return encounteredError
+}
+
+func (cli *DockerCli) CmdNetworkConnect(args ...string) error {
+	cmd := cli.Subcmd("network connect", "NETWORK CONTAINER", "Connect a stopped container to a network, which it uses from its next start")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+
+	config := map[string]string{"Container": cmd.Arg(1)}
+	_, _, err := readBody(cli.call("POST", "/networks/"+cmd.Arg(0)+"/connect", config, false))
+	return err
+}
+
+func (cli *DockerCli) CmdNetworkDisconnect(args ...string) error {
+	cmd := cli.Subcmd("network disconnect", "NETWORK CONTAINER", "Disconnect a stopped container from a network, moving it back to the default bridge")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		return nil
+	}
+
+	config := map[string]string{"Container": cmd.Arg(1)}
+	_, _, err := readBody(cli.call("POST", "/networks/"+cmd.Arg(0)+"/disconnect", config, false))
+	return err
+}
@@ -122,6 +122,31 @@ func (cli *DockerCli) call(method, path string, data interface{}, passAuthInfo b
 	return resp.Body, resp.StatusCode, nil
 }
 
+// head sends a HEAD request and returns the response headers, which is
+// where endpoints like /containers/(id)/archive put their result.
+func (cli *DockerCli) head(path string) (http.Header, int, error) {
+	req, err := http.NewRequest("HEAD", fmt.Sprintf("/v%s%s", api.APIVERSION, path), nil)
+	if err != nil {
+		return nil, -1, err
+	}
+	req.Header.Set("User-Agent", "Docker-Client/"+dockerversion.VERSION)
+	req.URL.Host = cli.addr
+	req.URL.Scheme = cli.scheme
+	resp, err := cli.HTTPClient().Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "connection refused") {
+			return nil, -1, ErrConnectionRefused
+		}
+		return nil, -1, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return nil, resp.StatusCode, fmt.Errorf("Error response from daemon: %s", http.StatusText(resp.StatusCode))
+	}
+	return resp.Header, resp.StatusCode, nil
+}
+
 func (cli *DockerCli) stream(method, path string, in io.Reader, out io.Writer, headers map[string][]string) error {
 	return cli.streamHelper(method, path, true, in, out, nil, headers)
 }
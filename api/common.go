@@ -3,12 +3,17 @@ package api
 import (
 	"fmt"
 	"mime"
+	"net"
+	"os"
+	"path"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/pkg/log"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/version"
+	"github.com/docker/libtrust"
 )
 
 const (
@@ -34,12 +39,34 @@ func DisplayablePorts(ports *engine.Table) string {
 		if port.Get("IP") == "" {
 			result = append(result, fmt.Sprintf("%d/%s", port.GetInt("PrivatePort"), port.Get("Type")))
 		} else {
-			result = append(result, fmt.Sprintf("%s:%d->%d/%s", port.Get("IP"), port.GetInt("PublicPort"), port.GetInt("PrivatePort"), port.Get("Type")))
+			hostPort := net.JoinHostPort(port.Get("IP"), strconv.Itoa(port.GetInt("PublicPort")))
+			result = append(result, fmt.Sprintf("%s->%d/%s", hostPort, port.GetInt("PrivatePort"), port.Get("Type")))
 		}
 	}
 	return strings.Join(result, ", ")
 }
 
+// LoadOrCreateTrustKey loads the libtrust private key stored at trustKeyPath,
+// generating and saving a new one if the file does not exist yet.
+func LoadOrCreateTrustKey(trustKeyPath string) (libtrust.PrivateKey, error) {
+	if err := os.MkdirAll(path.Dir(trustKeyPath), 0700); err != nil {
+		return nil, err
+	}
+	trustKey, err := libtrust.LoadKeyFile(trustKeyPath)
+	if err == libtrust.ErrKeyFileDoesNotExist {
+		trustKey, err = libtrust.GenerateECP256PrivateKey()
+		if err != nil {
+			return nil, fmt.Errorf("Error generating key: %s", err)
+		}
+		if err := libtrust.SaveKey(trustKeyPath, trustKey); err != nil {
+			return nil, fmt.Errorf("Error saving key file: %s", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("Error loading key file: %s", err)
+	}
+	return trustKey, nil
+}
+
 func MatchesContentType(contentType, expectedType string) bool {
 	mimetype, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
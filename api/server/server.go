@@ -204,6 +204,21 @@ func postContainersPause(eng *engine.Engine, version version.Version, w http.Res
 	return nil
 }
 
+func postContainerRename(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	job := eng.Job("container_rename", vars["name"], r.Form.Get("name"))
+	if err := job.Run(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func postContainersUnpause(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if vars == nil {
 		return fmt.Errorf("Missing parameter")
@@ -306,9 +321,148 @@ func getEvents(eng *engine.Engine, version version.Version, w http.ResponseWrite
 	streamJSON(job, w, true)
 	job.Setenv("since", r.Form.Get("since"))
 	job.Setenv("until", r.Form.Get("until"))
+	job.Setenv("filters", r.Form.Get("filters"))
+	return job.Run()
+}
+
+func getVolumesJSON(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+
+	var job = eng.Job("volumes")
+	streamJSON(job, w, false)
+	job.Setenv("filters", r.Form.Get("filters"))
 	return job.Run()
 }
 
+func getVolumeByName(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	var job = eng.Job("volume_inspect", vars["name"])
+	streamJSON(job, w, false)
+	return job.Run()
+}
+
+func postVolumesCreate(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+
+	var config engine.Env
+	if err := config.Decode(r.Body); err != nil {
+		return err
+	}
+	job := eng.Job("volume_create")
+	if name := config.Get("Name"); name != "" {
+		job.Args = append(job.Args, name)
+	}
+	job.Setenv("Driver", config.Get("Driver"))
+	out, err := job.Stdout.AddEnv()
+	if err != nil {
+		return err
+	}
+	if err := job.Run(); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, *out)
+}
+
+func deleteVolumes(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	job := eng.Job("volume_rm", vars["name"])
+	if err := job.Run(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func getNetworksJSON(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	var job = eng.Job("networks")
+	streamJSON(job, w, false)
+	return job.Run()
+}
+
+func getNetworkByName(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	var job = eng.Job("network_inspect", vars["name"])
+	streamJSON(job, w, false)
+	return job.Run()
+}
+
+func postNetworksCreate(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+
+	var config engine.Env
+	if err := config.Decode(r.Body); err != nil {
+		return err
+	}
+	job := eng.Job("network_create", config.Get("Name"))
+	job.Setenv("Driver", config.Get("Driver"))
+	job.Setenv("Subnet", config.Get("Subnet"))
+	out, err := job.Stdout.AddEnv()
+	if err != nil {
+		return err
+	}
+	if err := job.Run(); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, *out)
+}
+
+// postNetworkContainer runs the network_connect or network_disconnect job
+// for the network in the URL and the container in the request body.
+func postNetworkContainer(jobName string) HttpApiFunc {
+	return func(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		if vars == nil {
+			return fmt.Errorf("Missing parameter")
+		}
+		if err := parseForm(r); err != nil {
+			return err
+		}
+		if err := checkForJson(r); err != nil {
+			return err
+		}
+
+		var config engine.Env
+		if err := config.Decode(r.Body); err != nil {
+			return err
+		}
+		job := eng.Job(jobName, vars["name"], config.Get("Container"))
+		if err := job.Run(); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+func deleteNetworks(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	job := eng.Job("network_rm", vars["name"])
+	if err := job.Run(); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func getImagesHistory(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if vars == nil {
 		return fmt.Errorf("Missing parameter")
@@ -349,6 +503,19 @@ func getContainersTop(eng *engine.Engine, version version.Version, w http.Respon
 	return job.Run()
 }
 
+func getContainersStats(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+
+	job := eng.Job("container_stats", vars["name"])
+	streamJSON(job, w, true)
+	return job.Run()
+}
+
 func getContainersJSON(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := parseForm(r); err != nil {
 		return err
@@ -482,6 +649,7 @@ func postCommit(eng *engine.Engine, version version.Version, w http.ResponseWrit
 	job.Setenv("tag", r.Form.Get("tag"))
 	job.Setenv("author", r.Form.Get("author"))
 	job.Setenv("comment", r.Form.Get("comment"))
+	job.Setenv("squash", r.Form.Get("squash"))
 	job.SetenvSubEnv("config", &config)
 
 	job.Stdout.Add(stdoutBuffer)
@@ -1013,6 +1181,8 @@ func postBuild(eng *engine.Engine, version version.Version, w http.ResponseWrite
 	job.Setenv("q", r.FormValue("q"))
 	job.Setenv("nocache", r.FormValue("nocache"))
 	job.Setenv("forcerm", r.FormValue("forcerm"))
+	job.Setenv("squash", r.FormValue("squash"))
+	job.Setenv("buildargs", r.FormValue("buildargs"))
 	job.SetenvBool("lineDelim", version.GreaterThanOrEqualTo("1.15"))
 	job.SetenvJson("authConfig", authConfig)
 	job.SetenvJson("configFile", configFile)
@@ -1066,6 +1236,66 @@ func postContainersCopy(eng *engine.Engine, version version.Version, w http.Resp
 	return nil
 }
 
+// archiveJobError turns the "no such file" error of an archive job into one
+// that httpError maps to 404.
+func archiveJobError(err error, name, path string) error {
+	if strings.Contains(err.Error(), "no such file or directory") {
+		return fmt.Errorf("No such file or directory in container %s: %s", name, path)
+	}
+	return err
+}
+
+func headContainersArchive(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	path := r.Form.Get("path")
+	if path == "" {
+		return fmt.Errorf("Bad parameter: path cannot be empty")
+	}
+
+	job := eng.Job("container_stat_path", vars["name"], path)
+	stat, err := job.Stdout.AddEnv()
+	if err != nil {
+		return err
+	}
+	if err := job.Run(); err != nil {
+		return archiveJobError(err, vars["name"], path)
+	}
+
+	var buf bytes.Buffer
+	if err := stat.Encode(&buf); err != nil {
+		return err
+	}
+	w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(bytes.TrimSpace(buf.Bytes())))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func putContainersArchive(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	path := r.Form.Get("path")
+	if path == "" {
+		return fmt.Errorf("Bad parameter: path cannot be empty")
+	}
+
+	job := eng.Job("container_extract_to_dir", vars["name"], path)
+	job.Stdin.Add(r.Body)
+	if err := job.Run(); err != nil {
+		return archiveJobError(err, vars["name"], path)
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
 func postContainerExecCreate(eng *engine.Engine, version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := parseForm(r); err != nil {
 		return nil
@@ -1267,35 +1497,53 @@ func createRouter(eng *engine.Engine, logging, enableCors bool, dockerVersion st
 			"/containers/{name:.*}/changes":   getContainersChanges,
 			"/containers/{name:.*}/json":      getContainersByName,
 			"/containers/{name:.*}/top":       getContainersTop,
+			"/containers/{name:.*}/stats":     getContainersStats,
 			"/containers/{name:.*}/logs":      getContainersLogs,
 			"/containers/{name:.*}/attach/ws": wsContainersAttach,
+			"/volumes":                        getVolumesJSON,
+			"/volumes/{name:.*}":              getVolumeByName,
+			"/networks":                       getNetworksJSON,
+			"/networks/{name:.*}":             getNetworkByName,
 		},
 		"POST": {
-			"/auth":                         postAuth,
-			"/commit":                       postCommit,
-			"/build":                        postBuild,
-			"/images/create":                postImagesCreate,
-			"/images/load":                  postImagesLoad,
-			"/images/{name:.*}/push":        postImagesPush,
-			"/images/{name:.*}/tag":         postImagesTag,
-			"/containers/create":            postContainersCreate,
-			"/containers/{name:.*}/kill":    postContainersKill,
-			"/containers/{name:.*}/pause":   postContainersPause,
-			"/containers/{name:.*}/unpause": postContainersUnpause,
-			"/containers/{name:.*}/restart": postContainersRestart,
-			"/containers/{name:.*}/start":   postContainersStart,
-			"/containers/{name:.*}/stop":    postContainersStop,
-			"/containers/{name:.*}/wait":    postContainersWait,
-			"/containers/{name:.*}/resize":  postContainersResize,
-			"/containers/{name:.*}/attach":  postContainersAttach,
-			"/containers/{name:.*}/copy":    postContainersCopy,
-			"/containers/{name:.*}/exec":    postContainerExecCreate,
-			"/exec/{name:.*}/start":         postContainerExecStart,
-			"/exec/{name:.*}/resize":        postContainerExecResize,
+			"/auth":                          postAuth,
+			"/commit":                        postCommit,
+			"/build":                         postBuild,
+			"/images/create":                 postImagesCreate,
+			"/images/load":                   postImagesLoad,
+			"/images/{name:.*}/push":         postImagesPush,
+			"/images/{name:.*}/tag":          postImagesTag,
+			"/containers/create":             postContainersCreate,
+			"/containers/{name:.*}/kill":     postContainersKill,
+			"/containers/{name:.*}/pause":    postContainersPause,
+			"/containers/{name:.*}/unpause":  postContainersUnpause,
+			"/containers/{name:.*}/restart":  postContainersRestart,
+			"/containers/{name:.*}/start":    postContainersStart,
+			"/containers/{name:.*}/stop":     postContainersStop,
+			"/containers/{name:.*}/wait":     postContainersWait,
+			"/containers/{name:.*}/resize":   postContainersResize,
+			"/containers/{name:.*}/attach":   postContainersAttach,
+			"/containers/{name:.*}/copy":     postContainersCopy,
+			"/containers/{name:.*}/rename":   postContainerRename,
+			"/containers/{name:.*}/exec":     postContainerExecCreate,
+			"/exec/{name:.*}/start":          postContainerExecStart,
+			"/exec/{name:.*}/resize":         postContainerExecResize,
+			"/volumes/create":                postVolumesCreate,
+			"/networks/create":               postNetworksCreate,
+			"/networks/{name:.*}/connect":    postNetworkContainer("network_connect"),
+			"/networks/{name:.*}/disconnect": postNetworkContainer("network_disconnect"),
+		},
+		"PUT": {
+			"/containers/{name:.*}/archive": putContainersArchive,
+		},
+		"HEAD": {
+			"/containers/{name:.*}/archive": headContainersArchive,
 		},
 		"DELETE": {
 			"/containers/{name:.*}": deleteContainers,
 			"/images/{name:.*}":     deleteImages,
+			"/volumes/{name:.*}":    deleteVolumes,
+			"/networks/{name:.*}":   deleteNetworks,
 		},
 		"OPTIONS": {
 			"": optionsHandler,
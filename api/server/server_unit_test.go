@@ -2,9 +2,11 @@ package server
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -250,6 +252,48 @@ func TestGetContainersByName(t *testing.T) {
 	}
 }
 
+func TestGetContainersStats(t *testing.T) {
+	eng := engine.New()
+	name := "container_name"
+	var called bool
+	eng.Register("container_stats", func(job *engine.Job) engine.Status {
+		called = true
+		if job.Args[0] != name {
+			t.Errorf("name != '%s': %#v", name, job.Args[0])
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := fmt.Fprintf(job.Stdout, "{\"read\":\"2014-10-17T19:21:5%d.254052384Z\"}\n", i); err != nil {
+				return job.Error(err)
+			}
+		}
+		return engine.StatusOK
+	})
+	r := serveRequest("GET", "/containers/"+name+"/stats", nil, eng, t)
+	if !called {
+		t.Fatal("handler was not called")
+	}
+	assertContentType(r, "application/json", t)
+	var (
+		dec     = json.NewDecoder(r.Body)
+		samples int
+	)
+	for {
+		var v map[string]interface{}
+		if err := dec.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := v["read"]; !ok {
+			t.Fatalf("sample %d has no read time: %#v", samples, v)
+		}
+		samples++
+	}
+	if samples != 2 {
+		t.Fatalf("Expected 2 samples, got %d", samples)
+	}
+}
+
 func TestGetEvents(t *testing.T) {
 	eng := engine.New()
 	var called bool
@@ -474,6 +518,110 @@ func TestDeleteContainers(t *testing.T) {
 	}
 }
 
+func TestPostContainerRename(t *testing.T) {
+	eng := engine.New()
+	oldName, newName := "foo", "bar"
+	var called bool
+	eng.Register("container_rename", func(job *engine.Job) engine.Status {
+		called = true
+		if len(job.Args) != 2 {
+			t.Fatalf("Expected 2 job arguments, got %#v", job.Args)
+		}
+		if job.Args[0] != oldName || job.Args[1] != newName {
+			t.Fatalf("Expected rename from '%s' to '%s': %#v", oldName, newName, job.Args)
+		}
+		return engine.StatusOK
+	})
+	r := serveRequest("POST", "/containers/"+oldName+"/rename?name="+newName, bytes.NewReader(nil), eng, t)
+	if !called {
+		t.Fatalf("handler was not called")
+	}
+	if r.Code != http.StatusNoContent {
+		t.Fatalf("Got status %d, expected %d", r.Code, http.StatusNoContent)
+	}
+}
+
+func TestPostNetworkConnect(t *testing.T) {
+	eng := engine.New()
+	var called bool
+	eng.Register("network_connect", func(job *engine.Job) engine.Status {
+		called = true
+		if len(job.Args) != 2 || job.Args[0] != "staging" || job.Args[1] != "web" {
+			t.Fatalf("Expected to connect web to staging: %#v", job.Args)
+		}
+		return engine.StatusOK
+	})
+	req, err := http.NewRequest("POST", "/networks/staging/connect", strings.NewReader(`{"Container":"web"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	if err := ServeRequest(eng, api.APIVERSION, r, req); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatalf("handler was not called")
+	}
+	if r.Code != http.StatusNoContent {
+		t.Fatalf("Got status %d, expected %d", r.Code, http.StatusNoContent)
+	}
+}
+
+func TestContainersArchive(t *testing.T) {
+	eng := engine.New()
+	var extracted string
+	eng.Register("container_extract_to_dir", func(job *engine.Job) engine.Status {
+		if len(job.Args) != 2 || job.Args[0] != "web" || job.Args[1] != "/etc" {
+			t.Fatalf("Expected to extract to web:/etc: %#v", job.Args)
+		}
+		data, err := ioutil.ReadAll(job.Stdin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		extracted = string(data)
+		return engine.StatusOK
+	})
+	eng.Register("container_stat_path", func(job *engine.Job) engine.Status {
+		if job.Args[1] != "/etc" {
+			return job.Errorf("stat %s: no such file or directory", job.Args[1])
+		}
+		v := &engine.Env{}
+		v.Set("Name", "etc")
+		v.SetInt64("Size", 4096)
+		if _, err := v.WriteTo(job.Stdout); err != nil {
+			return job.Error(err)
+		}
+		return engine.StatusOK
+	})
+
+	r := serveRequest("PUT", "/containers/web/archive?path=/etc", strings.NewReader("tar data"), eng, t)
+	if r.Code != http.StatusOK {
+		t.Fatalf("Got status %d, expected %d", r.Code, http.StatusOK)
+	}
+	if extracted != "tar data" {
+		t.Fatalf("Expected the request body to be extracted, got %q", extracted)
+	}
+
+	r = serveRequest("HEAD", "/containers/web/archive?path=/etc", nil, eng, t)
+	if r.Code != http.StatusOK {
+		t.Fatalf("Got status %d, expected %d", r.Code, http.StatusOK)
+	}
+	data, err := base64.StdEncoding.DecodeString(r.Header().Get("X-Docker-Container-Path-Stat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := readEnv(bytes.NewReader(data), t)
+	if stat.Get("Name") != "etc" || stat.GetInt64("Size") != 4096 {
+		t.Fatalf("Unexpected path stat: %v", stat)
+	}
+
+	r = serveRequest("HEAD", "/containers/web/archive?path=/missing", nil, eng, t)
+	if r.Code != http.StatusNotFound {
+		t.Fatalf("Got status %d, expected %d", r.Code, http.StatusNotFound)
+	}
+}
+
 func serveRequest(method, target string, body io.Reader, eng *engine.Engine, t *testing.T) *httptest.ResponseRecorder {
 	return serveRequestUsingVersion(method, target, api.APIVERSION, body, eng, t)
 }
@@ -11,11 +11,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/nat"
 	"github.com/docker/docker/pkg/log"
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -47,6 +50,68 @@ func env(b *Builder, args []string, attributes map[string]bool) error {
 	return b.commit("", b.Config.Cmd, fmt.Sprintf("ENV %s", fullEnv))
 }
 
+// ARG name[=default]
+//
+// Declares a build-time variable. Its value comes from --build-arg when the
+// client supplied one, otherwise from the default. ARG values are available to
+// RUN and to variable expansion in later instructions, but are not persisted
+// in the image config.
+func arg(b *Builder, args []string, attributes map[string]bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("ARG requires exactly one argument definition")
+	}
+
+	var (
+		name       = args[0]
+		value      string
+		hasDefault bool
+	)
+	if strings.Contains(name, "=") {
+		parts := strings.SplitN(name, "=", 2)
+		name, value, hasDefault = parts[0], parts[1], true
+	}
+	if name == "" {
+		return fmt.Errorf("ARG requires a name")
+	}
+
+	if v, ok := b.BuildArgs[name]; ok {
+		b.declaredArgs[name] = v
+	} else if hasDefault {
+		b.declaredArgs[name] = value
+	}
+
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("ARG %s", args[0]))
+}
+
+// LABEL key=value [key=value...]
+//
+// Sets metadata labels on the image. Labels are kept in the image config
+// and inherited by containers and by images built on top of it.
+func label(b *Builder, args []string, attributes map[string]bool) error {
+	if len(args) == 0 {
+		return fmt.Errorf("LABEL requires at least one argument")
+	}
+	if len(args)%2 != 0 {
+		// should never get here, but just in case
+		return fmt.Errorf("Bad input to LABEL, too many args")
+	}
+
+	// copy the labels, so that the config of the parent image is untouched
+	labels := make(map[string]string, len(b.Config.Labels)+len(args)/2)
+	for k, v := range b.Config.Labels {
+		labels[k] = v
+	}
+
+	commitStr := "LABEL"
+	for j := 0; j < len(args); j += 2 {
+		labels[args[j]] = args[j+1]
+		commitStr += fmt.Sprintf(" %s=%s", args[j], strconv.Quote(args[j+1]))
+	}
+	b.Config.Labels = labels
+
+	return b.commit("", b.Config.Cmd, commitStr)
+}
+
 // MAINTAINER some text <maybe@an.email.address>
 //
 // Sets the maintainer metadata.
@@ -77,6 +142,13 @@ func add(b *Builder, args []string, attributes map[string]bool) error {
 // Same as 'ADD' but without the tar and remote url handling.
 //
 func dispatchCopy(b *Builder, args []string, attributes map[string]bool) error {
+	if len(args) > 0 && strings.HasPrefix(args[0], "--from=") {
+		if len(args) < 3 {
+			return fmt.Errorf("COPY requires at least two arguments")
+		}
+		return b.copyFromImage(strings.TrimPrefix(args[0], "--from="), args[1:])
+	}
+
 	if len(args) < 2 {
 		return fmt.Errorf("COPY requires at least two arguments")
 	}
@@ -84,17 +156,51 @@ func dispatchCopy(b *Builder, args []string, attributes map[string]bool) error {
 	return b.runContextCommand(args, false, false, "COPY")
 }
 
-// FROM imagename
+// FROM imagename [AS name]
 //
-// This sets the image the dockerfile will build on top of.
+// This sets the image the dockerfile will build on top of. A Dockerfile may
+// contain several FROM instructions; each one starts a new build stage, which
+// later stages can copy files out of with COPY --from.
 //
 func from(b *Builder, args []string, attributes map[string]bool) error {
-	if len(args) != 1 {
-		return fmt.Errorf("FROM requires one argument")
+	var stageName string
+	switch {
+	case len(args) == 1:
+	case len(args) == 3 && strings.EqualFold(args[1], "as"):
+		stageName = strings.ToLower(args[2])
+		if _, exists := b.stageNames[stageName]; exists || stageName == b.stageName {
+			return fmt.Errorf("Duplicate build stage name: %s", args[2])
+		}
+		if _, err := strconv.Atoi(stageName); err == nil {
+			return fmt.Errorf("Invalid build stage name %s: names can not be numbers", args[2])
+		}
+	default:
+		return fmt.Errorf("FROM requires either one argument, or three: FROM <image> AS <name>")
 	}
 
 	name := args[0]
 
+	// finish the previous stage, if any, and start over with a clean config
+	if b.image != "" {
+		b.stages = append(b.stages, b.image)
+		if b.stageName != "" {
+			b.stageNames[b.stageName] = len(b.stages) - 1
+		}
+		b.Config = &runconfig.Config{Entrypoint: []string{}, Cmd: nil}
+		b.maintainer = ""
+		b.cmdSet = false
+	}
+	b.stageName = stageName
+
+	// an earlier stage can be used as the base of a later one
+	if idx, ok := b.stageNames[strings.ToLower(name)]; ok {
+		image, err := b.Daemon.Graph().Get(b.stages[idx])
+		if err != nil {
+			return err
+		}
+		return b.processImageFrom(image)
+	}
+
 	image, err := b.Daemon.Repositories().LookupImage(name)
 	if err != nil {
 		if b.Daemon.Graph().IsNotExist(err) {
@@ -194,6 +300,12 @@ func run(b *Builder, args []string, attributes map[string]bool) error {
 
 	defer func(cmd []string) { b.Config.Cmd = cmd }(cmd)
 
+	// ARG values are part of the RUN container's environment, and therefore
+	// of the cache key, but they are not kept in the committed image config.
+	env := b.Config.Env
+	b.Config.Env = b.buildArgsEnv()
+	defer func(env []string) { b.Config.Env = env }(env)
+
 	log.Debugf("Command to be executed: %v", b.Config.Cmd)
 
 	hit, err := b.probeCache()
@@ -218,6 +330,7 @@ func run(b *Builder, args []string, attributes map[string]bool) error {
 	if err != nil {
 		return err
 	}
+	b.Config.Env = env
 	if err := b.commit(c.ID, cmd, "run"); err != nil {
 		return err
 	}
@@ -271,6 +384,81 @@ func entrypoint(b *Builder, args []string, attributes map[string]bool) error {
 	return nil
 }
 
+// HEALTHCHECK [--interval=30s] [--timeout=30s] [--retries=3] CMD /check.sh
+// HEALTHCHECK NONE
+//
+// Set the command the daemon runs in the container to check that it is
+// healthy. The command is handled like the one of RUN, except that a string
+// is run with /bin/sh -c even when there is an ENTRYPOINT. NONE disables the
+// check of the base image.
+//
+func healthcheck(b *Builder, args []string, attributes map[string]bool) error {
+	health := &runconfig.HealthConfig{}
+
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		parts := strings.SplitN(strings.TrimPrefix(args[0], "--"), "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("HEALTHCHECK option %s requires a value", args[0])
+		}
+		var err error
+		switch parts[0] {
+		case "interval":
+			health.Interval, err = parseHealthDuration(parts[1])
+		case "timeout":
+			health.Timeout, err = parseHealthDuration(parts[1])
+		case "retries":
+			health.Retries, err = strconv.Atoi(parts[1])
+			if err == nil && health.Retries < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		default:
+			return fmt.Errorf("Unknown HEALTHCHECK option %s", args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid HEALTHCHECK %s: %v", args[0], err)
+		}
+		args = args[1:]
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("HEALTHCHECK requires NONE or CMD")
+	}
+
+	switch args[0] {
+	case "NONE":
+		if len(args) != 1 {
+			return fmt.Errorf("HEALTHCHECK NONE takes no arguments")
+		}
+		health.Test = []string{"NONE"}
+	case "CMD":
+		if len(args) == 1 {
+			return fmt.Errorf("HEALTHCHECK CMD requires a command")
+		}
+		if attributes["json"] {
+			health.Test = append([]string{"CMD"}, args[1:]...)
+		} else {
+			health.Test = []string{"CMD-SHELL", strings.Join(args[1:], " ")}
+		}
+	default:
+		return fmt.Errorf("Unknown type %q in HEALTHCHECK (try CMD)", args[0])
+	}
+
+	b.Config.Healthcheck = health
+
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("HEALTHCHECK %v", health.Test))
+}
+
+func parseHealthDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
 // EXPOSE 6667/tcp 7000/tcp
 //
 // Expose ports for links and port mappings. This all ends up in
@@ -312,6 +500,25 @@ func user(b *Builder, args []string, attributes map[string]bool) error {
 	return b.commit("", b.Config.Cmd, fmt.Sprintf("USER %v", args))
 }
 
+// STOPSIGNAL signal
+//
+// Set the signal that will be used to stop the container, by name like
+// SIGQUIT or by number.
+//
+func stopSignal(b *Builder, args []string, attributes map[string]bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("STOPSIGNAL requires exactly one argument")
+	}
+
+	sig := args[0]
+	if _, err := signal.ParseSignal(sig); err != nil {
+		return err
+	}
+
+	b.Config.StopSignal = sig
+	return b.commit("", b.Config.Cmd, fmt.Sprintf("STOPSIGNAL %v", args))
+}
+
 // VOLUME /foo
 //
 // Expose the volume /foo for use. Will also accept the JSON array form.
@@ -25,6 +25,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/docker/docker/builder/parser"
@@ -46,6 +47,8 @@ var evaluateTable map[string]func(*Builder, []string, map[string]bool) error
 func init() {
 	evaluateTable = map[string]func(*Builder, []string, map[string]bool) error{
 		"env":            env,
+		"arg":            arg,
+		"label":          label,
 		"maintainer":     maintainer,
 		"add":            add,
 		"copy":           dispatchCopy, // copy() is a go builtin
@@ -56,9 +59,11 @@ func init() {
 		"run":            run,
 		"cmd":            cmd,
 		"entrypoint":     entrypoint,
+		"healthcheck":    healthcheck,
 		"expose":         expose,
 		"volume":         volume,
 		"user":           user,
+		"stopsignal":     stopSignal,
 		"insert":         insert,
 	}
 }
@@ -81,6 +86,9 @@ type Builder struct {
 	Remove      bool
 	ForceRemove bool
 
+	// merge the layers of the build into one layer on top of the FROM image
+	Squash bool
+
 	AuthConfig     *registry.AuthConfig
 	AuthConfigFile *registry.ConfigFile
 
@@ -90,15 +98,24 @@ type Builder struct {
 
 	Config *runconfig.Config // runconfig for cmd, run, entrypoint etc.
 
+	// build-time variables supplied by the client with --build-arg. Only the
+	// ones declared with ARG in the Dockerfile are used.
+	BuildArgs map[string]string
+
 	// both of these are controlled by the Remove and ForceRemove options in BuildOpts
 	TmpContainers map[string]struct{} // a map of containers used for removes
 
-	dockerfile  *parser.Node  // the syntax tree of the dockerfile
-	image       string        // image name for commit processing
-	maintainer  string        // maintainer name. could probably be removed.
-	cmdSet      bool          // indicates is CMD was set in current Dockerfile
-	context     tarsum.TarSum // the context is a tarball that is uploaded by the client
-	contextPath string        // the path of the temporary directory the local context is unpacked to (server side)
+	dockerfile   *parser.Node      // the syntax tree of the dockerfile
+	image        string            // image name for commit processing
+	fromImage    string            // image ID of the FROM of the current build stage
+	maintainer   string            // maintainer name. could probably be removed.
+	cmdSet       bool              // indicates is CMD was set in current Dockerfile
+	context      tarsum.TarSum     // the context is a tarball that is uploaded by the client
+	contextPath  string            // the path of the temporary directory the local context is unpacked to (server side)
+	declaredArgs map[string]string // ARG values available to the remaining instructions
+	stageName    string            // name of the current build stage, set with FROM ... AS
+	stages       []string          // image IDs of the completed build stages, in order
+	stageNames   map[string]int    // index into stages by stage name
 
 }
 
@@ -152,6 +169,9 @@ func (b *Builder) Run(context io.Reader) (string, error) {
 	// some initializations that would not have been supplied by the caller.
 	b.Config = &runconfig.Config{Entrypoint: []string{}, Cmd: nil}
 	b.TmpContainers = map[string]struct{}{}
+	b.declaredArgs = map[string]string{}
+	b.stages = []string{}
+	b.stageNames = map[string]int{}
 
 	for i, n := range b.dockerfile.Children {
 		if err := b.dispatch(i, n); err != nil {
@@ -170,6 +190,23 @@ func (b *Builder) Run(context io.Reader) (string, error) {
 		return "", fmt.Errorf("No image was generated. Is your Dockerfile empty?\n")
 	}
 
+	if b.Squash && b.image != b.fromImage {
+		if err := b.squash(); err != nil {
+			return "", err
+		}
+	}
+
+	unused := []string{}
+	for key := range b.BuildArgs {
+		if _, ok := b.declaredArgs[key]; !ok {
+			unused = append(unused, key)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return "", fmt.Errorf("One or more build-args %v were not consumed, failing build.", unused)
+	}
+
 	fmt.Fprintf(b.OutStream, "Successfully built %s\n", utils.TruncateID(b.image))
 	return b.image, nil
 }
@@ -14,6 +14,7 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/tarsum"
 	"github.com/docker/docker/registry"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
 
@@ -92,7 +94,7 @@ func (b *Builder) commit(id string, autoCmd []string, comment string) error {
 	autoConfig.Cmd = autoCmd
 
 	// Commit the container
-	image, err := b.Daemon.Commit(container, "", "", "", b.maintainer, true, &autoConfig)
+	image, err := b.Daemon.Commit(container, "", "", "", b.maintainer, true, false, &autoConfig)
 	if err != nil {
 		return err
 	}
@@ -100,6 +102,26 @@ func (b *Builder) commit(id string, autoCmd []string, comment string) error {
 	return nil
 }
 
+// squash merges the layers committed by the build on top of the FROM image
+// of the last build stage into one layer. The intermediate images stay in
+// the build cache.
+func (b *Builder) squash() error {
+	img, err := b.Daemon.Graph().Get(b.image)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(b.OutStream, "Squashing the layers above %s\n", utils.TruncateID(b.fromImage))
+	squashed, err := b.Daemon.Graph().Squash(img, b.fromImage)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(b.OutStream, " ---> %s\n", utils.TruncateID(squashed.ID))
+
+	b.image = squashed.ID
+	return nil
+}
+
 type copyInfo struct {
 	origPath   string
 	destPath   string
@@ -195,7 +217,7 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowDecomp
 	defer container.Unmount()
 
 	for _, ci := range copyInfos {
-		if err := b.addContext(container, ci.origPath, ci.destPath, ci.decompress); err != nil {
+		if err := b.addContext(container, b.contextPath, ci.origPath, ci.destPath, ci.decompress); err != nil {
 			return err
 		}
 	}
@@ -206,6 +228,117 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowDecomp
 	return nil
 }
 
+// copyFromImage implements COPY --from=<stage|image>. The sources are paths
+// in the root filesystem of an earlier build stage, or of any image, rather
+// than in the build context.
+func (b *Builder) copyFromImage(from string, args []string) error {
+	srcImage, err := b.lookupStage(from)
+	if err != nil {
+		return err
+	}
+
+	dest := args[len(args)-1] // last one is always the dest
+	srcs := args[:len(args)-1]
+
+	if len(srcs) > 1 && !strings.HasSuffix(dest, "/") {
+		return fmt.Errorf("When using COPY with more than one source file, the destination must be a directory and end with a /")
+	}
+
+	b.Config.Image = b.image
+
+	// The image IDs of the stages only change when their content does, so
+	// the source image and paths are enough to identify the copied files.
+	origPaths := strings.Join(srcs, " ")
+	cmd := b.Config.Cmd
+	b.Config.Cmd = []string{"/bin/sh", "-c", fmt.Sprintf("#(nop) COPY from:%s %s in %s", srcImage.ID, origPaths, dest)}
+	defer func(cmd []string) { b.Config.Cmd = cmd }(cmd)
+
+	hit, err := b.probeCache()
+	if err != nil {
+		return err
+	}
+	if hit {
+		return nil
+	}
+
+	// The source image is mounted through a throw-away container, which is
+	// removed together with the other intermediate containers.
+	src, _, err := b.Daemon.Create(&runconfig.Config{Image: srcImage.ID, Cmd: b.Config.Cmd}, nil, "")
+	if err != nil {
+		return err
+	}
+	b.TmpContainers[src.ID] = struct{}{}
+
+	if err := src.Mount(); err != nil {
+		return err
+	}
+	defer src.Unmount()
+
+	container, _, err := b.Daemon.Create(b.Config, nil, "")
+	if err != nil {
+		return err
+	}
+	b.TmpContainers[container.ID] = struct{}{}
+
+	if err := container.Mount(); err != nil {
+		return err
+	}
+	defer container.Unmount()
+
+	root := src.RootfsPath()
+	for _, orig := range srcs {
+		matches := []string{path.Join(root, orig)}
+		if ContainsWildcards(orig) {
+			if matches, err = filepath.Glob(path.Join(root, orig)); err != nil {
+				return err
+			}
+			if len(matches) == 0 {
+				return fmt.Errorf("%s: no such file or directory", orig)
+			}
+		}
+
+		for _, match := range matches {
+			// don't let symlinks in the source image escape its root filesystem
+			resolved, err := symlink.FollowSymlinkInScope(match, root)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, resolved)
+			if err != nil {
+				return err
+			}
+			if err := b.addContext(container, root, rel, dest, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return b.commit(container.ID, cmd, fmt.Sprintf("COPY --from=%s %s in %s", from, origPaths, dest))
+}
+
+// lookupStage resolves the argument of COPY --from: the name or index of an
+// earlier build stage, or otherwise an image, which is pulled if needed.
+func (b *Builder) lookupStage(name string) (*imagepkg.Image, error) {
+	if idx, ok := b.stageNames[strings.ToLower(name)]; ok {
+		return b.Daemon.Graph().Get(b.stages[idx])
+	}
+	if idx, err := strconv.Atoi(name); err == nil {
+		if idx < 0 || idx >= len(b.stages) {
+			return nil, fmt.Errorf("Invalid build stage index %d: only %d earlier stages", idx, len(b.stages))
+		}
+		return b.Daemon.Graph().Get(b.stages[idx])
+	}
+
+	image, err := b.Daemon.Repositories().LookupImage(name)
+	if err != nil {
+		if !b.Daemon.Graph().IsNotExist(err) {
+			return nil, err
+		}
+		return b.pullImage(name)
+	}
+	return image, nil
+}
+
 func calcCopyInfo(b *Builder, cmdName string, cInfos *[]*copyInfo, origPath string, destPath string, allowRemote bool, allowDecompression bool) error {
 
 	if origPath != "" && origPath[0] == '/' && len(origPath) > 1 {
@@ -418,6 +551,7 @@ func (b *Builder) pullImage(name string) (*imagepkg.Image, error) {
 
 func (b *Builder) processImageFrom(img *imagepkg.Image) error {
 	b.image = img.ID
+	b.fromImage = img.ID
 
 	if img.Config != nil {
 		b.Config = img.Config
@@ -571,11 +705,12 @@ func (b *Builder) checkPathForAddition(orig string) error {
 	return nil
 }
 
-func (b *Builder) addContext(container *daemon.Container, orig, dest string, decompress bool) error {
+// addContext copies orig, relative to root, to dest in the container.
+func (b *Builder) addContext(container *daemon.Container, root, orig, dest string, decompress bool) error {
 	var (
 		err        error
 		destExists = true
-		origPath   = path.Join(b.contextPath, orig)
+		origPath   = path.Join(root, orig)
 		destPath   = path.Join(container.RootfsPath(), dest)
 	)
 
@@ -36,6 +36,7 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 		noCache        = job.GetenvBool("nocache")
 		rm             = job.GetenvBool("rm")
 		forceRm        = job.GetenvBool("forcerm")
+		squash         = job.GetenvBool("squash")
 		authConfig     = &registry.AuthConfig{}
 		configFile     = &registry.ConfigFile{}
 		tag            string
@@ -44,6 +45,9 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 	job.GetenvJson("authConfig", authConfig)
 	job.GetenvJson("configFile", configFile)
 
+	buildArgs := map[string]string{}
+	job.GetenvJson("buildargs", &buildArgs)
+
 	repoName, tag = parsers.ParseRepositoryTag(repoName)
 	if repoName != "" {
 		if _, _, err := registry.ResolveRepositoryName(repoName); err != nil {
@@ -112,10 +116,12 @@ func (b *BuilderJob) CmdBuild(job *engine.Job) engine.Status {
 		UtilizeCache:    !noCache,
 		Remove:          rm,
 		ForceRemove:     forceRm,
+		Squash:          squash,
 		OutOld:          job.Stdout,
 		StreamFormatter: sf,
 		AuthConfig:      authConfig,
 		AuthConfigFile:  configFile,
+		BuildArgs:       buildArgs,
 	}
 
 	id, err := builder.Run(context)
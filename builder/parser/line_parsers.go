@@ -56,6 +56,96 @@ func parseEnv(rest string) (*Node, map[string]bool, error) {
 	return rootnode, nil, nil
 }
 
+// parse LABEL statements, which are either a single "key value" pair like
+// ENV, or any number of key=value pairs. Values of key=value pairs may be
+// quoted to contain whitespace. The result is a list of alternating keys and
+// values.
+func parseLabel(rest string) (*Node, map[string]bool, error) {
+	words, err := splitQuotedWords(rest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(words) == 0 {
+		return nil, nil, fmt.Errorf("LABEL requires at least one argument")
+	}
+
+	var pairs []string
+	if !strings.Contains(words[0], "=") {
+		strs := TOKEN_WHITESPACE.Split(rest, 2)
+		if len(strs) < 2 {
+			return nil, nil, fmt.Errorf("LABEL must have two arguments")
+		}
+		pairs = strs
+	} else {
+		for _, word := range words {
+			parts := strings.SplitN(word, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return nil, nil, fmt.Errorf("Syntax error - can't find = in %q. Must be of the form: name=value", word)
+			}
+			pairs = append(pairs, parts...)
+		}
+	}
+
+	node := &Node{}
+	rootnode := node
+	for i, str := range pairs {
+		node.Value = str
+		if i < len(pairs)-1 {
+			node.Next = &Node{}
+			node = node.Next
+		}
+	}
+
+	return rootnode, nil, nil
+}
+
+// splits a string on whitespace, except where the whitespace is quoted with
+// single or double quotes or escaped with a backslash. Quotes and escaping
+// backslashes are removed from the words.
+func splitQuotedWords(rest string) ([]string, error) {
+	var (
+		words   []string
+		word    []rune
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, ch := range rest {
+		switch {
+		case escaped:
+			word = append(word, ch)
+			escaped = false
+		case ch == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			} else {
+				word = append(word, ch)
+			}
+		case ch == '"' || ch == '\'':
+			quote, inWord = ch, true
+		case strings.ContainsRune(" \t\v\f\r", ch):
+			if inWord {
+				words = append(words, string(word))
+				word, inWord = nil, false
+			}
+		default:
+			word = append(word, ch)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("Unterminated quote in %q", rest)
+	}
+	if inWord {
+		words = append(words, string(word))
+	}
+	return words, nil
+}
+
 // parses a whitespace-delimited set of arguments. The result is effectively a
 // linked list of string arguments.
 func parseStringsWhitespaceDelimited(rest string) (*Node, map[string]bool, error) {
@@ -153,3 +243,47 @@ func parseMaybeJSONToList(rest string) (*Node, map[string]bool, error) {
 
 	return parseStringsWhitespaceDelimited(rest)
 }
+
+// parseHealthConfig parses the arguments of HEALTHCHECK: leading --name=value
+// options, then either NONE or CMD followed by a command in the forms of RUN.
+//
+// HEALTHCHECK --retries=2 CMD ["true"] -> (healthcheck "--retries=2" "CMD" "true")
+//
+func parseHealthConfig(rest string) (*Node, map[string]bool, error) {
+	var (
+		rootnode = &Node{}
+		node     = rootnode
+		parts    = TOKEN_WHITESPACE.Split(strings.TrimSpace(rest), 2)
+	)
+
+	for strings.HasPrefix(parts[0], "--") {
+		node.Value = parts[0]
+		node.Next = &Node{}
+		node = node.Next
+		if len(parts) < 2 {
+			return nil, nil, fmt.Errorf("HEALTHCHECK requires NONE or CMD")
+		}
+		parts = TOKEN_WHITESPACE.Split(parts[1], 2)
+	}
+
+	typ := strings.ToUpper(parts[0])
+	node.Value = typ
+	switch typ {
+	case "NONE":
+		if len(parts) > 1 {
+			return nil, nil, fmt.Errorf("HEALTHCHECK NONE takes no arguments")
+		}
+		return rootnode, nil, nil
+	case "CMD":
+		if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, nil, fmt.Errorf("HEALTHCHECK CMD requires a command")
+		}
+		cmd, attrs, err := parseMaybeJSON(parts[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		node.Next = cmd
+		return rootnode, attrs, nil
+	}
+	return nil, nil, fmt.Errorf("Unknown type %q in HEALTHCHECK (try CMD)", parts[0])
+}
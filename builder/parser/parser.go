@@ -46,9 +46,11 @@ func init() {
 		"onbuild":        parseSubCommand,
 		"workdir":        parseString,
 		"env":            parseEnv,
+		"label":          parseLabel,
+		"arg":            parseString,
 		"maintainer":     parseString,
 		"docker-version": parseString,
-		"from":           parseString,
+		"from":           parseStringsWhitespaceDelimited,
 		"add":            parseStringsWhitespaceDelimited,
 		"copy":           parseStringsWhitespaceDelimited,
 		"run":            parseMaybeJSON,
@@ -56,6 +58,8 @@ func init() {
 		"entrypoint":     parseMaybeJSON,
 		"expose":         parseStringsWhitespaceDelimited,
 		"volume":         parseMaybeJSONToList,
+		"healthcheck":    parseHealthConfig,
+		"stopsignal":     parseString,
 		"insert":         parseIgnore,
 	}
 }
@@ -1,7 +1,9 @@
 package builder
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -15,18 +17,52 @@ func (b *Builder) replaceEnv(str string) string {
 		match = match[strings.Index(match, "$"):]
 		matchKey := strings.Trim(match, "${}")
 
+		replaced := false
 		for _, keyval := range b.Config.Env {
 			tmp := strings.SplitN(keyval, "=", 2)
 			if tmp[0] == matchKey {
 				str = strings.Replace(str, match, tmp[1], -1)
+				replaced = true
 				break
 			}
 		}
+
+		// ENV takes precedence over ARG of the same name.
+		if value, ok := b.declaredArgs[matchKey]; ok && !replaced {
+			str = strings.Replace(str, match, value, -1)
+		}
 	}
 
 	return str
 }
 
+// buildArgsEnv returns the environment for a RUN step: the image environment
+// plus every declared ARG that is not overridden by an ENV of the same name.
+func (b *Builder) buildArgsEnv() []string {
+	env := make([]string, len(b.Config.Env))
+	copy(env, b.Config.Env)
+
+	keys := []string{}
+	for key := range b.declaredArgs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		overridden := false
+		for _, keyval := range b.Config.Env {
+			if strings.SplitN(keyval, "=", 2)[0] == key {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			env = append(env, fmt.Sprintf("%s=%s", key, b.declaredArgs[key]))
+		}
+	}
+	return env
+}
+
 func handleJsonArgs(args []string, attributes map[string]bool) []string {
 	if len(args) == 0 {
 		return []string{}
@@ -10,7 +10,6 @@ import (
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/events"
 	"github.com/docker/docker/pkg/parsers/kernel"
-	"github.com/docker/docker/registry"
 )
 
 func Register(eng *engine.Engine) error {
@@ -23,10 +22,7 @@ func Register(eng *engine.Engine) error {
 	if err := events.New().Install(eng); err != nil {
 		return err
 	}
-	if err := eng.Register("version", dockerVersion); err != nil {
-		return err
-	}
-	return registry.NewService().Install(eng)
+	return eng.Register("version", dockerVersion)
 }
 
 // remote: a RESTful api for cross-docker communication
@@ -0,0 +1,152 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/symlink"
+)
+
+// resolveArchivePath returns the host path of a path in the container's
+// filesystem, and whether it may be written to. Paths inside a volume
+// resolve to the volume's host directory, everything else to the
+// container's root filesystem, which is read-only with --read-only.
+// Symlinks are followed, but never out of the volume or root filesystem
+// they are in.
+func (container *Container) resolveArchivePath(path string) (string, bool, error) {
+	cleanPath := filepath.Join("/", path)
+
+	// find the volume with the longest mount point containing the path
+	var volumePath, hostPath string
+	for mountToPath, source := range container.Volumes {
+		if cleanPath != mountToPath && !strings.HasPrefix(cleanPath, mountToPath+"/") {
+			continue
+		}
+		if len(mountToPath) > len(volumePath) {
+			volumePath, hostPath = mountToPath, source
+		}
+	}
+
+	if volumePath == "" {
+		resolved, err := container.getResourcePath(cleanPath)
+		return resolved, !container.hostConfig.ReadonlyRootfs, err
+	}
+
+	rel := strings.TrimPrefix(cleanPath, volumePath)
+	resolved, err := symlink.FollowSymlinkInScope(filepath.Join(hostPath, rel), hostPath)
+	return resolved, container.VolumesRW[volumePath], err
+}
+
+// StatPath returns the metadata of path in the container's filesystem.
+func (container *Container) StatPath(path string) (*engine.Env, error) {
+	if err := container.Mount(); err != nil {
+		return nil, err
+	}
+	defer container.Unmount()
+
+	resolved, _, err := container.resolveArchivePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lstat, err := os.Lstat(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	var linkTarget string
+	if lstat.Mode()&os.ModeSymlink != 0 {
+		if linkTarget, err = os.Readlink(resolved); err != nil {
+			return nil, err
+		}
+	}
+
+	stat := &engine.Env{}
+	stat.Set("Name", filepath.Base(filepath.Join("/", path)))
+	stat.SetInt64("Size", lstat.Size())
+	stat.SetInt64("Mode", int64(lstat.Mode()))
+	stat.Set("Mtime", lstat.ModTime().UTC().Format(time.RFC3339Nano))
+	stat.Set("LinkTarget", linkTarget)
+	return stat, nil
+}
+
+// ExtractToDir extracts the tar archive content into the directory path
+// of the container's filesystem. The archive can't write outside of the
+// volume or root filesystem that contains path.
+func (container *Container) ExtractToDir(path string, content io.Reader) error {
+	if err := container.Mount(); err != nil {
+		return err
+	}
+	defer container.Unmount()
+
+	resolved, writable, err := container.resolveArchivePath(path)
+	if err != nil {
+		return err
+	}
+	if !writable {
+		return fmt.Errorf("Cannot extract to %s: read-only file system", path)
+	}
+
+	stat, err := os.Stat(resolved)
+	if err != nil {
+		return err
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("Cannot extract to %s: not a directory", path)
+	}
+
+	return archive.Untar(content, resolved, nil)
+}
+
+func (daemon *Daemon) ContainerStatPath(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s CONTAINER PATH\n", job.Name)
+	}
+
+	var (
+		name = job.Args[0]
+		path = job.Args[1]
+	)
+
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+
+	stat, err := container.StatPath(path)
+	if err != nil {
+		return job.Error(err)
+	}
+	if _, err := stat.WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (daemon *Daemon) ContainerExtractToDir(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s CONTAINER PATH\n", job.Name)
+	}
+
+	var (
+		name = job.Args[0]
+		path = job.Args[1]
+	)
+
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+
+	if err := container.ExtractToDir(path, job.Stdin); err != nil {
+		return job.Error(err)
+	}
+	container.LogEvent("extract-to-dir")
+	return engine.StatusOK
+}
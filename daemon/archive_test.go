@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/runconfig"
+)
+
+func TestResolveArchivePathReadonly(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-archive-rootfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	volume, err := ioutil.TempDir("", "docker-archive-volume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(volume)
+
+	container := &Container{
+		basefs:     root,
+		hostConfig: &runconfig.HostConfig{},
+		Volumes:    map[string]string{"/data": volume, "/ro": volume},
+		VolumesRW:  map[string]bool{"/data": true, "/ro": false},
+	}
+
+	for _, readonly := range []bool{false, true} {
+		container.hostConfig.ReadonlyRootfs = readonly
+		for path, expected := range map[string]struct {
+			hostPath string
+			writable bool
+		}{
+			"/tmp":      {filepath.Join(root, "tmp"), !readonly},
+			"/data/dir": {filepath.Join(volume, "dir"), true},
+			"/ro":       {volume, false},
+		} {
+			hostPath, writable, err := container.resolveArchivePath(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hostPath != expected.hostPath || writable != expected.writable {
+				t.Fatalf("Expected %s to resolve to %s (writable: %t) with a read-only rootfs %t, got %s (writable: %t)", path, expected.hostPath, expected.writable, readonly, hostPath, writable)
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/jsonlog"
@@ -35,7 +36,7 @@ func (daemon *Daemon) ContainerAttach(job *engine.Job) engine.Status {
 
 	//logs
 	if logs {
-		cLog, err := container.ReadLog("json")
+		cLogs, err := container.readLogs()
 		if err != nil && os.IsNotExist(err) {
 			// Legacy logs
 			log.Debugf("Old logs format")
@@ -55,10 +56,17 @@ func (daemon *Daemon) ContainerAttach(job *engine.Job) engine.Status {
 					log.Errorf("Error streaming logs (stderr): %s", err)
 				}
 			}
+		} else if err == logger.ErrReadLogsNotSupported {
+			log.Debugf("Logging driver of %s cannot be read back, not replaying logs", container.ID)
 		} else if err != nil {
 			log.Errorf("Error reading logs (json): %s", err)
 		} else {
-			dec := json.NewDecoder(cLog)
+			readers := make([]io.Reader, len(cLogs))
+			for i, f := range cLogs {
+				defer f.Close()
+				readers[i] = f
+			}
+			dec := json.NewDecoder(io.MultiReader(readers...))
 			for {
 				l := &jsonlog.JSONLog{}
 
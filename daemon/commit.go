@@ -3,6 +3,7 @@ package daemon
 import (
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/log"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -30,7 +31,7 @@ func (daemon *Daemon) ContainerCommit(job *engine.Job) engine.Status {
 		return job.Error(err)
 	}
 
-	img, err := daemon.Commit(container, job.Getenv("repo"), job.Getenv("tag"), job.Getenv("comment"), job.Getenv("author"), job.GetenvBool("pause"), &newConfig)
+	img, err := daemon.Commit(container, job.Getenv("repo"), job.Getenv("tag"), job.Getenv("comment"), job.Getenv("author"), job.GetenvBool("pause"), job.GetenvBool("squash"), &newConfig)
 	if err != nil {
 		return job.Error(err)
 	}
@@ -39,8 +40,10 @@ func (daemon *Daemon) ContainerCommit(job *engine.Job) engine.Status {
 }
 
 // Commit creates a new filesystem image from the current state of a container.
-// The image can optionally be tagged into a repository
-func (daemon *Daemon) Commit(container *Container, repository, tag, comment, author string, pause bool, config *runconfig.Config) (*image.Image, error) {
+// The image can optionally be tagged into a repository. With squash, the
+// image has a single layer with the whole filesystem of the container and no
+// parent: the layers of the container's image are flattened too.
+func (daemon *Daemon) Commit(container *Container, repository, tag, comment, author string, pause, squash bool, config *runconfig.Config) (*image.Image, error) {
 	if pause {
 		container.Pause()
 		defer container.Unpause()
@@ -74,6 +77,20 @@ func (daemon *Daemon) Commit(container *Container, repository, tag, comment, aut
 		return nil, err
 	}
 
+	if squash {
+		// the changes of the container are already a single layer on top of
+		// its image, so squashing is only useful down to the bottom layer
+		squashed, err := daemon.graph.Squash(img, "")
+		if err != nil {
+			return nil, err
+		}
+		// nothing refers to the unsquashed image
+		if err := daemon.graph.Delete(img.ID); err != nil {
+			log.Errorf("Error removing unsquashed image %s: %s", img.ID, err)
+		}
+		img = squashed
+	}
+
 	// Register the image if needed
 	if repository != "" {
 		if err := daemon.repositories.Set(repository, tag, img.ID, true); err != nil {
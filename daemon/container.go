@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"os"
 	"path"
 	"path/filepath"
@@ -17,6 +18,8 @@ import (
 	"github.com/docker/libcontainer/label"
 
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/links"
@@ -28,9 +31,12 @@ import (
 	"github.com/docker/docker/pkg/networkfs/etchosts"
 	"github.com/docker/docker/pkg/networkfs/resolvconf"
 	"github.com/docker/docker/pkg/promise"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
+	"github.com/docker/docker/volumes"
 )
 
 const DefaultPathEnv = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
@@ -90,9 +96,13 @@ type Container struct {
 	VolumesRW  map[string]bool
 	hostConfig *runconfig.HostConfig
 
-	activeLinks  map[string]*links.Link
-	monitor      *containerMonitor
-	execCommands *execStore
+	activeLinks    map[string]*links.Link
+	monitor        *containerMonitor
+	execCommands   *execStore
+	logDriver      logger.Logger
+	logCopier      *logger.Copier
+	mountedVolumes []*volumes.Volume
+	healthStop     chan struct{} // closed to stop the probes of the health check
 }
 
 func (container *Container) FromDisk() error {
@@ -181,7 +191,9 @@ func (container *Container) WriteHostConfig() error {
 
 func (container *Container) LogEvent(action string) {
 	d := container.daemon
-	if err := d.eng.Job("log", action, container.ID, d.Repositories().ImageName(container.Image)).Run(); err != nil {
+	job := d.eng.Job("log", action, container.ID, d.Repositories().ImageName(container.Image), strings.TrimPrefix(container.Name, "/"))
+	job.SetenvJson("Labels", container.Config.Labels)
+	if err := job.Run(); err != nil {
 		log.Errorf("Error logging event %s for %s: %s", action, container.ID, err)
 	}
 }
@@ -207,27 +219,44 @@ func populateCommand(c *Container, env []string) error {
 	case "none":
 	case "host":
 		en.HostNetworking = true
-	case "bridge", "": // empty string to support existing containers
+	case "container":
+		nc, err := c.getNetworkedContainer()
+		if err != nil {
+			return err
+		}
+		en.ContainerID = nc.ID
+	default:
+		// The default bridge, including the empty string to support existing
+		// containers, or a user-defined network.
 		if !c.Config.NetworkDisabled {
 			network := c.NetworkSettings
 			en.Interface = &execdriver.NetworkInterface{
-				Gateway:     network.Gateway,
-				Bridge:      network.Bridge,
-				IPAddress:   network.IPAddress,
-				IPPrefixLen: network.IPPrefixLen,
-				MacAddress:  network.MacAddress,
+				Gateway:             network.Gateway,
+				Bridge:              network.Bridge,
+				IPAddress:           network.IPAddress,
+				IPPrefixLen:         network.IPPrefixLen,
+				MacAddress:          network.MacAddress,
+				GlobalIPv6Address:   network.GlobalIPv6Address,
+				GlobalIPv6PrefixLen: network.GlobalIPv6PrefixLen,
+				IPv6Gateway:         network.IPv6Gateway,
 			}
 		}
-	case "container":
-		nc, err := c.getNetworkedContainer()
+	}
+
+	ipc := &execdriver.Ipc{}
+	if c.hostConfig.IpcMode.IsContainer() {
+		ic, err := c.getIpcContainer()
 		if err != nil {
 			return err
 		}
-		en.ContainerID = nc.ID
-	default:
-		return fmt.Errorf("invalid network mode: %s", c.hostConfig.NetworkMode)
+		ipc.ContainerID = ic.ID
+	} else {
+		ipc.HostIpc = c.hostConfig.IpcMode.IsHost()
 	}
 
+	pid := &execdriver.Pid{}
+	pid.HostPid = c.hostConfig.PidMode.IsHost()
+
 	// Build lists of devices allowed and created within the container.
 	userSpecifiedDevices := make([]*devices.Device, len(c.hostConfig.Devices))
 	for i, deviceMapping := range c.hostConfig.Devices {
@@ -245,11 +274,33 @@ func populateCommand(c *Container, env []string) error {
 	// TODO: this can be removed after lxc-conf is fully deprecated
 	lxcConfig := mergeLxcConfIntoOptions(c.hostConfig)
 
+	// the daemon's default ulimits apply unless the container sets its own
+	ulimits := c.hostConfig.Ulimits
+	ulIdx := make(map[string]struct{})
+	for _, ul := range ulimits {
+		ulIdx[ul.Name] = struct{}{}
+	}
+	for name, ul := range c.daemon.config.Ulimits {
+		if _, exists := ulIdx[name]; !exists {
+			ulimits = append(ulimits, ul)
+		}
+	}
+
+	var rlimits []*ulimit.Rlimit
+	for _, ul := range ulimits {
+		rl, err := ul.GetRlimit()
+		if err != nil {
+			return err
+		}
+		rlimits = append(rlimits, rl)
+	}
+
 	resources := &execdriver.Resources{
 		Memory:     c.Config.Memory,
 		MemorySwap: c.Config.MemorySwap,
 		CpuShares:  c.Config.CpuShares,
 		Cpuset:     c.Config.Cpuset,
+		Rlimits:    rlimits,
 	}
 
 	processConfig := execdriver.ProcessConfig{
@@ -269,6 +320,8 @@ func populateCommand(c *Container, env []string) error {
 		InitPath:           "/.dockerinit",
 		WorkingDir:         c.Config.WorkingDir,
 		Network:            en,
+		Ipc:                ipc,
+		Pid:                pid,
 		Resources:          resources,
 		AllowedDevices:     allowedDevices,
 		AutoCreatedDevices: autoCreatedDevices,
@@ -279,6 +332,7 @@ func populateCommand(c *Container, env []string) error {
 		MountLabel:         c.GetMountLabel(),
 		LxcConfig:          lxcConfig,
 		AppArmorProfile:    c.AppArmorProfile,
+		ReadonlyRootfs:     c.hostConfig.ReadonlyRootfs,
 	}
 
 	return nil
@@ -316,6 +370,9 @@ func (container *Container) Start() (err error) {
 	if err := container.prepareVolumes(); err != nil {
 		return err
 	}
+	if err := container.mountVolumes(); err != nil {
+		return err
+	}
 	linkedEnv, err := container.setupLinkedContainers()
 	if err != nil {
 		return err
@@ -405,7 +462,7 @@ func (container *Container) buildHostnameFile() error {
 	return ioutil.WriteFile(container.HostnamePath, []byte(container.Config.Hostname+"\n"), 0644)
 }
 
-func (container *Container) buildHostsFiles(IP string) error {
+func (container *Container) buildHostsFiles(IP, IPv6 string) error {
 
 	hostsPath, err := container.getRootResourcePath("hosts")
 	if err != nil {
@@ -430,15 +487,15 @@ func (container *Container) buildHostsFiles(IP string) error {
 		extraContent[parts[0]] = parts[1]
 	}
 
-	return etchosts.Build(container.HostsPath, IP, container.Config.Hostname, container.Config.Domainname, &extraContent)
+	return etchosts.Build(container.HostsPath, IP, IPv6, container.Config.Hostname, container.Config.Domainname, &extraContent)
 }
 
-func (container *Container) buildHostnameAndHostsFiles(IP string) error {
+func (container *Container) buildHostnameAndHostsFiles(IP, IPv6 string) error {
 	if err := container.buildHostnameFile(); err != nil {
 		return err
 	}
 
-	return container.buildHostsFiles(IP)
+	return container.buildHostsFiles(IP, IPv6)
 }
 
 func (container *Container) AllocateNetwork() (err error) {
@@ -452,12 +509,18 @@ func (container *Container) AllocateNetwork() (err error) {
 		eng = container.daemon.eng
 	)
 
-	job := eng.Job("allocate_interface", container.ID)
-	if env, err = job.Stdout.AddEnv(); err != nil {
-		return err
-	}
-	if err := job.Run(); err != nil {
-		return err
+	if mode.IsUserDefined() {
+		if env, err = container.joinNetwork(nil, ""); err != nil {
+			return err
+		}
+	} else {
+		job := eng.Job("allocate_interface", container.ID)
+		if env, err = job.Stdout.AddEnv(); err != nil {
+			return err
+		}
+		if err := job.Run(); err != nil {
+			return err
+		}
 	}
 
 	// Error handling: At this point, the interface is allocated so we have to
@@ -465,7 +528,7 @@ func (container *Container) AllocateNetwork() (err error) {
 	// might leak resources.
 	defer func() {
 		if err != nil {
-			eng.Job("release_interface", container.ID).Run()
+			container.releaseInterface()
 		}
 	}()
 
@@ -515,17 +578,47 @@ func (container *Container) AllocateNetwork() (err error) {
 	container.NetworkSettings.IPPrefixLen = env.GetInt("IPPrefixLen")
 	container.NetworkSettings.MacAddress = env.Get("MacAddress")
 	container.NetworkSettings.Gateway = env.Get("Gateway")
+	container.NetworkSettings.GlobalIPv6Address = env.Get("GlobalIPv6")
+	container.NetworkSettings.GlobalIPv6PrefixLen = env.GetInt("GlobalIPv6PrefixLen")
+	container.NetworkSettings.IPv6Gateway = env.Get("IPv6Gateway")
 
 	return nil
 }
 
+// joinNetwork allocates the container's interface on the user-defined
+// network named by its network mode, returning it in the same form as the
+// allocate_interface job of the default bridge.
+func (container *Container) joinNetwork(requestedIP net.IP, requestedMac string) (*engine.Env, error) {
+	name := string(container.hostConfig.NetworkMode)
+	iface, err := container.daemon.networks.Join(name, container.ID, requestedIP, requestedMac)
+	if err != nil {
+		return nil, err
+	}
+	env := &engine.Env{}
+	env.Set("IP", iface.IP.String())
+	env.SetInt("IPPrefixLen", iface.IPPrefixLen)
+	env.Set("Gateway", iface.Gateway.String())
+	env.Set("MacAddress", iface.MacAddress)
+	env.Set("Bridge", iface.Bridge)
+	return env, nil
+}
+
+func (container *Container) releaseInterface() {
+	if mode := container.hostConfig.NetworkMode; mode.IsUserDefined() {
+		if err := container.daemon.networks.Leave(string(mode), container.ID); err != nil {
+			log.Debugf("%v: Failed to leave network %s: %v", container.ID, mode, err)
+		}
+		return
+	}
+	container.daemon.eng.Job("release_interface", container.ID).Run()
+}
+
 func (container *Container) ReleaseNetwork() {
 	if container.Config.NetworkDisabled {
 		return
 	}
-	eng := container.daemon.eng
 
-	eng.Job("release_interface", container.ID).Run()
+	container.releaseInterface()
 	container.NetworkSettings = &NetworkSettings{}
 }
 
@@ -545,11 +638,18 @@ func (container *Container) RestoreNetwork() error {
 	eng := container.daemon.eng
 
 	// Re-allocate the interface with the same IP and MAC address.
-	job := eng.Job("allocate_interface", container.ID)
-	job.Setenv("RequestedIP", container.NetworkSettings.IPAddress)
-	job.Setenv("RequestedMac", container.NetworkSettings.MacAddress)
-	if err := job.Run(); err != nil {
-		return err
+	if mode.IsUserDefined() {
+		if _, err := container.joinNetwork(net.ParseIP(container.NetworkSettings.IPAddress), container.NetworkSettings.MacAddress); err != nil {
+			return err
+		}
+	} else {
+		job := eng.Job("allocate_interface", container.ID)
+		job.Setenv("RequestedIP", container.NetworkSettings.IPAddress)
+		job.Setenv("RequestedIPv6", container.NetworkSettings.GlobalIPv6Address)
+		job.Setenv("RequestedMac", container.NetworkSettings.MacAddress)
+		if err := job.Run(); err != nil {
+			return err
+		}
 	}
 
 	// Re-allocate any previously allocated ports.
@@ -571,6 +671,8 @@ func (container *Container) cleanup() {
 		}
 	}
 
+	container.unmountVolumes()
+
 	if err := container.Unmount(); err != nil {
 		log.Errorf("%v: Failed to umount filesystem: %v", container.ID, err)
 	}
@@ -649,14 +751,28 @@ func (container *Container) Kill() error {
 	return nil
 }
 
+// StopSignal returns the signal that stops the container gracefully, the
+// one set with STOPSIGNAL or --stop-signal, or SIGTERM.
+func (container *Container) StopSignal() int {
+	var stopSignal syscall.Signal
+	if container.Config.StopSignal != "" {
+		stopSignal, _ = signal.ParseSignal(container.Config.StopSignal)
+	}
+
+	if int(stopSignal) <= 0 {
+		stopSignal = syscall.SIGTERM
+	}
+	return int(stopSignal)
+}
+
 func (container *Container) Stop(seconds int) error {
 	if !container.IsRunning() {
 		return nil
 	}
 
-	// 1. Send a SIGTERM
-	if err := container.KillSig(15); err != nil {
-		log.Infof("Failed to send SIGTERM to the process, force killing")
+	// 1. Send the stop signal, SIGTERM by default
+	if err := container.KillSig(container.StopSignal()); err != nil {
+		log.Infof("Failed to send the stop signal to the process, force killing")
 		if err := container.KillSig(9); err != nil {
 			return err
 		}
@@ -664,7 +780,7 @@ func (container *Container) Stop(seconds int) error {
 
 	// 2. Wait for the process to exit on its own
 	if _, err := container.WaitStop(time.Duration(seconds) * time.Second); err != nil {
-		log.Infof("Container %v failed to exit within %d seconds of SIGTERM - using the force", container.ID, seconds)
+		log.Infof("Container %v failed to exit within %d seconds of the stop signal - using the force", container.ID, seconds)
 		// 3. If it doesn't, then send SIGKILL
 		if err := container.Kill(); err != nil {
 			container.WaitStop(-1 * time.Second)
@@ -974,7 +1090,7 @@ func (container *Container) initializeNetworking() error {
 
 		content, err := ioutil.ReadFile("/etc/hosts")
 		if os.IsNotExist(err) {
-			return container.buildHostnameAndHostsFiles("")
+			return container.buildHostnameAndHostsFiles("", "")
 		} else if err != nil {
 			return err
 		}
@@ -1005,7 +1121,7 @@ func (container *Container) initializeNetworking() error {
 	}
 	if container.daemon.config.DisableNetwork {
 		container.Config.NetworkDisabled = true
-		return container.buildHostnameAndHostsFiles("127.0.1.1")
+		return container.buildHostnameAndHostsFiles("127.0.1.1", "")
 	}
 	// Backward compatibility:
 	// Network allocation used to be done when containers started, not when they
@@ -1016,7 +1132,7 @@ func (container *Container) initializeNetworking() error {
 			return err
 		}
 	}
-	return container.buildHostnameAndHostsFiles(container.NetworkSettings.IPAddress)
+	return container.buildHostnameAndHostsFiles(container.NetworkSettings.IPAddress, container.NetworkSettings.GlobalIPv6Address)
 }
 
 // Make sure the config is compatible with the current kernel
@@ -1141,24 +1257,106 @@ func (container *Container) setupWorkingDirectory() error {
 	return nil
 }
 
-func (container *Container) startLoggingToDisk() error {
-	// Setup logging of stdout and stderr to disk
-	pth, err := container.logPath("json")
+// getLogConfig returns the log configuration of the container, see
+// Daemon.logConfig.
+func (container *Container) getLogConfig() runconfig.LogConfig {
+	return container.daemon.logConfig(container.hostConfig.LogConfig)
+}
+
+func (container *Container) getLogger() (logger.Logger, error) {
+	cfg := container.getLogConfig()
+	c, err := logger.GetLogDriver(cfg.Type)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	ctx := logger.Context{
+		Config:        cfg.Config,
+		ContainerID:   container.ID,
+		ContainerName: container.Name,
 	}
+	if cfg.Type == jsonfilelog.Name {
+		if ctx.LogPath, err = container.logPath("json"); err != nil {
+			return nil, err
+		}
+	}
+	return c(ctx)
+}
 
-	if err := container.daemon.LogToDisk(container.stdout, pth, "stdout"); err != nil {
-		return err
+// startLogging copies the container's stdout and stderr to its log driver
+// until the streams are closed in resetContainer.
+func (container *Container) startLogging() error {
+	l, err := container.getLogger()
+	if err != nil {
+		return fmt.Errorf("Failed to initialize logging driver: %v", err)
 	}
 
-	if err := container.daemon.LogToDisk(container.stderr, pth, "stderr"); err != nil {
+	stdout, err := container.StdoutPipe()
+	if err != nil {
+		l.Close()
 		return err
 	}
+	stderr, err := container.StderrPipe()
+	if err != nil {
+		l.Close()
+		return err
+	}
+	copier := logger.NewCopier(container.ID, map[string]io.Reader{"stdout": stdout, "stderr": stderr}, l)
+	copier.Run()
+	container.logCopier = copier
+	container.logDriver = l
 
 	return nil
 }
 
+// stopLogging waits for the log copier to drain the closed streams and then
+// closes the log driver.
+func (container *Container) stopLogging() {
+	if container.logDriver == nil {
+		return
+	}
+	if container.logCopier != nil {
+		exit := make(chan struct{})
+		go func() {
+			container.logCopier.Wait()
+			close(exit)
+		}()
+		select {
+		case <-time.After(1 * time.Second):
+			log.Errorf("%s: Logger didn't exit in time: logs may be truncated", container.ID)
+		case <-exit:
+		}
+	}
+	if err := container.logDriver.Close(); err != nil {
+		log.Errorf("%s: Error closing logger: %s", container.ID, err)
+	}
+	container.logCopier = nil
+	container.logDriver = nil
+}
+
+// readLogs opens the json-file logs of the container, oldest first. It
+// returns logger.ErrReadLogsNotSupported for drivers that cannot be read back.
+func (container *Container) readLogs() ([]*os.File, error) {
+	if container.getLogConfig().Type != jsonfilelog.Name {
+		return nil, logger.ErrReadLogsNotSupported
+	}
+	pth, err := container.logPath("json")
+	if err != nil {
+		return nil, err
+	}
+	var files []*os.File
+	for _, name := range jsonfilelog.Files(pth) {
+		f, err := os.Open(name)
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
 func (container *Container) waitForStart() error {
 	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
 
@@ -1220,6 +1418,18 @@ func (container *Container) GetMountLabel() string {
 	return container.MountLabel
 }
 
+func (container *Container) getIpcContainer() (*Container, error) {
+	containerID := container.hostConfig.IpcMode.Container()
+	c := container.daemon.Get(containerID)
+	if c == nil {
+		return nil, fmt.Errorf("no such container to join IPC: %s", containerID)
+	}
+	if !c.IsRunning() {
+		return nil, fmt.Errorf("cannot join IPC of a non running container: %s", containerID)
+	}
+	return c, nil
+}
+
 func (container *Container) getNetworkedContainer() (*Container, error) {
 	parts := strings.SplitN(string(container.hostConfig.NetworkMode), ":", 2)
 	switch parts[0] {
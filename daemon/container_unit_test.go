@@ -1,8 +1,11 @@
 package daemon
 
 import (
-	"github.com/docker/docker/nat"
+	"reflect"
 	"testing"
+
+	"github.com/docker/docker/nat"
+	"github.com/docker/docker/runconfig"
 )
 
 func TestParseNetworkOptsPrivateOnly(t *testing.T) {
@@ -195,3 +198,25 @@ func TestValidContainerNames(t *testing.T) {
 		}
 	}
 }
+
+func TestGetLogConfig(t *testing.T) {
+	daemon := &Daemon{config: &Config{LogConfig: runconfig.LogConfig{Type: "json-file", Config: map[string]string{"max-size": "10m", "max-file": "3"}}}}
+	container := &Container{daemon: daemon, hostConfig: &runconfig.HostConfig{}}
+
+	// --log-opt without --log-driver applies to the daemon's driver
+	container.hostConfig.LogConfig = runconfig.LogConfig{Config: map[string]string{"max-size": "1m"}}
+	cfg := container.getLogConfig()
+	if cfg.Type != "json-file" || !reflect.DeepEqual(cfg.Config, map[string]string{"max-size": "1m", "max-file": "3"}) {
+		t.Fatalf("Expected the container's options over the daemon's, got %+v", cfg)
+	}
+	if daemon.config.LogConfig.Config["max-size"] != "10m" {
+		t.Fatal("Expected the daemon's options to be left alone")
+	}
+
+	// another driver doesn't take the daemon's options
+	container.hostConfig.LogConfig = runconfig.LogConfig{Type: "syslog", Config: map[string]string{"syslog-address": "udp://127.0.0.1:514"}}
+	cfg = container.getLogConfig()
+	if cfg.Type != "syslog" || !reflect.DeepEqual(cfg.Config, map[string]string{"syslog-address": "udp://127.0.0.1:514"}) {
+		t.Fatalf("Expected the container's log config alone, got %+v", cfg)
+	}
+}
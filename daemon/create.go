@@ -1,9 +1,11 @@
 package daemon
 
 import (
+	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/graph"
 	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -26,6 +28,11 @@ func (daemon *Daemon) ContainerCreate(job *engine.Job) engine.Status {
 		job.Errorf("Your kernel does not support swap limit capabilities. Limitation discarded.\n")
 		config.MemorySwap = -1
 	}
+	if config.StopSignal != "" {
+		if _, err := signal.ParseSignal(config.StopSignal); err != nil {
+			return job.Error(err)
+		}
+	}
 
 	var hostConfig *runconfig.HostConfig
 	if job.EnvExists("HostConfig") {
@@ -80,13 +87,25 @@ func (daemon *Daemon) Create(config *runconfig.Config, hostConfig *runconfig.Hos
 	if warnings, err = daemon.mergeAndVerifyConfig(config, img); err != nil {
 		return nil, nil, err
 	}
+	if hostConfig != nil {
+		logConfig := daemon.logConfig(hostConfig.LogConfig)
+		if err := logger.ValidateLogOpts(logConfig.Type, logConfig.Config); err != nil {
+			return nil, nil, err
+		}
+	}
 	if container, err = daemon.newContainer(name, config, img); err != nil {
 		return nil, nil, err
 	}
-	if err := daemon.Register(container); err != nil {
+	var storageOpt map[string]string
+	if hostConfig != nil {
+		storageOpt = hostConfig.StorageOpt
+	}
+	if err := daemon.createRootfs(container, img, storageOpt); err != nil {
+		// release the name, so it can be used once the error is fixed
+		daemon.containerGraph.Delete(container.Name)
 		return nil, nil, err
 	}
-	if err := daemon.createRootfs(container, img); err != nil {
+	if err := daemon.Register(container); err != nil {
 		return nil, nil, err
 	}
 	if hostConfig != nil {
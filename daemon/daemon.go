@@ -15,17 +15,23 @@ import (
 
 	"github.com/docker/libcontainer/label"
 
+	"github.com/docker/docker/api"
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/daemon/execdriver/execdrivers"
 	"github.com/docker/docker/daemon/execdriver/lxc"
 	"github.com/docker/docker/daemon/graphdriver"
 	_ "github.com/docker/docker/daemon/graphdriver/vfs"
+	"github.com/docker/docker/daemon/logger"
+	_ "github.com/docker/docker/daemon/logger/jsonfilelog"
+	_ "github.com/docker/docker/daemon/logger/none"
+	_ "github.com/docker/docker/daemon/logger/syslog"
 	_ "github.com/docker/docker/daemon/networkdriver/bridge"
 	"github.com/docker/docker/daemon/networkdriver/portallocator"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/graph"
 	"github.com/docker/docker/image"
+	"github.com/docker/docker/networks"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/broadcastwriter"
 	"github.com/docker/docker/pkg/graphdb"
@@ -94,6 +100,7 @@ type Daemon struct {
 	idIndex        *truncindex.TruncIndex
 	sysInfo        *sysinfo.SysInfo
 	volumes        *volumes.Repository
+	networks       *networks.Repository
 	eng            *engine.Engine
 	config         *Config
 	containerGraph *graphdb.Database
@@ -106,30 +113,37 @@ type Daemon struct {
 func (daemon *Daemon) Install(eng *engine.Engine) error {
 	// FIXME: remove ImageDelete's dependency on Daemon, then move to graph/
 	for name, method := range map[string]engine.Handler{
-		"attach":            daemon.ContainerAttach,
-		"commit":            daemon.ContainerCommit,
-		"container_changes": daemon.ContainerChanges,
-		"container_copy":    daemon.ContainerCopy,
-		"container_inspect": daemon.ContainerInspect,
-		"containers":        daemon.Containers,
-		"create":            daemon.ContainerCreate,
-		"rm":                daemon.ContainerRm,
-		"export":            daemon.ContainerExport,
-		"info":              daemon.CmdInfo,
-		"kill":              daemon.ContainerKill,
-		"logs":              daemon.ContainerLogs,
-		"pause":             daemon.ContainerPause,
-		"resize":            daemon.ContainerResize,
-		"restart":           daemon.ContainerRestart,
-		"start":             daemon.ContainerStart,
-		"stop":              daemon.ContainerStop,
-		"top":               daemon.ContainerTop,
-		"unpause":           daemon.ContainerUnpause,
-		"wait":              daemon.ContainerWait,
-		"image_delete":      daemon.ImageDelete, // FIXME: see above
-		"execCreate":        daemon.ContainerExecCreate,
-		"execStart":         daemon.ContainerExecStart,
-		"execResize":        daemon.ContainerExecResize,
+		"attach":                   daemon.ContainerAttach,
+		"commit":                   daemon.ContainerCommit,
+		"container_changes":        daemon.ContainerChanges,
+		"container_copy":           daemon.ContainerCopy,
+		"container_stat_path":      daemon.ContainerStatPath,
+		"container_extract_to_dir": daemon.ContainerExtractToDir,
+		"container_inspect":        daemon.ContainerInspect,
+		"containers":               daemon.Containers,
+		"create":                   daemon.ContainerCreate,
+		"rm":                       daemon.ContainerRm,
+		"export":                   daemon.ContainerExport,
+		"info":                     daemon.CmdInfo,
+		"kill":                     daemon.ContainerKill,
+		"logs":                     daemon.ContainerLogs,
+		"pause":                    daemon.ContainerPause,
+		"container_rename":         daemon.ContainerRename,
+		"resize":                   daemon.ContainerResize,
+		"restart":                  daemon.ContainerRestart,
+		"start":                    daemon.ContainerStart,
+		"container_stats":          daemon.ContainerStats,
+		"stop":                     daemon.ContainerStop,
+		"top":                      daemon.ContainerTop,
+		"unpause":                  daemon.ContainerUnpause,
+		"wait":                     daemon.ContainerWait,
+		"image_delete":             daemon.ImageDelete, // FIXME: see above
+		"execCreate":               daemon.ContainerExecCreate,
+		"execStart":                daemon.ContainerExecStart,
+		"execResize":               daemon.ContainerExecResize,
+		"network_connect":          daemon.NetworkConnect,
+		"network_disconnect":       daemon.NetworkDisconnect,
+		"network_rm":               daemon.NetworkRm,
 	} {
 		if err := eng.Register(name, method); err != nil {
 			return err
@@ -138,6 +152,12 @@ func (daemon *Daemon) Install(eng *engine.Engine) error {
 	if err := daemon.Repositories().Install(eng); err != nil {
 		return err
 	}
+	if err := daemon.volumes.Install(eng); err != nil {
+		return err
+	}
+	if err := daemon.networks.Install(eng); err != nil {
+		return err
+	}
 	if err := daemon.trustStore.Install(eng); err != nil {
 		return err
 	}
@@ -289,15 +309,6 @@ func (daemon *Daemon) ensureName(container *Container) error {
 	return nil
 }
 
-func (daemon *Daemon) LogToDisk(src *broadcastwriter.BroadcastWriter, dst, stream string) error {
-	log, err := os.OpenFile(dst, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
-	if err != nil {
-		return err
-	}
-	src.AddWriter(log, stream)
-	return nil
-}
-
 func (daemon *Daemon) restore() error {
 	var (
 		debug         = (os.Getenv("DEBUG") != "" || os.Getenv("TEST") != "")
@@ -402,6 +413,7 @@ func (daemon *Daemon) restore() error {
 	for _, c := range registeredContainers {
 		for _, mnt := range c.VolumeMounts() {
 			daemon.volumes.Add(mnt.volume)
+			mnt.volume.AddContainer(c.ID)
 		}
 	}
 
@@ -425,6 +437,24 @@ func (daemon *Daemon) checkDeprecatedExpose(config *runconfig.Config) bool {
 	return false
 }
 
+// logConfig returns the log configuration a container created with cfg
+// uses, falling back to the daemon's default driver when none was given. The
+// options of the container then override the daemon's.
+func (daemon *Daemon) logConfig(cfg runconfig.LogConfig) runconfig.LogConfig {
+	if cfg.Type != "" {
+		return cfg
+	}
+	defaults := daemon.config.LogConfig
+	merged := runconfig.LogConfig{Type: defaults.Type, Config: make(map[string]string)}
+	for k, v := range defaults.Config {
+		merged.Config[k] = v
+	}
+	for k, v := range cfg.Config {
+		merged.Config[k] = v
+	}
+	return merged
+}
+
 func (daemon *Daemon) mergeAndVerifyConfig(config *runconfig.Config, img *image.Image) ([]string, error) {
 	warnings := []string{}
 	if daemon.checkDeprecatedExpose(img.Config) || daemon.checkDeprecatedExpose(config) {
@@ -601,27 +631,35 @@ func (daemon *Daemon) newContainer(name string, config *runconfig.Config, img *i
 	return container, err
 }
 
-func (daemon *Daemon) createRootfs(container *Container, img *image.Image) error {
+// createRootfs creates the init and read-write layers of a container.
+// storageOpt applies to the read-write layer, which is the root filesystem
+// of the container.
+func (daemon *Daemon) createRootfs(container *Container, img *image.Image, storageOpt map[string]string) error {
 	// Step 1: create the container directory.
 	// This doubles as a barrier to avoid race conditions.
 	if err := os.Mkdir(container.root, 0700); err != nil {
 		return err
 	}
 	initID := fmt.Sprintf("%s-init", container.ID)
-	if err := daemon.driver.Create(initID, img.ID); err != nil {
+	if err := daemon.driver.Create(initID, img.ID, nil); err != nil {
+		os.RemoveAll(container.root)
 		return err
 	}
 	initPath, err := daemon.driver.Get(initID, "")
 	if err != nil {
 		return err
 	}
-	defer daemon.driver.Put(initID)
-
-	if err := graph.SetupInitLayer(initPath); err != nil {
+	err = graph.SetupInitLayer(initPath)
+	daemon.driver.Put(initID)
+	if err != nil {
 		return err
 	}
 
-	if err := daemon.driver.Create(container.ID, initID); err != nil {
+	if err := daemon.driver.Create(container.ID, initID, storageOpt); err != nil {
+		// storage options are checked by the driver, don't leave the
+		// container half created when they are invalid
+		daemon.driver.Remove(initID)
+		os.RemoveAll(container.root)
 		return err
 	}
 	return nil
@@ -744,11 +782,26 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 	if !config.EnableIptables && !config.InterContainerCommunication {
 		return nil, fmt.Errorf("You specified --iptables=false with --icc=false. ICC uses iptables to function. Please set --icc or --iptables to true.")
 	}
+	if config.FixedCIDRv6 != "" && !config.EnableIPv6 {
+		return nil, fmt.Errorf("You specified --fixed-cidr-v6 without --ipv6. Please set --ipv6 to true.")
+	}
+	if config.EnableIPv6 && !config.InterContainerCommunication {
+		return nil, fmt.Errorf("You specified --ipv6 with --icc=false. ICC is only enforced for IPv4, the containers could reach each other over IPv6. Please set --icc to true or --ipv6 to false.")
+	}
 	if !config.EnableIptables && config.EnableIpMasq {
 		return nil, fmt.Errorf("You specified --iptables=false with --ipmasq=true. IP masquerading uses iptables to function. Please set --ipmasq to false or --iptables to true.")
 	}
 	config.DisableNetwork = config.BridgeIface == disableNetworkBridge
 
+	logOpts, err := runconfig.ParseLogOpts(config.LogOpts)
+	if err != nil {
+		return nil, err
+	}
+	config.LogConfig.Config = logOpts
+	if err := logger.ValidateLogOpts(config.LogConfig.Type, config.LogConfig.Config); err != nil {
+		return nil, err
+	}
+
 	// Claim the pidfile first, to avoid any and all unexpected race conditions.
 	// Some of the init doesn't need a pidfile lock - but let's not try to be smart.
 	if config.Pidfile != "" {
@@ -844,8 +897,16 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		return nil, err
 	}
 
+	if config.TrustKeyPath == "" {
+		config.TrustKeyPath = path.Join(config.Root, "key.json")
+	}
+	trustKey, err := api.LoadOrCreateTrustKey(config.TrustKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Debugf("Creating repository list")
-	repositories, err := graph.NewTagStore(path.Join(config.Root, "repositories-"+driver.String()), g, config.Mirrors)
+	repositories, err := graph.NewTagStore(path.Join(config.Root, "repositories-"+driver.String()), g, config.Mirrors, config.InsecureRegistries, trustKey)
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't create Tag store: %s", err)
 	}
@@ -869,6 +930,9 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		job.Setenv("BridgeIface", config.BridgeIface)
 		job.Setenv("BridgeIP", config.BridgeIP)
 		job.Setenv("FixedCIDR", config.FixedCIDR)
+		job.SetenvBool("EnableIPv6", config.EnableIPv6)
+		job.Setenv("FixedCIDRv6", config.FixedCIDRv6)
+		job.Setenv("PortRange", config.PortRange)
 		job.Setenv("DefaultBindingIP", config.DefaultIp.String())
 
 		if err := job.Run(); err != nil {
@@ -876,6 +940,11 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		}
 	}
 
+	networks, err := networks.NewRepository(path.Join(config.Root, "networks"))
+	if err != nil {
+		return nil, err
+	}
+
 	graphdbPath := path.Join(config.Root, "linkgraph.db")
 	graph, err := graphdb.NewSqliteConn(graphdbPath)
 	if err != nil {
@@ -917,6 +986,7 @@ func NewDaemonFromDirectory(config *Config, eng *engine.Engine) (*Daemon, error)
 		idIndex:        truncindex.NewTruncIndex([]string{}),
 		sysInfo:        sysInfo,
 		volumes:        volumes,
+		networks:       networks,
 		config:         config,
 		containerGraph: graph,
 		driver:         driver,
@@ -965,8 +1035,9 @@ func (daemon *Daemon) shutdown() error {
 
 			go func() {
 				defer group.Done()
-				if err := c.KillSig(15); err != nil {
-					log.Debugf("kill 15 error for %s - %s", c.ID, err)
+				sig := c.StopSignal()
+				if err := c.KillSig(sig); err != nil {
+					log.Debugf("kill %d error for %s - %s", sig, c.ID, err)
 				}
 				c.WaitStop(-1 * time.Second)
 				log.Debugf("container stopped %s", c.ID)
@@ -1027,6 +1098,10 @@ func (daemon *Daemon) Unpause(c *Container) error {
 	return nil
 }
 
+func (daemon *Daemon) Stats(c *Container) (*execdriver.ResourceStats, error) {
+	return daemon.execDriver.Stats(c.ID)
+}
+
 func (daemon *Daemon) Kill(c *Container, sig int) error {
 	return daemon.execDriver.Kill(c.command, sig)
 }
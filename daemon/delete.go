@@ -71,6 +71,10 @@ func (daemon *Daemon) ContainerRm(job *engine.Job) engine.Status {
 
 func (daemon *Daemon) DeleteVolumes(volumeIDs map[string]struct{}) {
 	for id := range volumeIDs {
+		// Named volumes outlive the containers using them
+		if vol := daemon.volumes.Get(id); vol != nil && vol.Name != "" {
+			continue
+		}
 		if err := daemon.volumes.Delete(id); err != nil {
 			log.Infof("%s", err)
 			continue
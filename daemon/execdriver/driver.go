@@ -5,7 +5,10 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"time"
 
+	"github.com/docker/docker/pkg/ulimit"
+	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/devices"
 )
 
@@ -52,6 +55,7 @@ type Driver interface {
 	GetPidsForContainer(id string) ([]int, error) // Returns a list of pids for the given container.
 	Terminate(c *Command) error                   // kill it with fire
 	Clean(id string) error                        // clean all traces of container exec
+	Stats(id string) (*ResourceStats, error)      // Returns a sample of the resource usage of a running container
 }
 
 // Network settings of the container
@@ -62,19 +66,41 @@ type Network struct {
 	HostNetworking bool              `json:"host_networking"`
 }
 
+// IPC settings of the container
+type Ipc struct {
+	ContainerID string `json:"container_id"` // id of the container to join ipc.
+	HostIpc     bool   `json:"host_ipc"`
+}
+
+// PID settings of the container
+type Pid struct {
+	HostPid bool `json:"host_pid"`
+}
+
 type NetworkInterface struct {
-	Gateway     string `json:"gateway"`
-	IPAddress   string `json:"ip"`
-	IPPrefixLen int    `json:"ip_prefix_len"`
-	MacAddress  string `json:"mac_address"`
-	Bridge      string `json:"bridge"`
+	Gateway             string `json:"gateway"`
+	IPAddress           string `json:"ip"`
+	IPPrefixLen         int    `json:"ip_prefix_len"`
+	MacAddress          string `json:"mac_address"`
+	Bridge              string `json:"bridge"`
+	GlobalIPv6Address   string `json:"global_ipv6"`
+	GlobalIPv6PrefixLen int    `json:"global_ipv6_prefix_len"`
+	IPv6Gateway         string `json:"ipv6_gateway"`
 }
 
 type Resources struct {
-	Memory     int64  `json:"memory"`
-	MemorySwap int64  `json:"memory_swap"`
-	CpuShares  int64  `json:"cpu_shares"`
-	Cpuset     string `json:"cpuset"`
+	Memory     int64            `json:"memory"`
+	MemorySwap int64            `json:"memory_swap"`
+	CpuShares  int64            `json:"cpu_shares"`
+	Cpuset     string           `json:"cpuset"`
+	Rlimits    []*ulimit.Rlimit `json:"rlimits"`
+}
+
+// ResourceStats is a single sample of the cgroup and network
+// counters of a running container
+type ResourceStats struct {
+	*libcontainer.ContainerStats
+	Read time.Time `json:"read"`
 }
 
 type Mount struct {
@@ -106,6 +132,8 @@ type Command struct {
 	WorkingDir         string            `json:"working_dir"`
 	ConfigPath         string            `json:"config_path"` // this should be able to be removed when the lxc template is moved into the driver
 	Network            *Network          `json:"network"`
+	Ipc                *Ipc              `json:"ipc"`
+	Pid                *Pid              `json:"pid"`
 	Resources          *Resources        `json:"resources"`
 	Mounts             []Mount           `json:"mounts"`
 	AllowedDevices     []*devices.Device `json:"allowed_devices"`
@@ -118,4 +146,5 @@ type Command struct {
 	MountLabel         string            `json:"mount_label"`
 	LxcConfig          []string          `json:"lxc_config"`
 	AppArmorProfile    string            `json:"apparmor_profile"`
+	ReadonlyRootfs     bool              `json:"readonly_rootfs"` // mount the root filesystem read-only, mounts stay as they are
 }
@@ -21,8 +21,11 @@ import (
 	"github.com/docker/docker/pkg/log"
 	"github.com/docker/docker/pkg/term"
 	"github.com/docker/docker/utils"
+	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/cgroups/fs"
 	"github.com/docker/libcontainer/mount/nodes"
+	"github.com/docker/libcontainer/network"
 )
 
 const DriverName = "lxc"
@@ -61,6 +64,10 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 		err  error
 	)
 
+	if (c.Ipc != nil && (c.Ipc.HostIpc || c.Ipc.ContainerID != "")) || (c.Pid != nil && c.Pid.HostPid) {
+		return -1, fmt.Errorf("the lxc driver doesn't support sharing the IPC or PID namespace")
+	}
+
 	if c.ProcessConfig.Tty {
 		term, err = NewTtyConsole(&c.ProcessConfig, pipes)
 	} else {
@@ -82,6 +89,7 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 	if err != nil {
 		return -1, err
 	}
+
 	params := []string{
 		"lxc-start",
 		"-n", c.ID,
@@ -124,6 +132,14 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 		params = append(params, fmt.Sprintf("-cap-drop=%s", strings.Join(c.CapDrop, ":")))
 	}
 
+	if c.Resources != nil && len(c.Resources.Rlimits) > 0 {
+		rlimits, err := json.Marshal(c.Resources.Rlimits)
+		if err != nil {
+			return -1, err
+		}
+		params = append(params, fmt.Sprintf("-rlimits=%s", rlimits))
+	}
+
 	params = append(params, "--", c.ProcessConfig.Entrypoint)
 	params = append(params, c.ProcessConfig.Arguments...)
 
@@ -376,6 +392,65 @@ func (d *driver) GetPidsForContainer(id string) ([]int, error) {
 	return pids, nil
 }
 
+func (d *driver) Stats(id string) (*execdriver.ResourceStats, error) {
+	output, err := d.getInfo(id)
+	if err != nil {
+		return nil, fmt.Errorf("Err: %s Output: %s", err, output)
+	}
+	info, err := parseLxcInfo(string(output))
+	if err != nil {
+		return nil, err
+	}
+	if !info.Running {
+		return nil, fmt.Errorf("lxc container %s is not running", id)
+	}
+
+	parent, err := cgroupParent(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	cgroupStats, err := fs.GetStats(&cgroups.Cgroup{Name: id, Parent: parent})
+	if err != nil {
+		return nil, err
+	}
+	// older lxc-info does not report the host side veth, in which case
+	// GetStats returns empty network counters
+	networkStats, err := network.GetStats(&network.NetworkState{VethHost: info.Link})
+	if err != nil {
+		return nil, err
+	}
+	return &execdriver.ResourceStats{
+		ContainerStats: &libcontainer.ContainerStats{
+			CgroupStats:  cgroupStats,
+			NetworkStats: networkStats,
+		},
+		Read: now,
+	}, nil
+}
+
+// cgroupParent returns the absolute path of the cgroup that lxc created
+// the container's cgroup under
+func cgroupParent(id string) (string, error) {
+	// cpu is chosen because it is the only non optional subsystem in cgroups
+	subsystem := "cpu"
+	cgroupRoot, err := cgroups.FindCgroupMountpoint(subsystem)
+	if err != nil {
+		return "", err
+	}
+
+	cgroupDir, err := cgroups.GetThisCgroupDir(subsystem)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(filepath.Join(cgroupRoot, cgroupDir, id)); os.IsNotExist(err) {
+		// With more recent lxc versions use, cgroup will be in lxc/
+		return filepath.Join(cgroupDir, "lxc"), nil
+	}
+	return cgroupDir, nil
+}
+
 func linkLxcStart(root string) error {
 	sourcePath, err := exec.LookPath("lxc-start")
 	if err != nil {
@@ -14,6 +14,7 @@ var (
 type lxcInfo struct {
 	Running bool
 	Pid     int
+	Link    string // host side of the container's veth pair
 }
 
 func parseLxcInfo(raw string) (*lxcInfo, error) {
@@ -44,6 +45,8 @@ func parseLxcInfo(raw string) (*lxcInfo, error) {
 			if err != nil {
 				return nil, err
 			}
+		case "link":
+			info.Link = strings.TrimSpace(parts[1])
 		}
 	}
 	return info, nil
@@ -21,6 +21,21 @@ func TestParseRunningInfo(t *testing.T) {
 	}
 }
 
+func TestParseLinkInfo(t *testing.T) {
+	raw := `
+    state: RUNNING
+    pid:    50
+    Link:   vethE5J9QL`
+
+	info, err := parseLxcInfo(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Link != "vethE5J9QL" {
+		t.Fatalf("info should have link vethE5J9QL got %s", info.Link)
+	}
+}
+
 func TestEmptyInfo(t *testing.T) {
 	_, err := parseLxcInfo("")
 	if err == nil {
@@ -32,6 +32,7 @@ type InitArgs struct {
 	Root       string
 	CapAdd     string
 	CapDrop    string
+	Rlimits    string
 }
 
 func init() {
@@ -93,6 +94,7 @@ func getArgs() *InitArgs {
 		mtu        = flag.Int("mtu", 1500, "interface mtu")
 		capAdd     = flag.String("cap-add", "", "capabilities to add")
 		capDrop    = flag.String("cap-drop", "", "capabilities to drop")
+		rlimits    = flag.String("rlimits", "", "json encoded resource limits")
 	)
 
 	flag.Parse()
@@ -107,6 +109,7 @@ func getArgs() *InitArgs {
 		Mtu:        *mtu,
 		CapAdd:     *capAdd,
 		CapDrop:    *capDrop,
+		Rlimits:    *rlimits,
 	}
 }
 
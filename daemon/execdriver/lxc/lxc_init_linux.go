@@ -26,6 +26,11 @@ func finalizeNamespace(args *InitArgs) error {
 	// across both drivers
 	container := template.New()
 
+	// set the limits while the capabilities still allow raising them
+	if err := execdriver.SetupRlimits(args.Rlimits); err != nil {
+		return fmt.Errorf("setup rlimits %s", err)
+	}
+
 	if !args.Privileged {
 		// drop capabilities in bounding set before changing user
 		if err := capabilities.DropBoundingSet(container.Capabilities); err != nil {
@@ -15,6 +15,10 @@ lxc.network.type = veth
 lxc.network.link = {{.Network.Interface.Bridge}}
 lxc.network.name = eth0
 lxc.network.mtu = {{.Network.Mtu}}
+{{if .Network.Interface.GlobalIPv6Address}}
+lxc.network.ipv6 = {{.Network.Interface.GlobalIPv6Address}}/{{.Network.Interface.GlobalIPv6PrefixLen}}
+lxc.network.ipv6.gateway = {{.Network.Interface.IPv6Gateway}}
+{{end}}
 {{else if .Network.HostNetworking}}
 lxc.network.type = none
 {{else}}
@@ -27,6 +31,9 @@ lxc.network.mtu = {{.Network.Mtu}}
 # root filesystem
 {{$ROOTFS := .Rootfs}}
 lxc.rootfs = {{$ROOTFS}}
+{{if .ReadonlyRootfs}}
+lxc.rootfs.options = ro
+{{end}}
 
 # use a dedicated pts for the container (and limit the number of pseudo terminal
 # available)
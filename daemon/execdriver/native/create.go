@@ -31,6 +31,7 @@ func (d *driver) createContainer(c *execdriver.Command) (*libcontainer.Config, e
 	container.Cgroups.AllowedDevices = c.AllowedDevices
 	container.MountConfig.DeviceNodes = c.AutoCreatedDevices
 	container.RootFs = c.Rootfs
+	container.MountConfig.ReadonlyFs = c.ReadonlyRootfs
 
 	// check to see if we are running in ramdisk to disable pivot root
 	container.MountConfig.NoPivotRoot = os.Getenv("DOCKER_RAMDISK") != ""
@@ -40,6 +41,14 @@ func (d *driver) createContainer(c *execdriver.Command) (*libcontainer.Config, e
 		return nil, err
 	}
 
+	if err := d.createIpc(container, c); err != nil {
+		return nil, err
+	}
+
+	if err := d.createPid(container, c); err != nil {
+		return nil, err
+	}
+
 	if c.ProcessConfig.Privileged {
 		if err := d.setPrivileged(container); err != nil {
 			return nil, err
@@ -101,6 +110,10 @@ func (d *driver) createNetwork(container *libcontainer.Config, c *execdriver.Com
 			Bridge:     c.Network.Interface.Bridge,
 			VethPrefix: "veth",
 		}
+		if c.Network.Interface.GlobalIPv6Address != "" {
+			vethNetwork.IPv6Address = fmt.Sprintf("%s/%d", c.Network.Interface.GlobalIPv6Address, c.Network.Interface.GlobalIPv6PrefixLen)
+			vethNetwork.IPv6Gateway = c.Network.Interface.IPv6Gateway
+		}
 		container.Networks = append(container.Networks, &vethNetwork)
 	}
 
@@ -124,6 +137,47 @@ func (d *driver) createNetwork(container *libcontainer.Config, c *execdriver.Com
 	return nil
 }
 
+func (d *driver) createIpc(container *libcontainer.Config, c *execdriver.Command) error {
+	if c.Ipc == nil {
+		return nil
+	}
+
+	// a container joining the IPC of another one starts in the host's
+	// namespace, its init process then joins the other container's one,
+	// see ipcNamespacePath
+	if c.Ipc.HostIpc || c.Ipc.ContainerID != "" {
+		container.Namespaces["NEWIPC"] = false
+	}
+
+	return nil
+}
+
+// ipcNamespacePath returns the path of the IPC namespace of the container
+// whose IPC the command joins, or "" if it doesn't join one.
+func (d *driver) ipcNamespacePath(c *execdriver.Command) (string, error) {
+	if c.Ipc == nil || c.Ipc.ContainerID == "" {
+		return "", nil
+	}
+
+	d.Lock()
+	active := d.activeContainers[c.Ipc.ContainerID]
+	d.Unlock()
+
+	if active == nil || active.cmd.Process == nil {
+		return "", fmt.Errorf("%s is not a valid running container to join", c.Ipc.ContainerID)
+	}
+
+	return filepath.Join("/proc", fmt.Sprint(active.cmd.Process.Pid), "ns", "ipc"), nil
+}
+
+func (d *driver) createPid(container *libcontainer.Config, c *execdriver.Command) error {
+	if c.Pid != nil && c.Pid.HostPid {
+		container.Namespaces["NEWPID"] = false
+	}
+
+	return nil
+}
+
 func (d *driver) setPrivileged(container *libcontainer.Config) (err error) {
 	container.Capabilities = capabilities.GetAllCapabilities()
 	container.Cgroups.AllowAllDevices = true
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/pkg/term"
@@ -100,6 +101,18 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 		return -1, err
 	}
 
+	ipcPath, err := d.ipcNamespacePath(c)
+	if err != nil {
+		return -1, err
+	}
+
+	var rlimits []byte
+	if c.Resources != nil && len(c.Resources.Rlimits) > 0 {
+		if rlimits, err = json.Marshal(c.Resources.Rlimits); err != nil {
+			return -1, err
+		}
+	}
+
 	return namespaces.Exec(container, c.ProcessConfig.Stdin, c.ProcessConfig.Stdout, c.ProcessConfig.Stderr, c.ProcessConfig.Console, dataPath, args, func(container *libcontainer.Config, console, dataPath, init string, child *os.File, args []string) *exec.Cmd {
 		c.ProcessConfig.Path = d.initPath
 		c.ProcessConfig.Args = append([]string{
@@ -107,6 +120,8 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 			"-console", console,
 			"-pipe", "3",
 			"-root", filepath.Join(d.root, c.ID),
+			"-rlimits", string(rlimits),
+			"-ipc", ipcPath,
 			"--",
 		}, args...)
 
@@ -219,6 +234,29 @@ func (d *driver) GetPidsForContainer(id string) ([]int, error) {
 	return fs.GetPids(c)
 }
 
+func (d *driver) Stats(id string) (*execdriver.ResourceStats, error) {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+
+	if active == nil {
+		return nil, fmt.Errorf("active container for %s does not exist", id)
+	}
+	state, err := libcontainer.GetState(filepath.Join(d.root, id))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	stats, err := libcontainer.GetStats(active.container, state)
+	if err != nil {
+		return nil, err
+	}
+	return &execdriver.ResourceStats{
+		ContainerStats: stats,
+		Read:           now,
+	}, nil
+}
+
 func (d *driver) writeContainerFile(container *libcontainer.Config, id string) error {
 	data, err := json.Marshal(container)
 	if err != nil {
@@ -9,11 +9,14 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"syscall"
 
+	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/reexec"
 	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/namespaces"
 	"github.com/docker/libcontainer/syncpipe"
+	"github.com/docker/libcontainer/system"
 )
 
 func init() {
@@ -27,6 +30,8 @@ func initializer() {
 		pipe    = flag.Int("pipe", 0, "sync pipe fd")
 		console = flag.String("console", "", "console (pty slave) path")
 		root    = flag.String("root", ".", "root path for configuration files")
+		rlimits = flag.String("rlimits", "", "json encoded resource limits")
+		ipc     = flag.String("ipc", "", "path of the ipc namespace to join")
 	)
 
 	flag.Parse()
@@ -53,6 +58,14 @@ func initializer() {
 		writeError(err)
 	}
 
+	if err := execdriver.SetupRlimits(*rlimits); err != nil {
+		writeError(err)
+	}
+
+	if err := joinIpc(*ipc); err != nil {
+		writeError(err)
+	}
+
 	if err := namespaces.Init(container, rootfs, *console, syncPipe, flag.Args()); err != nil {
 		writeError(err)
 	}
@@ -60,6 +73,25 @@ func initializer() {
 	panic("Unreachable")
 }
 
+// joinIpc moves the init process into the IPC namespace at path, which the
+// process it executes inherits.
+func joinIpc(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := system.Setns(f.Fd(), syscall.CLONE_NEWIPC); err != nil {
+		return fmt.Errorf("failed to join ipc namespace %s: %v", path, err)
+	}
+	return nil
+}
+
 func writeError(err error) {
 	fmt.Fprint(os.Stderr, err)
 	os.Exit(1)
@@ -1,9 +1,12 @@
 package execdriver
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
+	"syscall"
 
+	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/utils"
 	"github.com/docker/libcontainer/security/capabilities"
 )
@@ -61,3 +64,22 @@ func TweakCapabilities(basics, adds, drops []string) ([]string, error) {
 
 	return newCaps, nil
 }
+
+// SetupRlimits applies the JSON encoded resource limits a driver passes to
+// its init process, the process it executes inherits them
+func SetupRlimits(data string) error {
+	if data == "" {
+		return nil
+	}
+
+	var rlimits []*ulimit.Rlimit
+	if err := json.Unmarshal([]byte(data), &rlimits); err != nil {
+		return err
+	}
+	for _, rlimit := range rlimits {
+		if err := syscall.Setrlimit(rlimit.Type, &syscall.Rlimit{Cur: rlimit.Soft, Max: rlimit.Hard}); err != nil {
+			return fmt.Errorf("error setting rlimit type %v: %v", rlimit.Type, err)
+		}
+	}
+	return nil
+}
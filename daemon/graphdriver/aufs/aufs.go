@@ -160,7 +160,11 @@ func (a Driver) Exists(id string) bool {
 
 // Three folders are created for each id
 // mnt, layers, and diff
-func (a *Driver) Create(id, parent string) error {
+func (a *Driver) Create(id, parent string, storageOpt map[string]string) error {
+	if len(storageOpt) != 0 {
+		return fmt.Errorf("Storage options are not supported by the aufs storage driver")
+	}
+
 	if err := a.createDirsFor(id); err != nil {
 		return err
 	}
@@ -11,12 +11,15 @@ import "C"
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path"
+	"sync"
 	"syscall"
 	"unsafe"
 
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/pkg/log"
 	"github.com/docker/docker/pkg/mount"
 )
 
@@ -53,6 +56,10 @@ func Init(home string, options []string) (graphdriver.Driver, error) {
 
 type Driver struct {
 	home string
+
+	sync.Mutex   // Protects quotaEnabled and quotaChecked
+	quotaEnabled bool
+	quotaChecked bool // quotaEnabled was read from the filesystem
 }
 
 func (d *Driver) String() string {
@@ -159,6 +166,142 @@ func subvolDelete(path, name string) error {
 	return nil
 }
 
+// subvolQgroupStatus tells if the quota groups of the btrfs filesystem of
+// path are enabled, by looking for their status item in the quota tree.
+func subvolQgroupStatus(path string) (bool, error) {
+	dir, err := openDir(path)
+	if err != nil {
+		return false, err
+	}
+	defer closeDir(dir)
+
+	// BTRFS_QUOTA_TREE_OBJECTID and BTRFS_QGROUP_STATUS_KEY of btrfs/ctree.h
+	var args C.struct_btrfs_ioctl_search_args
+	args.key.tree_id = 8
+	args.key.min_type = 240
+	args.key.max_type = 240
+	args.key.max_objectid = C.__u64(math.MaxUint64)
+	args.key.max_offset = C.__u64(math.MaxUint64)
+	args.key.max_transid = C.__u64(math.MaxUint64)
+	args.key.nr_items = 1
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_TREE_SEARCH,
+		uintptr(unsafe.Pointer(&args)))
+	if errno == syscall.ENOENT {
+		// the quota tree only exists while quotas are enabled
+		return false, nil
+	}
+	if errno != 0 {
+		return false, fmt.Errorf("Failed to search the btrfs quota tree of %s: %v", path, errno.Error())
+	}
+	return args.key.nr_items > 0, nil
+}
+
+// isQuotaEnabled tells if the quota groups of the filesystem are enabled.
+// They may have been enabled before the daemon restarted, so this is read
+// from the filesystem the first time. d must be locked.
+func (d *Driver) isQuotaEnabled() bool {
+	if !d.quotaChecked {
+		enabled, err := subvolQgroupStatus(d.home)
+		if err != nil {
+			log.Debugf("%s", err)
+			return false
+		}
+		d.quotaEnabled = enabled
+		d.quotaChecked = true
+	}
+	return d.quotaEnabled
+}
+
+// subvolEnableQuota enables the quota groups of the btrfs filesystem the
+// driver is on, the first time a subvolume is given a size.
+func (d *Driver) subvolEnableQuota() error {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.isQuotaEnabled() {
+		return nil
+	}
+
+	dir, err := openDir(d.home)
+	if err != nil {
+		return err
+	}
+	defer closeDir(dir)
+
+	var args C.struct_btrfs_ioctl_quota_ctl_args
+	args.cmd = C.BTRFS_QUOTA_CTL_ENABLE
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QUOTA_CTL,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to enable btrfs quota for %s: %v", d.home, errno.Error())
+	}
+
+	d.quotaEnabled = true
+	d.quotaChecked = true
+	return nil
+}
+
+// subvolLimitQgroup limits the data the subvolume at path can refer to.
+func subvolLimitQgroup(path string, size uint64) error {
+	dir, err := openDir(path)
+	if err != nil {
+		return err
+	}
+	defer closeDir(dir)
+
+	// A qgroupid of 0 is the quota group of the subvolume of the fd
+	var args C.struct_btrfs_ioctl_qgroup_limit_args
+	args.lim.max_referenced = C.__u64(size)
+	args.lim.flags = C.BTRFS_QGROUP_LIMIT_MAX_RFER
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QGROUP_LIMIT,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to limit qgroup for %s: %v", path, errno.Error())
+	}
+	return nil
+}
+
+// subvolLookupId returns the id of the subvolume at path, which is also the
+// id of its quota group.
+func subvolLookupId(path string) (uint64, error) {
+	dir, err := openDir(path)
+	if err != nil {
+		return 0, err
+	}
+	defer closeDir(dir)
+
+	// The objectid of the root directory of a subvolume, the
+	// BTRFS_FIRST_FREE_OBJECTID of btrfs/ctree.h
+	var args C.struct_btrfs_ioctl_ino_lookup_args
+	args.objectid = 256
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_INO_LOOKUP,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return 0, fmt.Errorf("Failed to lookup btrfs subvolume id of %s: %v", path, errno.Error())
+	}
+	return uint64(args.treeid), nil
+}
+
+// subvolDestroyQgroup removes the quota group of a deleted subvolume, btrfs
+// keeps them around.
+func (d *Driver) subvolDestroyQgroup(qgroupid uint64) error {
+	dir, err := openDir(d.home)
+	if err != nil {
+		return err
+	}
+	defer closeDir(dir)
+
+	var args C.struct_btrfs_ioctl_qgroup_create_args
+	args.create = 0
+	args.qgroupid = C.__u64(qgroupid)
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QGROUP_CREATE,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to destroy btrfs qgroup %d: %v", qgroupid, errno.Error())
+	}
+	return nil
+}
+
 func (d *Driver) subvolumesDir() string {
 	return path.Join(d.home, "subvolumes")
 }
@@ -167,7 +310,12 @@ func (d *Driver) subvolumesDirId(id string) string {
 	return path.Join(d.subvolumesDir(), id)
 }
 
-func (d *Driver) Create(id string, parent string) error {
+func (d *Driver) Create(id string, parent string, storageOpt map[string]string) error {
+	size, err := graphdriver.ParseStorageOptSize("btrfs", storageOpt)
+	if err != nil {
+		return err
+	}
+
 	subvolumes := path.Join(d.home, "subvolumes")
 	if err := os.MkdirAll(subvolumes, 0700); err != nil {
 		return err
@@ -185,17 +333,47 @@ func (d *Driver) Create(id string, parent string) error {
 			return err
 		}
 	}
+
+	if size > 0 {
+		if err := d.setSize(id, uint64(size)); err != nil {
+			subvolDelete(subvolumes, id)
+			return err
+		}
+	}
 	return nil
 }
 
+func (d *Driver) setSize(id string, size uint64) error {
+	if err := d.subvolEnableQuota(); err != nil {
+		return err
+	}
+	return subvolLimitQgroup(d.subvolumesDirId(id), size)
+}
+
 func (d *Driver) Remove(id string) error {
 	dir := d.subvolumesDirId(id)
 	if _, err := os.Stat(dir); err != nil {
 		return err
 	}
+
+	d.Lock()
+	quotaEnabled := d.isQuotaEnabled()
+	d.Unlock()
+
+	var qgroupid uint64
+	if quotaEnabled {
+		qgroupid, _ = subvolLookupId(dir)
+	}
+
 	if err := subvolDelete(d.subvolumesDir(), id); err != nil {
 		return err
 	}
+
+	if qgroupid != 0 {
+		if err := d.subvolDestroyQgroup(qgroupid); err != nil {
+			log.Debugf("%s", err)
+		}
+	}
 	return os.RemoveAll(dir)
 }
 
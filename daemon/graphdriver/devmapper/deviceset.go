@@ -677,12 +677,67 @@ func (devices *DeviceSet) initDevmapper(doInit bool) error {
 	return nil
 }
 
-func (devices *DeviceSet) AddDevice(hash, baseHash string) error {
+// growFS grows the filesystem of a device to the size of the device, for
+// devices created bigger than the device they are a snapshot of.
+func (devices *DeviceSet) growFS(info *DevInfo) error {
+	if err := devices.activateDeviceIfNeeded(info); err != nil {
+		return fmt.Errorf("Error activating devmapper device: %s", err)
+	}
+	defer devices.deactivateDevice(info)
+
+	fsMountPoint, err := ioutil.TempDir(devices.root, "growfs-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(fsMountPoint)
+
+	fstype, err := ProbeFsType(info.DevName())
+	if err != nil {
+		return err
+	}
+
+	options := ""
+	if fstype == "xfs" {
+		// XFS needs nouuid or it can't mount filesystems with the same fs
+		options = joinMountOptions(options, "nouuid")
+	}
+	options = joinMountOptions(options, devices.mountOptions)
+
+	if err := syscall.Mount(info.DevName(), fsMountPoint, fstype, syscall.MS_MGC_VAL, options); err != nil {
+		return fmt.Errorf("Error mounting '%s' on '%s': %s", info.DevName(), fsMountPoint, err)
+	}
+	defer syscall.Unmount(fsMountPoint, syscall.MNT_DETACH)
+
+	var out []byte
+	switch fstype {
+	case "ext4":
+		out, err = exec.Command("resize2fs", info.DevName()).CombinedOutput()
+	case "xfs":
+		out, err = exec.Command("xfs_growfs", fsMountPoint).CombinedOutput()
+	default:
+		return fmt.Errorf("Unsupported filesystem type %s", fstype)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to grow the filesystem of %s: %s (%s)", info.DevName(), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// AddDevice creates the device hash as a snapshot of the device baseHash.
+// The new device has the size of the base device, unless size is set.
+func (devices *DeviceSet) AddDevice(hash, baseHash string, size uint64) error {
 	baseInfo, err := devices.lookupDevice(baseHash)
 	if err != nil {
 		return err
 	}
 
+	if size == 0 {
+		size = baseInfo.Size
+	}
+	if size < baseInfo.Size {
+		return fmt.Errorf("Container size cannot be smaller than %s", units.HumanSize(int64(baseInfo.Size)))
+	}
+
 	baseInfo.lock.Lock()
 	defer baseInfo.lock.Unlock()
 
@@ -703,11 +758,19 @@ func (devices *DeviceSet) AddDevice(hash, baseHash string) error {
 	// Ids are 24bit, so wrap around
 	devices.nextDeviceId = (deviceId + 1) & 0xffffff
 
-	if _, err := devices.registerDevice(deviceId, hash, baseInfo.Size); err != nil {
+	info, err := devices.registerDevice(deviceId, hash, size)
+	if err != nil {
 		deleteDevice(devices.getPoolDevName(), deviceId)
 		log.Debugf("Error registering device: %s", err)
 		return err
 	}
+
+	if size > baseInfo.Size {
+		if err := devices.growFS(info); err != nil {
+			devices.deleteDevice(info)
+			return err
+		}
+	}
 	return nil
 }
 
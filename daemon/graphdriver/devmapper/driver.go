@@ -79,8 +79,13 @@ func (d *Driver) Cleanup() error {
 	return err
 }
 
-func (d *Driver) Create(id, parent string) error {
-	if err := d.DeviceSet.AddDevice(id, parent); err != nil {
+func (d *Driver) Create(id, parent string, storageOpt map[string]string) error {
+	size, err := graphdriver.ParseStorageOptSize("devicemapper", storageOpt)
+	if err != nil {
+		return err
+	}
+
+	if err := d.DeviceSet.AddDevice(id, parent, uint64(size)); err != nil {
 		return err
 	}
 
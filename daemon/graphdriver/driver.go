@@ -5,16 +5,19 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/mount"
+	"github.com/docker/docker/pkg/units"
 )
 
 type FsMagic uint64
 
 const (
-	FsMagicBtrfs = FsMagic(0x9123683E)
-	FsMagicAufs  = FsMagic(0x61756673)
+	FsMagicBtrfs   = FsMagic(0x9123683E)
+	FsMagicAufs    = FsMagic(0x61756673)
+	FsMagicOverlay = FsMagic(0x794C7630)
 )
 
 type InitFunc func(root string, options []string) (Driver, error)
@@ -29,8 +32,9 @@ type ProtoDriver interface {
 	// String returns a string representation of this driver.
 	String() string
 	// Create creates a new, empty, filesystem layer with the
-	// specified id and parent. Parent may be "".
-	Create(id, parent string) error
+	// specified id and parent. Parent may be "". storageOpt holds
+	// options for the layer, like its "size", and may be nil.
+	Create(id, parent string, storageOpt map[string]string) error
 	// Remove attempts to remove the filesystem layer with this id.
 	Remove(id string) error
 	// Get returns the mountpoint for the layered filesystem referred
@@ -80,6 +84,7 @@ var (
 		"aufs",
 		"btrfs",
 		"devicemapper",
+		"overlay",
 		"vfs",
 	}
 
@@ -140,6 +145,26 @@ func New(root string, options []string) (driver Driver, err error) {
 	return nil, fmt.Errorf("No supported storage backend found")
 }
 
+// ParseStorageOptSize returns the size in bytes set with the "size" option
+// of storageOpt, or 0 when it isn't set. The size is a human readable size,
+// like 10G. Any other option is an error, as no driver supports one.
+func ParseStorageOptSize(driver string, storageOpt map[string]string) (int64, error) {
+	var size int64
+	for key, val := range storageOpt {
+		switch strings.ToLower(key) {
+		case "size":
+			bytes, err := units.RAMInBytes(val)
+			if err != nil {
+				return 0, fmt.Errorf("Invalid storage option size %s: %s", val, err)
+			}
+			size = bytes
+		default:
+			return 0, fmt.Errorf("Unknown storage option %s for the %s storage driver", key, driver)
+		}
+	}
+	return size, nil
+}
+
 func MakePrivate(mountPoint string) error {
 	mounted, err := mount.Mounted(mountPoint)
 	if err != nil {
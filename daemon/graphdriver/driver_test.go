@@ -0,0 +1,30 @@
+package graphdriver
+
+import "testing"
+
+func TestParseStorageOptSize(t *testing.T) {
+	for opts, expected := range map[string]int64{
+		"":     0,
+		"10G":  10 * 1024 * 1024 * 1024,
+		"512m": 512 * 1024 * 1024,
+	} {
+		storageOpt := map[string]string{}
+		if opts != "" {
+			storageOpt["size"] = opts
+		}
+		size, err := ParseStorageOptSize("vfs", storageOpt)
+		if err != nil {
+			t.Fatalf("Unexpected error for size %q: %s", opts, err)
+		}
+		if size != expected {
+			t.Fatalf("Expected size %q to be %d bytes, got %d", opts, expected, size)
+		}
+	}
+
+	if _, err := ParseStorageOptSize("vfs", map[string]string{"size": "big"}); err == nil {
+		t.Fatal("Expected an error for an invalid size")
+	}
+	if _, err := ParseStorageOptSize("vfs", map[string]string{"inodes": "1000"}); err == nil {
+		t.Fatal("Expected an error for an unknown storage option")
+	}
+}
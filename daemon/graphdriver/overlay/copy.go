@@ -0,0 +1,159 @@
+// +build linux
+
+package overlay
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/docker/docker/pkg/system"
+)
+
+type copyFlags int
+
+const (
+	copyHardlink copyFlags = 1 << iota
+)
+
+func copyRegular(srcPath, dstPath string, mode os.FileMode) error {
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE, mode)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	_, err = io.Copy(dstFile, srcFile)
+	return err
+}
+
+func copyXattr(srcPath, dstPath, attr string) error {
+	data, err := system.Lgetxattr(srcPath, attr)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		if err := system.Lsetxattr(dstPath, attr, data, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyDir copies the content of srcDir into dstDir, with the ownership,
+// permissions, modification times and the xattrs that matter to layers.
+// With copyHardlink, regular files are hard linked instead of copied.
+func copyDir(srcDir, dstDir string, flags copyFlags) error {
+	var dirs []string
+
+	err := filepath.Walk(srcDir, func(srcPath string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Rebase path
+		relPath, err := filepath.Rel(srcDir, srcPath)
+		if err != nil {
+			return err
+		}
+
+		dstPath := filepath.Join(dstDir, relPath)
+
+		stat, ok := f.Sys().(*syscall.Stat_t)
+		if !ok {
+			return fmt.Errorf("Unable to get raw syscall.Stat_t data for %s", srcPath)
+		}
+
+		switch f.Mode() & os.ModeType {
+		case 0: // Regular file
+			if flags&copyHardlink != 0 {
+				// The link shares the metadata of the file, nothing
+				// else to copy
+				return os.Link(srcPath, dstPath)
+			}
+			if err := copyRegular(srcPath, dstPath, f.Mode()); err != nil {
+				return err
+			}
+
+		case os.ModeDir:
+			if err := os.Mkdir(dstPath, f.Mode()); err != nil && !os.IsExist(err) {
+				return err
+			}
+			dirs = append(dirs, dstPath)
+
+		case os.ModeSymlink:
+			link, err := os.Readlink(srcPath)
+			if err != nil {
+				return err
+			}
+
+			if err := os.Symlink(link, dstPath); err != nil {
+				return err
+			}
+
+		case os.ModeNamedPipe, os.ModeSocket, os.ModeDevice, os.ModeDevice | os.ModeCharDevice:
+			// This also copies the whiteouts of overlay upper directories,
+			// which are character devices
+			if err := syscall.Mknod(dstPath, stat.Mode, int(stat.Rdev)); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("Unknown file type for %s", srcPath)
+		}
+
+		if err := os.Lchown(dstPath, int(stat.Uid), int(stat.Gid)); err != nil {
+			return err
+		}
+
+		if err := copyXattr(srcPath, dstPath, "security.capability"); err != nil {
+			return err
+		}
+
+		// overlayfs sets this attribute on the directories of the upper
+		// directory that were removed and created again, they hide the
+		// content of the lower directory
+		if err := copyXattr(srcPath, dstPath, "trusted.overlay.opaque"); err != nil {
+			return err
+		}
+
+		if f.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+
+		if err := os.Chmod(dstPath, f.Mode()); err != nil {
+			return err
+		}
+
+		// Directory mtimes are set once all their entries are copied
+		if f.IsDir() {
+			return nil
+		}
+		return syscall.UtimesNano(dstPath, []syscall.Timespec{stat.Atim, stat.Mtim})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Set the deepest directories first, setting the times of a directory
+	// doesn't change the times of its parent
+	for i := len(dirs) - 1; i >= 0; i-- {
+		var stat syscall.Stat_t
+		srcPath := filepath.Join(srcDir, dirs[i][len(dstDir):])
+		if err := syscall.Lstat(srcPath, &stat); err != nil {
+			return err
+		}
+		if err := syscall.UtimesNano(dirs[i], []syscall.Timespec{stat.Atim, stat.Mtim}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,515 @@
+// +build linux
+
+/*
+
+overlay driver directory structure
+
+.
+├── 1            // A layer without an overlay, like the base image
+│   └── root     // The full root filesystem of the layer
+└── 2            // An overlay layer, like a container
+    ├── lower-id // The id of the layer mounted as the lower directory
+    ├── upper    // The changes of the layer
+    ├── work     // The work directory of overlayfs
+    └── merged   // The mount point of the overlay
+
+overlayfs only mounts a single lower directory, so every layer that has a
+parent is either a full copy of the parent's root, hard linked to it, or an
+overlay on top of the root of its closest ancestor that has one.
+
+*/
+
+package overlay
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/log"
+	mountpk "github.com/docker/docker/pkg/mount"
+	"github.com/docker/docker/pkg/system"
+	"github.com/docker/libcontainer/label"
+)
+
+var incompatibleFsMagic = []graphdriver.FsMagic{
+	graphdriver.FsMagicAufs,
+	graphdriver.FsMagicOverlay,
+}
+
+func init() {
+	graphdriver.Register("overlay", Init)
+}
+
+type activeMount struct {
+	count   int
+	path    string
+	mounted bool
+}
+
+type Driver struct {
+	home       string
+	sync.Mutex // Protects concurrent modification to active
+	active     map[string]*activeMount
+	naiveDiff  graphdriver.Driver
+}
+
+// Init returns a new overlay driver.
+// An error is returned if overlayfs is not supported.
+func Init(home string, options []string) (graphdriver.Driver, error) {
+	if err := supportsOverlay(); err != nil {
+		return nil, graphdriver.ErrNotSupported
+	}
+
+	var buf syscall.Statfs_t
+	if err := syscall.Statfs(path.Dir(home), &buf); err != nil {
+		return nil, fmt.Errorf("Couldn't stat the root directory: %s", err)
+	}
+
+	for _, magic := range incompatibleFsMagic {
+		if graphdriver.FsMagic(buf.Type) == magic {
+			return nil, graphdriver.ErrIncompatibleFS
+		}
+	}
+
+	if err := os.MkdirAll(home, 0700); err != nil {
+		return nil, err
+	}
+
+	if err := graphdriver.MakePrivate(home); err != nil {
+		return nil, err
+	}
+
+	d := &Driver{
+		home:   home,
+		active: make(map[string]*activeMount),
+	}
+	d.naiveDiff = graphdriver.NaiveDiffDriver(d)
+
+	return d, nil
+}
+
+// Return a nil error if the kernel supports overlayfs
+func supportsOverlay() error {
+	// We can try to modprobe overlay first before looking at
+	// proc/filesystems for when overlay is supported
+	exec.Command("modprobe", "overlay").Run()
+
+	f, err := os.Open("/proc/filesystems")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if s.Text() == "nodev\toverlay" {
+			return nil
+		}
+	}
+	log.Errorf("'overlay' not found as a supported filesystem on this host. Please ensure kernel is new enough and has overlay support loaded.")
+	return graphdriver.ErrNotSupported
+}
+
+func (d *Driver) String() string {
+	return "overlay"
+}
+
+func (d *Driver) Status() [][2]string {
+	return [][2]string{
+		{"Root Dir", d.home},
+	}
+}
+
+func (d *Driver) Cleanup() error {
+	return mountpk.Unmount(d.home)
+}
+
+func (d *Driver) dir(id string) string {
+	return path.Join(d.home, path.Base(id))
+}
+
+func (d *Driver) Exists(id string) bool {
+	_, err := os.Stat(d.dir(id))
+	return err == nil
+}
+
+func (d *Driver) Create(id, parent string, storageOpt map[string]string) (retErr error) {
+	if len(storageOpt) != 0 {
+		return fmt.Errorf("Storage options are not supported by the overlay storage driver")
+	}
+
+	dir := d.dir(id)
+	if err := os.MkdirAll(path.Dir(dir), 0700); err != nil {
+		return err
+	}
+	if err := os.Mkdir(dir, 0700); err != nil {
+		return err
+	}
+
+	defer func() {
+		// Clean up on failure
+		if retErr != nil {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	// Layers without a parent are plain directories
+	if parent == "" {
+		return os.Mkdir(path.Join(dir, "root"), 0755)
+	}
+
+	parentDir := d.dir(parent)
+
+	// If the parent has a root, the layer is an overlay on top of it
+	if st, err := os.Lstat(path.Join(parentDir, "root")); err == nil {
+		return d.createOverlay(dir, parent, st)
+	}
+
+	// Otherwise the layer shares the lower directory of the parent, and
+	// starts with a copy of its changes
+	lowerId, err := ioutil.ReadFile(path.Join(parentDir, "lower-id"))
+	if err != nil {
+		return err
+	}
+
+	parentUpperDir := path.Join(parentDir, "upper")
+	st, err := os.Lstat(parentUpperDir)
+	if err != nil {
+		return err
+	}
+
+	if err := d.createOverlay(dir, string(lowerId), st); err != nil {
+		return err
+	}
+	return copyDir(parentUpperDir, path.Join(dir, "upper"), 0)
+}
+
+// createOverlay sets up the directories of an overlay on top of the root of
+// the layer lowerId. The upper directory gets the mode and the owner of
+// root, the root directory of the mounted overlay comes from it.
+func (d *Driver) createOverlay(dir, lowerId string, root os.FileInfo) error {
+	upperDir := path.Join(dir, "upper")
+	if err := os.Mkdir(upperDir, root.Mode()); err != nil {
+		return err
+	}
+	if stat, ok := root.Sys().(*syscall.Stat_t); ok {
+		if err := os.Chown(upperDir, int(stat.Uid), int(stat.Gid)); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(path.Join(dir, "work"), 0700); err != nil {
+		return err
+	}
+	if err := os.Mkdir(path.Join(dir, "merged"), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(dir, "lower-id"), []byte(lowerId), 0666)
+}
+
+func (d *Driver) Remove(id string) error {
+	dir := d.dir(id)
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+func (d *Driver) Get(id string, mountLabel string) (string, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	mount := d.active[id]
+	if mount != nil {
+		mount.count++
+		return mount.path, nil
+	}
+
+	mount = &activeMount{count: 1}
+
+	dir := d.dir(id)
+	if _, err := os.Stat(dir); err != nil {
+		return "", err
+	}
+
+	// If id has a root, just return it
+	rootDir := path.Join(dir, "root")
+	if _, err := os.Stat(rootDir); err == nil {
+		mount.path = rootDir
+		d.active[id] = mount
+		return mount.path, nil
+	}
+
+	lowerId, err := ioutil.ReadFile(path.Join(dir, "lower-id"))
+	if err != nil {
+		return "", err
+	}
+	var (
+		lowerDir  = path.Join(d.dir(string(lowerId)), "root")
+		upperDir  = path.Join(dir, "upper")
+		workDir   = path.Join(dir, "work")
+		mergedDir = path.Join(dir, "merged")
+		opts      = fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", lowerDir, upperDir, workDir)
+	)
+	if err := syscall.Mount("overlay", mergedDir, "overlay", 0, label.FormatMountLabel(opts, mountLabel)); err != nil {
+		return "", fmt.Errorf("error creating overlay mount to %s: %v", mergedDir, err)
+	}
+	mount.path = mergedDir
+	mount.mounted = true
+	d.active[id] = mount
+
+	return mount.path, nil
+}
+
+func (d *Driver) Put(id string) {
+	d.Lock()
+	defer d.Unlock()
+
+	mount := d.active[id]
+	if mount == nil {
+		log.Debugf("Put on a non-mounted device %s", id)
+		return
+	}
+
+	mount.count--
+	if mount.count > 0 {
+		return
+	}
+
+	if mount.mounted {
+		if err := syscall.Unmount(mount.path, 0); err != nil {
+			log.Debugf("Failed to unmount %s overlay: %v", id, err)
+		}
+	}
+
+	delete(d.active, id)
+}
+
+// ApplyDiff applies the diff on top of a copy of the parent's root. The
+// files of the copy are hard links to the parent's files, which is safe
+// because ApplyDiff is only called on new layers and never modifies a file
+// in place, it replaces it.
+func (d *Driver) ApplyDiff(id string, parent string, diff archive.ArchiveReader) (size int64, err error) {
+	dir := d.dir(id)
+
+	if parent == "" {
+		return d.naiveDiff.ApplyDiff(id, parent, diff)
+	}
+
+	parentRootDir := path.Join(d.dir(parent), "root")
+	if _, err := os.Stat(parentRootDir); err != nil {
+		return d.naiveDiff.ApplyDiff(id, parent, diff)
+	}
+
+	tmpRootDir, err := ioutil.TempDir(dir, "tmproot")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(tmpRootDir)
+		} else {
+			// The layer is now a full root, drop its overlay
+			for _, name := range []string{"upper", "work", "merged", "lower-id"} {
+				os.RemoveAll(path.Join(dir, name))
+			}
+		}
+	}()
+
+	if err = copyDir(parentRootDir, tmpRootDir, copyHardlink); err != nil {
+		return 0, err
+	}
+
+	if err = archive.ApplyLayer(tmpRootDir, diff); err != nil {
+		return 0, err
+	}
+
+	rootDir := path.Join(dir, "root")
+	if err = os.Rename(tmpRootDir, rootDir); err != nil {
+		return 0, err
+	}
+
+	return layerSize(rootDir, parentRootDir)
+}
+
+// layerSize returns the size of the files of root that are not hard links to
+// the files of parentRoot, the files the layer added or changed.
+func layerSize(root, parentRoot string) (int64, error) {
+	var size int64
+	err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if parentFi, err := os.Lstat(filepath.Join(parentRoot, relPath)); err == nil && os.SameFile(fi, parentFi) {
+			return nil
+		}
+		size += fi.Size()
+		return nil
+	})
+	return size, err
+}
+
+// diffDirs returns the upper directory of id, the root it is an overlay of,
+// and the upper directory of parent when parent is an overlay of the same
+// root. It returns false when the changes between id and parent aren't all
+// in the upper directory of id.
+func (d *Driver) diffDirs(id, parent string) (upperDir, lowerDir, parentUpperDir string, ok bool) {
+	lowerId, err := ioutil.ReadFile(path.Join(d.dir(id), "lower-id"))
+	if err != nil {
+		return "", "", "", false
+	}
+
+	upperDir = path.Join(d.dir(id), "upper")
+	lowerDir = path.Join(d.dir(string(lowerId)), "root")
+
+	if parent == string(lowerId) {
+		return upperDir, lowerDir, "", true
+	}
+
+	parentLowerId, err := ioutil.ReadFile(path.Join(d.dir(parent), "lower-id"))
+	if err != nil || string(parentLowerId) != string(lowerId) {
+		return "", "", "", false
+	}
+	return upperDir, lowerDir, path.Join(d.dir(parent), "upper"), true
+}
+
+// isWhiteout returns whether fi is the whiteout overlayfs leaves in the
+// upper directory when a file of the lower directory is removed.
+func isWhiteout(fi os.FileInfo) bool {
+	if fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	return ok && stat.Rdev == 0
+}
+
+// isOpaque returns whether the directory of the upper directory hides the
+// content of the same directory in the lower directory.
+func isOpaque(dir string) bool {
+	opaque, err := system.Lgetxattr(dir, "trusted.overlay.opaque")
+	return err == nil && string(opaque) == "y"
+}
+
+type changesByPath []archive.Change
+
+func (c changesByPath) Len() int           { return len(c) }
+func (c changesByPath) Less(i, j int) bool { return c[i].Path < c[j].Path }
+func (c changesByPath) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// overlayChanges translates the changes between the upper directories of a
+// layer and of its parent to the changes of the layer: whiteouts are
+// deletions, files that replace a file of the lower directory are
+// modifications, and opaque directories delete the entries of the lower
+// directory they hide.
+func overlayChanges(upperDir, lowerDir, parentUpperDir string) ([]archive.Change, error) {
+	changes, err := archive.ChangesDirs(upperDir, parentUpperDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var hidden []archive.Change
+	for i := range changes {
+		change := &changes[i]
+		if change.Kind == archive.ChangeDelete {
+			continue
+		}
+
+		upperPath := filepath.Join(upperDir, change.Path)
+		fi, err := os.Lstat(upperPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if isWhiteout(fi) {
+			change.Kind = archive.ChangeDelete
+			continue
+		}
+
+		lowerPath := filepath.Join(lowerDir, change.Path)
+		if change.Kind == archive.ChangeAdd {
+			if _, err := os.Lstat(lowerPath); err == nil {
+				change.Kind = archive.ChangeModify
+			}
+		}
+
+		if !fi.IsDir() || !isOpaque(upperPath) {
+			continue
+		}
+		if parentUpperDir != "" && isOpaque(filepath.Join(parentUpperDir, change.Path)) {
+			continue
+		}
+		lowerFis, err := ioutil.ReadDir(lowerPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, lowerFi := range lowerFis {
+			if _, err := os.Lstat(filepath.Join(upperPath, lowerFi.Name())); os.IsNotExist(err) {
+				hidden = append(hidden, archive.Change{
+					Path: filepath.Join(change.Path, lowerFi.Name()),
+					Kind: archive.ChangeDelete,
+				})
+			}
+		}
+	}
+
+	changes = append(changes, hidden...)
+	sort.Sort(changesByPath(changes))
+	return changes, nil
+}
+
+// Changes produces a list of changes between the specified layer
+// and its parent layer. If parent is "", then all changes will be ADD changes.
+func (d *Driver) Changes(id, parent string) ([]archive.Change, error) {
+	upperDir, lowerDir, parentUpperDir, ok := d.diffDirs(id, parent)
+	if !ok {
+		return d.naiveDiff.Changes(id, parent)
+	}
+	return overlayChanges(upperDir, lowerDir, parentUpperDir)
+}
+
+// Diff produces an archive of the changes between the specified
+// layer and its parent layer which may be "".
+func (d *Driver) Diff(id, parent string) (archive.Archive, error) {
+	upperDir, lowerDir, parentUpperDir, ok := d.diffDirs(id, parent)
+	if !ok {
+		return d.naiveDiff.Diff(id, parent)
+	}
+
+	changes, err := overlayChanges(upperDir, lowerDir, parentUpperDir)
+	if err != nil {
+		return nil, err
+	}
+	return archive.ExportChanges(upperDir, changes)
+}
+
+// DiffSize calculates the changes between the specified layer
+// and its parent and returns the size in bytes of the changes
+// relative to its base filesystem directory.
+func (d *Driver) DiffSize(id, parent string) (int64, error) {
+	upperDir, lowerDir, parentUpperDir, ok := d.diffDirs(id, parent)
+	if !ok {
+		return d.naiveDiff.DiffSize(id, parent)
+	}
+
+	changes, err := overlayChanges(upperDir, lowerDir, parentUpperDir)
+	if err != nil {
+		return 0, err
+	}
+	return archive.ChangesSize(upperDir, changes), nil
+}
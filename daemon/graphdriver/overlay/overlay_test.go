@@ -0,0 +1,158 @@
+// +build linux
+
+package overlay
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver/graphtest"
+)
+
+// This avoids creating a new driver for each test if all tests are run
+// Make sure to put new tests between TestOverlaySetup and TestOverlayTeardown
+func TestOverlaySetup(t *testing.T) {
+	graphtest.GetDriver(t, "overlay")
+}
+
+func TestOverlayCreateEmpty(t *testing.T) {
+	graphtest.DriverTestCreateEmpty(t, "overlay")
+}
+
+func TestOverlayCreateBase(t *testing.T) {
+	graphtest.DriverTestCreateBase(t, "overlay")
+}
+
+func TestOverlayCreateSnap(t *testing.T) {
+	graphtest.DriverTestCreateSnap(t, "overlay")
+}
+
+func writeFiles(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		if err := os.MkdirAll(path.Join(dir, path.Dir(file)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path.Join(dir, file), []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOverlayDiff(t *testing.T) {
+	driver := graphtest.GetDriver(t, "overlay")
+	defer graphtest.PutDriver(t)
+
+	if err := driver.Create("base", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := driver.Get("base", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, "a", "dir/b", "dir/c", "odir/old")
+	driver.Put("base")
+
+	// A container: an init layer and the layer of the container on top
+	if err := driver.Create("init", "base", nil); err != nil {
+		t.Fatal(err)
+	}
+	if dir, err = driver.Get("init", ""); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, "hosts")
+	driver.Put("init")
+
+	if err := driver.Create("rw", "init", nil); err != nil {
+		t.Fatal(err)
+	}
+	if dir, err = driver.Get("rw", ""); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, "new")
+	if err := ioutil.WriteFile(path.Join(dir, "a"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path.Join(dir, "dir/b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(path.Join(dir, "odir")); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, "odir/x")
+	driver.Put("rw")
+
+	changes, err := driver.Changes("rw", "init")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for _, change := range changes {
+		result = append(result, change.String())
+	}
+	expected := []string{"C /a", "C /dir", "D /dir/b", "A /new", "C /odir", "D /odir/old", "A /odir/x"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("Expected changes %v, got %v", expected, result)
+	}
+
+	// Commit the changes of the container to an image
+	diff, err := driver.Diff("rw", "init")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer diff.Close()
+
+	if err := driver.Create("image", "base", nil); err != nil {
+		t.Fatal(err)
+	}
+	size, err := driver.ApplyDiff("image", "base", diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := int64(len("changed") + len("new") + len("odir/x")); size != expected {
+		t.Fatalf("Expected a layer size of %d, got %d", expected, size)
+	}
+
+	if dir, err = driver.Get("image", ""); err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Put("image")
+
+	for file, exists := range map[string]bool{
+		"a": true, "new": true, "dir/c": true, "odir/x": true,
+		"dir/b": false, "odir/old": false, "hosts": false,
+	} {
+		if _, err := os.Lstat(path.Join(dir, file)); (err == nil) != exists {
+			t.Fatalf("Expected %s to exist: %t, got %v", file, exists, err)
+		}
+	}
+	if data, err := ioutil.ReadFile(path.Join(dir, "a")); err != nil || string(data) != "changed" {
+		t.Fatalf("Expected the changed content of a, got %q (%v)", data, err)
+	}
+
+	// The files the image didn't change are shared with the base image
+	baseDir, err := driver.Get("base", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Put("base")
+	baseFi, err := os.Lstat(path.Join(baseDir, "dir/c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Lstat(path.Join(dir, "dir/c")); err != nil || !os.SameFile(fi, baseFi) {
+		t.Fatalf("Expected dir/c to be a hard link to the file of the base image (%v)", err)
+	}
+
+	for _, id := range []string{"image", "rw", "init", "base"} {
+		if err := driver.Remove(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOverlayTeardown(t *testing.T) {
+	graphtest.PutDriver(t)
+}
@@ -0,0 +1,268 @@
+// +build linux
+
+// Package quota limits the size of directories with project quotas. Every
+// directory gets its own project id, and a block limit is set on the
+// project. The backing filesystem must support project quotas and have them
+// enabled, like xfs mounted with the pquota option.
+package quota
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/docker/docker/pkg/log"
+)
+
+const (
+	// from linux/quota.h and linux/dqblk_xfs.h
+	qXSetQLim      = 0x5804 // Q_XSETQLIM
+	prjQuota       = 2      // PRJQUOTA
+	fsDquotVersion = 1      // FS_DQUOT_VERSION
+	fsProjQuota    = 2      // FS_PROJ_QUOTA
+	fsDqBSoft      = 1 << 2 // FS_DQ_BSOFT
+	fsDqBHard      = 1 << 3 // FS_DQ_BHARD
+
+	// from linux/fs.h
+	fsIocFsGetXattr       = 0x801c581f // FS_IOC_FSGETXATTR
+	fsIocFsSetXattr       = 0x401c5820 // FS_IOC_FSSETXATTR
+	fsXflagProjInherit    = 0x200      // FS_XFLAG_PROJINHERIT
+	quotaBasicBlockSize   = 512        // the unit of the block limits
+	backingFsBlockDevName = "backingFsBlockDev"
+)
+
+// fsDiskQuota is struct fs_disk_quota from linux/dqblk_xfs.h
+type fsDiskQuota struct {
+	version      int8
+	flags        int8
+	fieldmask    uint16
+	id           uint32
+	blkHardlimit uint64
+	blkSoftlimit uint64
+	inoHardlimit uint64
+	inoSoftlimit uint64
+	bcount       uint64
+	icount       uint64
+	itimer       int32
+	btimer       int32
+	iwarns       uint16
+	bwarns       uint16
+	padding2     int32
+	rtbHardlimit uint64
+	rtbSoftlimit uint64
+	rtbcount     uint64
+	rtbtimer     int32
+	rtbwarns     uint16
+	padding3     int16
+	padding4     [8]byte
+}
+
+// fsxattr is struct fsxattr from linux/fs.h
+type fsxattr struct {
+	xflags     uint32
+	extsize    uint32
+	nextents   uint32
+	projid     uint32
+	cowextsize uint32
+	pad        [8]byte
+}
+
+// Quota is the limit set on a directory
+type Quota struct {
+	Size uint64 // in bytes
+}
+
+// Control sets quotas on the directories of a base directory
+type Control struct {
+	sync.Mutex
+	backingFsBlockDev string
+	nextProjectID     uint32
+	quotas            map[string]uint32
+}
+
+// NewControl returns a Control for the directories of basePath, or an error
+// if the filesystem of basePath can't enforce project quotas. The project ids
+// start after the project id of basePath, and the project ids of the
+// directories already in basePath are kept. The block device node the quotas
+// are set through is created in stateDir, on the same filesystem, so that
+// basePath only holds the directories.
+func NewControl(basePath, stateDir string) (*Control, error) {
+	minProjectID, err := getProjectID(basePath)
+	if err != nil {
+		return nil, err
+	}
+	minProjectID++
+
+	// quotactl(2) needs a block device of the filesystem, create one as the
+	// filesystem may not be mounted from a device node
+	backingFsBlockDev, err := makeBackingFsDev(basePath, stateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// setting an empty quota fails if the filesystem doesn't support project
+	// quotas or they aren't enabled
+	if err := setProjectQuota(backingFsBlockDev, minProjectID, Quota{}); err != nil {
+		os.Remove(backingFsBlockDev)
+		return nil, err
+	}
+
+	q := &Control{
+		backingFsBlockDev: backingFsBlockDev,
+		nextProjectID:     minProjectID + 1,
+		quotas:            make(map[string]uint32),
+	}
+
+	if err := q.findNextProjectID(basePath); err != nil {
+		return nil, err
+	}
+
+	log.Debugf("NewControl(%s): nextProjectID = %d", basePath, q.nextProjectID)
+	return q, nil
+}
+
+// SetQuota sets the quota of targetPath, which must be a directory of the
+// base directory of the Control.
+func (q *Control) SetQuota(targetPath string, quota Quota) error {
+	q.Lock()
+	defer q.Unlock()
+
+	projectID, ok := q.quotas[targetPath]
+	if !ok {
+		projectID = q.nextProjectID
+		if err := setProjectID(targetPath, projectID); err != nil {
+			return err
+		}
+		q.quotas[targetPath] = projectID
+		q.nextProjectID++
+	}
+
+	log.Debugf("SetQuota(%s, %d): projectID=%d", targetPath, quota.Size, projectID)
+	return setProjectQuota(q.backingFsBlockDev, projectID, quota)
+}
+
+// RemoveQuota forgets the project id of targetPath, once it was removed.
+func (q *Control) RemoveQuota(targetPath string) {
+	q.Lock()
+	delete(q.quotas, targetPath)
+	q.Unlock()
+}
+
+// setProjectQuota sets the block limits of a project
+func setProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) error {
+	d := fsDiskQuota{
+		version:      fsDquotVersion,
+		flags:        fsProjQuota,
+		fieldmask:    fsDqBSoft | fsDqBHard,
+		id:           projectID,
+		blkHardlimit: quota.Size / quotaBasicBlockSize,
+		blkSoftlimit: quota.Size / quotaBasicBlockSize,
+	}
+
+	dev, err := syscall.BytePtrFromString(backingFsBlockDev)
+	if err != nil {
+		return err
+	}
+
+	_, _, errno := syscall.Syscall6(syscall.SYS_QUOTACTL, uintptr(qXSetQLim<<8|prjQuota),
+		uintptr(unsafe.Pointer(dev)), uintptr(d.id), uintptr(unsafe.Pointer(&d)), 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("Failed to set quota limit for projid %d on %s: %v", projectID, backingFsBlockDev, errno)
+	}
+	return nil
+}
+
+func getFsxattr(targetPath string) (*fsxattr, error) {
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	var fsx fsxattr
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), fsIocFsGetXattr, uintptr(unsafe.Pointer(&fsx))); errno != 0 {
+		return nil, fmt.Errorf("Failed to get projid for %s: %v", targetPath, errno)
+	}
+	return &fsx, nil
+}
+
+func getProjectID(targetPath string) (uint32, error) {
+	fsx, err := getFsxattr(targetPath)
+	if err != nil {
+		return 0, err
+	}
+	return fsx.projid, nil
+}
+
+// setProjectID sets the project id of a directory, and makes the files
+// created in it inherit it.
+func setProjectID(targetPath string, projectID uint32) error {
+	fsx, err := getFsxattr(targetPath)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	fsx.projid = projectID
+	fsx.xflags |= fsXflagProjInherit
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), fsIocFsSetXattr, uintptr(unsafe.Pointer(fsx))); errno != 0 {
+		return fmt.Errorf("Failed to set projid for %s: %v", targetPath, errno)
+	}
+	return nil
+}
+
+// findNextProjectID records the project ids of the directories in basePath
+// and moves nextProjectID past them.
+func (q *Control) findNextProjectID(basePath string) error {
+	files, err := ioutil.ReadDir(basePath)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		targetPath := filepath.Join(basePath, file.Name())
+		projectID, err := getProjectID(targetPath)
+		if err != nil {
+			return err
+		}
+		if projectID == 0 {
+			continue
+		}
+		q.quotas[targetPath] = projectID
+		if projectID >= q.nextProjectID {
+			q.nextProjectID = projectID + 1
+		}
+	}
+	return nil
+}
+
+// makeBackingFsDev creates in dir a block device node for the filesystem of
+// basePath
+func makeBackingFsDev(basePath, dir string) (string, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(basePath, &stat); err != nil {
+		return "", err
+	}
+
+	backingFsBlockDev := path.Join(dir, backingFsBlockDevName)
+	// the filesystem may have moved to another device since the last start
+	if err := os.Remove(backingFsBlockDev); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := syscall.Mknod(backingFsBlockDev, syscall.S_IFBLK|0600, int(stat.Dev)); err != nil {
+		return "", fmt.Errorf("Failed to mknod %s: %v", backingFsBlockDev, err)
+	}
+	return backingFsBlockDev, nil
+}
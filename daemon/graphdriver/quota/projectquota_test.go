@@ -0,0 +1,19 @@
+// +build linux
+
+package quota
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// The structs passed to the kernel must match the layout of their C
+// definitions.
+func TestStructSizes(t *testing.T) {
+	if size := unsafe.Sizeof(fsDiskQuota{}); size != 112 {
+		t.Fatalf("Expected fs_disk_quota to be 112 bytes, got %d", size)
+	}
+	if size := unsafe.Sizeof(fsxattr{}); size != 28 {
+		t.Fatalf("Expected fsxattr to be 28 bytes, got %d", size)
+	}
+}
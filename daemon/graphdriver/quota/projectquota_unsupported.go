@@ -0,0 +1,25 @@
+// +build !linux
+
+package quota
+
+import "errors"
+
+// ErrQuotaNotSupported is returned by NewControl on platforms without
+// project quotas
+var ErrQuotaNotSupported = errors.New("project quotas are not supported on this platform")
+
+type Quota struct {
+	Size uint64
+}
+
+type Control struct{}
+
+func NewControl(basePath, stateDir string) (*Control, error) {
+	return nil, ErrQuotaNotSupported
+}
+
+func (q *Control) SetQuota(targetPath string, quota Quota) error {
+	return ErrQuotaNotSupported
+}
+
+func (q *Control) RemoveQuota(targetPath string) {}
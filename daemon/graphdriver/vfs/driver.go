@@ -8,6 +8,8 @@ import (
 	"path"
 
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/graphdriver/quota"
+	"github.com/docker/docker/pkg/log"
 	"github.com/docker/libcontainer/label"
 )
 
@@ -19,11 +21,23 @@ func Init(home string, options []string) (graphdriver.Driver, error) {
 	d := &Driver{
 		home: home,
 	}
+
+	// size limits need project quotas on the filesystem of the layers
+	if err := os.MkdirAll(path.Join(home, "dir"), 0700); err != nil {
+		return nil, err
+	}
+	if quotaCtl, err := quota.NewControl(path.Join(home, "dir"), home); err == nil {
+		d.quotaCtl = quotaCtl
+	} else {
+		log.Debugf("vfs: size limits are not supported: %s", err)
+	}
+
 	return graphdriver.NaiveDiffDriver(d), nil
 }
 
 type Driver struct {
-	home string
+	home     string
+	quotaCtl *quota.Control // nil if the filesystem has no project quotas
 }
 
 func (d *Driver) String() string {
@@ -61,7 +75,15 @@ func copyDir(src, dst string) error {
 	return nil
 }
 
-func (d *Driver) Create(id, parent string) error {
+func (d *Driver) Create(id, parent string, storageOpt map[string]string) error {
+	size, err := graphdriver.ParseStorageOptSize("vfs", storageOpt)
+	if err != nil {
+		return err
+	}
+	if size != 0 && d.quotaCtl == nil {
+		return fmt.Errorf("Storage option size is not supported by the vfs storage driver on this filesystem, it needs project quotas")
+	}
+
 	dir := d.dir(id)
 	if err := os.MkdirAll(path.Dir(dir), 0700); err != nil {
 		return err
@@ -69,6 +91,12 @@ func (d *Driver) Create(id, parent string) error {
 	if err := os.Mkdir(dir, 0755); err != nil {
 		return err
 	}
+	if size != 0 {
+		if err := d.quotaCtl.SetQuota(dir, quota.Quota{Size: uint64(size)}); err != nil {
+			os.RemoveAll(dir)
+			return err
+		}
+	}
 	opts := []string{"level:s0"}
 	if _, mountLabel, err := label.InitLabels(opts); err == nil {
 		label.Relabel(dir, mountLabel, "")
@@ -94,6 +122,9 @@ func (d *Driver) Remove(id string) error {
 	if _, err := os.Stat(d.dir(id)); err != nil {
 		return err
 	}
+	if d.quotaCtl != nil {
+		d.quotaCtl.RemoveQuota(d.dir(id))
+	}
 	return os.RemoveAll(d.dir(id))
 }
 
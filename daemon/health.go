@@ -0,0 +1,285 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/log"
+	"github.com/docker/docker/utils"
+)
+
+const (
+	// Health states of a container with a health check
+	HealthStarting  = "starting"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+
+	defaultHealthInterval = 30 * time.Second
+	defaultHealthTimeout  = 30 * time.Second
+	defaultHealthRetries  = 3
+
+	// the number of probe results kept in the health state
+	maxHealthLogEntries = 5
+	// the number of bytes of a probe's output kept in its result
+	maxHealthOutputLen = 4096
+)
+
+// Health is the health of a container with a health check
+type Health struct {
+	Status        string               // HealthStarting, HealthHealthy or HealthUnhealthy
+	FailingStreak int                  // Number of consecutive failed probes
+	Log           []*HealthcheckResult // The results of the last probes
+}
+
+// HealthcheckResult is the result of one run of the health check command
+type HealthcheckResult struct {
+	Start    time.Time
+	End      time.Time
+	ExitCode int
+	Output   string
+}
+
+// limitedBuffer keeps the first maxHealthOutputLen bytes written to it
+type limitedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if left := maxHealthOutputLen - b.buf.Len(); left > 0 {
+		if len(p) > left {
+			b.buf.Write(p[:left])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// initHealthMonitor starts probing the health of a container that was just
+// started, if it has a health check. The container must be locked.
+func (container *Container) initHealthMonitor() {
+	container.stopHealthMonitor()
+
+	config := container.Config.Healthcheck
+	if config == nil || len(config.Test) == 0 || config.Test[0] == "NONE" {
+		container.State.Health = nil
+		return
+	}
+
+	interval := config.Interval
+	if interval == 0 {
+		interval = defaultHealthInterval
+	}
+
+	container.State.Health = &Health{Status: HealthStarting}
+	container.healthStop = make(chan struct{})
+
+	go container.monitorHealth(container.healthStop, interval)
+}
+
+// stopHealthMonitor stops the probes of the container. The container must
+// be locked.
+func (container *Container) stopHealthMonitor() {
+	if container.healthStop != nil {
+		close(container.healthStop)
+		container.healthStop = nil
+	}
+}
+
+func (container *Container) monitorHealth(stop chan struct{}, interval time.Duration) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		if container.IsPaused() {
+			continue
+		}
+
+		result := container.probeHealth()
+
+		select {
+		case <-stop:
+			// the container stopped while the probe ran, its result says
+			// nothing about the health of the container
+			return
+		default:
+		}
+
+		container.handleProbeResult(result)
+	}
+}
+
+// probeHealth runs the health check command in the container with the exec
+// machinery, and returns its result.
+func (container *Container) probeHealth() *HealthcheckResult {
+	config := container.Config.Healthcheck
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	var cmd []string
+	if config.Test[0] == "CMD-SHELL" {
+		cmd = append([]string{"/bin/sh", "-c"}, config.Test[1:]...)
+	} else {
+		cmd = config.Test[1:]
+	}
+
+	result := &HealthcheckResult{
+		Start:    time.Now().UTC(),
+		ExitCode: -1,
+	}
+	if len(cmd) == 0 {
+		result.End = result.Start
+		result.Output = "Health check has no command"
+		return result
+	}
+
+	d := container.daemon
+	entrypoint, args := d.getEntrypointAndArgs(nil, cmd)
+	execConfig := &execConfig{
+		ID:        utils.GenerateRandomID(),
+		Container: container,
+		Running:   true,
+		ProcessConfig: execdriver.ProcessConfig{
+			User:       container.Config.User,
+			Entrypoint: entrypoint,
+			Arguments:  args,
+		},
+	}
+	d.registerExecCommand(execConfig)
+	defer d.unregisterExecCommand(execConfig)
+
+	var (
+		output  = &limitedBuffer{}
+		pids    = make(chan int, 1)
+		exited  = make(chan probeExit, 1)
+		started = func(_ *execdriver.ProcessConfig, pid int) { pids <- pid }
+	)
+	go func() {
+		exitCode, err := d.Exec(container, execConfig, execdriver.NewPipes(nil, output, output, false), started)
+		exited <- probeExit{exitCode, err}
+	}()
+
+	exit := waitProbe(pids, exited, timeout)
+	result.End = time.Now().UTC()
+	result.ExitCode = exit.exitCode
+	result.Output = output.String()
+	if exit.err != nil {
+		result.ExitCode = -1
+		result.Output = strings.TrimSpace(result.Output + "\n" + exit.err.Error())
+	}
+	return result
+}
+
+// probeExit is how the process of a probe exited
+type probeExit struct {
+	exitCode int
+	err      error
+}
+
+// probeKillTimeout bounds the waits for a probe that exceeded its timeout:
+// for the pid of its process, and for the process to exit once killed.
+// killProbe kills the process of a probe. Both are replaced in tests.
+var (
+	probeKillTimeout = 10 * time.Second
+	killProbe        = func(pid int) error { return syscall.Kill(pid, syscall.SIGKILL) }
+)
+
+// waitProbe waits for the process of a probe to exit, with its pid sent on
+// pids once it started. A probe exceeding timeout is killed. A probe that
+// didn't start by then is killed whenever it starts, without waiting for
+// it, so that a stuck exec doesn't stall the health monitor.
+func waitProbe(pids <-chan int, exited <-chan probeExit, timeout time.Duration) probeExit {
+	select {
+	case exit := <-exited:
+		return exit
+	case <-time.After(timeout):
+	}
+
+	timedOut := probeExit{-1, fmt.Errorf("Health check exceeded timeout (%v)", timeout)}
+	select {
+	case pid := <-pids:
+		if err := killProbe(pid); err != nil {
+			log.Debugf("Error killing the health check process %d: %s", pid, err)
+		}
+		select {
+		case <-exited:
+		case <-time.After(probeKillTimeout):
+			log.Errorf("Health check process %d did not exit after being killed", pid)
+		}
+	case <-exited:
+	case <-time.After(probeKillTimeout):
+		log.Errorf("Health check did not start within %v, not waiting for it", timeout+probeKillTimeout)
+		go func() {
+			select {
+			case pid := <-pids:
+				killProbe(pid)
+			case <-exited:
+			}
+		}()
+	}
+	return timedOut
+}
+
+// handleProbeResult records the result of a probe in the container's health
+// and logs a health_status event when the status changes.
+func (container *Container) handleProbeResult(result *HealthcheckResult) {
+	container.Lock()
+
+	health := container.State.Health
+	if health == nil {
+		container.Unlock()
+		return
+	}
+
+	retries := container.Config.Healthcheck.Retries
+	if retries == 0 {
+		retries = defaultHealthRetries
+	}
+
+	health.Log = append(health.Log, result)
+	if len(health.Log) > maxHealthLogEntries {
+		health.Log = health.Log[len(health.Log)-maxHealthLogEntries:]
+	}
+
+	oldStatus := health.Status
+	if result.ExitCode == 0 {
+		health.FailingStreak = 0
+		health.Status = HealthHealthy
+	} else {
+		health.FailingStreak++
+		if health.FailingStreak >= retries {
+			health.Status = HealthUnhealthy
+		}
+	}
+	status := health.Status
+
+	if err := container.toDisk(); err != nil {
+		log.Errorf("Error saving the health of container %s: %s", container.ID, err)
+	}
+	container.Unlock()
+
+	if status != oldStatus {
+		container.LogEvent("health_status: " + status)
+	}
+}
@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/runconfig"
+)
+
+func TestLimitedBuffer(t *testing.T) {
+	buf := &limitedBuffer{}
+	buf.Write([]byte("ok\n"))
+	buf.Write([]byte(strings.Repeat("x", maxHealthOutputLen)))
+
+	out := buf.String()
+	if len(out) != maxHealthOutputLen {
+		t.Fatalf("expected the output to be cut to %d bytes, got %d", maxHealthOutputLen, len(out))
+	}
+	if !strings.HasPrefix(out, "ok\nxxx") {
+		t.Fatalf("expected the start of the output to be kept, got %q", out[:10])
+	}
+}
+
+func TestStateStringHealth(t *testing.T) {
+	s := NewState()
+	s.SetRunning(42)
+	s.Health = &Health{Status: HealthUnhealthy}
+
+	if str := s.String(); !strings.HasSuffix(str, "(unhealthy)") {
+		t.Fatalf("expected the status to show the health, got %q", str)
+	}
+
+	s.SetStopped(0)
+	if str := s.String(); strings.Contains(str, "unhealthy") {
+		t.Fatalf("expected a stopped container's status to not show its health, got %q", str)
+	}
+}
+
+func TestWaitProbe(t *testing.T) {
+	defer func(d time.Duration, f func(int) error) { probeKillTimeout, killProbe = d, f }(probeKillTimeout, killProbe)
+	probeKillTimeout = 10 * time.Millisecond
+	killed := make(chan int, 1)
+	killProbe = func(pid int) error {
+		killed <- pid
+		return nil
+	}
+
+	// a probe exiting in time
+	pids, exited := make(chan int, 1), make(chan probeExit, 1)
+	pids <- 42
+	exited <- probeExit{exitCode: 1}
+	if exit := waitProbe(pids, exited, time.Second); exit.exitCode != 1 || exit.err != nil {
+		t.Fatalf("expected the exit code of the probe, got %+v", exit)
+	}
+
+	// a probe exceeding the timeout is killed
+	pids, exited = make(chan int, 1), make(chan probeExit, 1)
+	pids <- 42
+	if exit := waitProbe(pids, exited, 10*time.Millisecond); exit.exitCode != -1 || exit.err == nil {
+		t.Fatalf("expected the probe to time out, got %+v", exit)
+	}
+	if pid := <-killed; pid != 42 {
+		t.Fatalf("expected the probe process 42 to be killed, got %d", pid)
+	}
+
+	// a probe that never reports its pid doesn't block, and is killed once
+	// it starts
+	pids, exited = make(chan int, 1), make(chan probeExit, 1)
+	done := make(chan probeExit)
+	go func() { done <- waitProbe(pids, exited, 10*time.Millisecond) }()
+	select {
+	case exit := <-done:
+		if exit.err == nil {
+			t.Fatalf("expected the probe to time out, got %+v", exit)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected waiting for a probe that didn't start to be bounded")
+	}
+	pids <- 43
+	select {
+	case pid := <-killed:
+		if pid != 43 {
+			t.Fatalf("expected the probe process 43 to be killed, got %d", pid)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a probe starting late to be killed")
+	}
+}
+
+func TestMonitorCallbackLocksOnRestart(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-health-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	container := &Container{
+		State:      NewState(),
+		root:       root,
+		Config:     &runconfig.Config{Healthcheck: &runconfig.HealthConfig{Test: []string{"CMD", "true"}}},
+		hostConfig: &runconfig.HostConfig{},
+	}
+	container.RestartCount = 1
+	m := &containerMonitor{container: container, startSignal: make(chan struct{})}
+
+	container.Lock()
+	done := make(chan struct{})
+	go func() {
+		m.callback(&execdriver.ProcessConfig{}, 42)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected the callback of a restart to wait for the container lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if container.Running || container.healthStop != nil {
+		t.Fatal("expected the callback of a restart to wait for the container lock")
+	}
+	container.Unlock()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the callback to finish once the container is unlocked")
+	}
+
+	container.Lock()
+	defer container.Unlock()
+	if !container.Running || container.Health == nil || container.Health.Status != HealthStarting {
+		t.Fatalf("expected a running container starting its health check, got %s", container.State.String())
+	}
+	if container.healthStop == nil {
+		t.Fatal("expected the health monitor to be started")
+	}
+	container.stopHealthMonitor()
+	if container.healthStop != nil {
+		t.Fatal("expected the health monitor to be stopped")
+	}
+}
@@ -49,7 +49,8 @@ func (daemon *Daemon) DeleteImage(eng *engine.Engine, name string, imgs *engine.
 		return fmt.Errorf("No such image: %s", name)
 	}
 
-	if strings.Contains(img.ID, name) {
+	// a digest names the image itself, like its ID
+	if strings.Contains(img.ID, name) || graph.IsDigest(tag) {
 		repoName = ""
 		tag = ""
 	}
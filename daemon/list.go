@@ -107,6 +107,9 @@ func (daemon *Daemon) Containers(job *engine.Job) engine.Status {
 				return nil
 			}
 		}
+		if !psFilters.MatchKVList("label", container.Config.Labels) {
+			return nil
+		}
 		displayed++
 		out := &engine.Env{}
 		out.Set("Id", container.ID)
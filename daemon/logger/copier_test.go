@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+)
+
+type TestLoggerJSON struct {
+	sync.Mutex
+	msgs []*Message
+}
+
+func (l *TestLoggerJSON) Log(m *Message) error {
+	l.Lock()
+	l.msgs = append(l.msgs, m)
+	l.Unlock()
+	return nil
+}
+
+func (l *TestLoggerJSON) Close() error { return nil }
+
+func (l *TestLoggerJSON) Name() string { return "json" }
+
+func TestCopier(t *testing.T) {
+	var (
+		stdout = bytes.NewBufferString("line1\nline2\n")
+		stderr = bytes.NewBufferString("error1\npartial")
+		l      = &TestLoggerJSON{}
+	)
+	c := NewCopier("cid", map[string]io.Reader{"stdout": stdout, "stderr": stderr}, l)
+	c.Run()
+	c.Wait()
+
+	lines := map[string][]string{}
+	for _, m := range l.msgs {
+		if m.ContainerID != "cid" {
+			t.Fatalf("Wrong ContainerID: %q, expected %q", m.ContainerID, "cid")
+		}
+		if m.Timestamp.IsZero() {
+			t.Fatalf("Message for %q has no timestamp", m.Line)
+		}
+		lines[m.Source] = append(lines[m.Source], string(m.Line))
+	}
+	if len(lines["stdout"]) != 2 || lines["stdout"][0] != "line1" || lines["stdout"][1] != "line2" {
+		t.Fatalf("Wrong stdout lines: %v", lines["stdout"])
+	}
+	if len(lines["stderr"]) != 2 || lines["stderr"][1] != "partial" {
+		t.Fatalf("Wrong stderr lines: %v", lines["stderr"])
+	}
+}
@@ -0,0 +1,180 @@
+package jsonfilelog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/jsonlog"
+	"github.com/docker/docker/pkg/units"
+)
+
+const Name = "json-file"
+
+// JSONFileLogger is Logger implementation for default docker logging:
+// JSON objects to file, rotated once they grow past max-size
+type JSONFileLogger struct {
+	buf      *bytes.Buffer
+	f        *os.File   // store for closing
+	mu       sync.Mutex // protects buffer
+	capacity int64      // maximum size of each file, -1 if unlimited
+	n        int        // maximum number of files
+	size     int64      // size of the current file
+	path     string
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		panic(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		panic(err)
+	}
+}
+
+// parseLogOpt returns the maximum size of each log file, -1 if unlimited,
+// and the maximum number of files from the max-size and max-file options
+func parseLogOpt(cfg map[string]string) (int64, int, error) {
+	var (
+		capacity int64 = -1
+		maxFiles       = 1
+		err      error
+	)
+	for key := range cfg {
+		if key != "max-size" && key != "max-file" {
+			return 0, 0, fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	if maxSize, ok := cfg["max-size"]; ok {
+		capacity, err = units.FromHumanSize(maxSize)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if maxFile, ok := cfg["max-file"]; ok {
+		maxFiles, err = strconv.Atoi(maxFile)
+		if err != nil {
+			return 0, 0, err
+		}
+		if maxFiles < 1 {
+			return 0, 0, fmt.Errorf("max-file cannot be less than 1")
+		}
+	}
+	return capacity, maxFiles, nil
+}
+
+// ValidateLogOpt checks the max-size and max-file options
+func ValidateLogOpt(cfg map[string]string) error {
+	_, _, err := parseLogOpt(cfg)
+	return err
+}
+
+// New creates new JSONFileLogger which writes to filename
+func New(ctx logger.Context) (logger.Logger, error) {
+	capacity, maxFiles, err := parseLogOpt(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := os.OpenFile(ctx.LogPath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := log.Stat()
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+	return &JSONFileLogger{
+		f:        log,
+		buf:      bytes.NewBuffer(nil),
+		capacity: capacity,
+		n:        maxFiles,
+		size:     fi.Size(),
+		path:     ctx.LogPath,
+	}, nil
+}
+
+// Log converts logger.Message to jsonlog.JSONLog and serializes it to file
+func (l *JSONFileLogger) Log(msg *logger.Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// the newline is kept in the log line itself, as docker logs has
+	// always written it back verbatim
+	err := (&jsonlog.JSONLog{Log: string(msg.Line) + "\n", Stream: msg.Source, Created: msg.Timestamp}).MarshalJSONBuf(l.buf)
+	if err != nil {
+		return err
+	}
+	l.buf.WriteByte('\n')
+	if err := l.rotate(int64(l.buf.Len())); err != nil {
+		l.buf.Reset()
+		return err
+	}
+	n, err := l.buf.WriteTo(l.f)
+	l.size += n
+	return err
+}
+
+// rotate shifts <path>.N-1 to <path>.N down to <path> to <path>.1 and
+// starts a new file when writing size more bytes would overflow capacity.
+// The current file is only closed once the new one is open, so that the
+// logger keeps a file to write to if rotating fails.
+func (l *JSONFileLogger) rotate(size int64) error {
+	if l.capacity == -1 || l.size+size <= l.capacity || l.size == 0 {
+		return nil
+	}
+	if l.n > 1 {
+		for i := l.n - 1; i > 1; i-- {
+			if err := os.Rename(rotatedName(l.path, i-1), rotatedName(l.path, i)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(l.path, rotatedName(l.path, 1)); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
+	if err != nil {
+		if l.n > 1 {
+			// move the current file back so that it is still the one at path
+			os.Rename(rotatedName(l.path, 1), l.path)
+		}
+		return err
+	}
+	l.f.Close()
+	l.f = f
+	l.size = 0
+	return nil
+}
+
+// Close closes underlying file
+func (l *JSONFileLogger) Close() error {
+	return l.f.Close()
+}
+
+// Name returns name of this logger
+func (l *JSONFileLogger) Name() string {
+	return Name
+}
+
+func rotatedName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// Files returns the paths of the existing log files written for path,
+// oldest first, so that they can be read back in order.
+func Files(path string) []string {
+	var files []string
+	for i := 1; ; i++ {
+		name := rotatedName(path, i)
+		if _, err := os.Stat(name); err != nil {
+			break
+		}
+		files = append([]string{name}, files...)
+	}
+	return append(files, path)
+}
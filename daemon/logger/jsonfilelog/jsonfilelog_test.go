@@ -0,0 +1,152 @@
+package jsonfilelog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+func TestJSONFileLogger(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	l, err := New(logger.Context{LogPath: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	cid := "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657"
+	if err := l.Log(&logger.Message{ContainerID: cid, Line: []byte("line1"), Source: "src1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(&logger.Message{ContainerID: cid, Line: []byte("line2"), Source: "src2"}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"log":"line1\n","stream":"src1","time":"0001-01-01T00:00:00Z"}
+{"log":"line2\n","stream":"src2","time":"0001-01-01T00:00:00Z"}
+`
+
+	if string(res) != expected {
+		t.Fatalf("Wrong log content: %q, expected %q", res, expected)
+	}
+}
+
+func TestJSONFileLoggerRotate(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	l, err := New(logger.Context{LogPath: filename, Config: map[string]string{"max-size": "250", "max-file": "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < 10; i++ {
+		if err := l.Log(&logger.Message{Line: []byte(strings.Repeat("x", 40)), Source: "stdout", Timestamp: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := Files(filename)
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 log files, got %v", files)
+	}
+	if files[0] != filename+".2" || files[2] != filename {
+		t.Fatalf("Log files are not ordered oldest first: %v", files)
+	}
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() > 250 {
+			t.Fatalf("%s is %d bytes, larger than max-size", f, fi.Size())
+		}
+	}
+	if _, err := os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("Expected only max-file files to be kept")
+	}
+}
+
+func TestJSONFileLoggerRotateError(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	l, err := New(logger.Context{LogPath: filename, Config: map[string]string{"max-size": "100", "max-file": "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	msg := &logger.Message{Line: []byte(strings.Repeat("x", 40)), Source: "stdout"}
+	if err := l.Log(msg); err != nil {
+		t.Fatal(err)
+	}
+	// a directory in the way of the rotated file makes the rotation fail
+	if err := os.MkdirAll(filepath.Join(filename+".1", "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(msg); err == nil {
+		t.Fatal("Expected an error when the log file can't be rotated")
+	}
+	if err := os.RemoveAll(filename + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(msg); err != nil {
+		t.Fatalf("Expected the logger to keep working after a failed rotation: %v", err)
+	}
+	files := Files(filename)
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 log files, got %v", files)
+	}
+	for _, f := range files {
+		res, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(string(res), "\n") != 1 {
+			t.Fatalf("Expected one line in %s, got %q", f, res)
+		}
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"max-files": "3"},
+		{"max-size": "ten"},
+		{"max-file": "0"},
+	} {
+		if err := ValidateLogOpt(cfg); err == nil {
+			t.Fatalf("Expected an error for %v", cfg)
+		}
+	}
+	if err := ValidateLogOpt(map[string]string{"max-size": "10m", "max-file": "3"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONFileLoggerInvalidOpt(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	if _, err := New(logger.Context{LogPath: filepath.Join(tmp, "container.log"), Config: map[string]string{"max-files": "3"}}); err == nil {
+		t.Fatal("Expected an error for an unknown option")
+	}
+}
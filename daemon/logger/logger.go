@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrReadLogsNotSupported = errors.New("configured logging driver does not support reading")
+
+// Message is a single line written by a container to stdout or stderr
+type Message struct {
+	ContainerID string
+	Line        []byte
+	Source      string
+	Timestamp   time.Time
+}
+
+// Logger is the interface for docker logging drivers
+type Logger interface {
+	Log(*Message) error
+	Name() string
+	Close() error
+}
+
+// Context provides a log driver with the container it logs for and the
+// options it was configured with
+type Context struct {
+	Config        map[string]string
+	ContainerID   string
+	ContainerName string
+	LogPath       string
+}
+
+// Creator is a method that builds a logging driver instance with given context
+type Creator func(Context) (Logger, error)
+
+// LogOptValidator checks the options of a logging driver without building it
+type LogOptValidator func(cfg map[string]string) error
+
+var (
+	// All registered logging drivers
+	drivers    = make(map[string]Creator)
+	validators = make(map[string]LogOptValidator)
+	mu         sync.Mutex
+)
+
+// RegisterLogDriver registers the given logging driver builder with given logging
+// driver name.
+func RegisterLogDriver(name string, c Creator) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := drivers[name]; exists {
+		return fmt.Errorf("Name already registered %s", name)
+	}
+	drivers[name] = c
+	return nil
+}
+
+// GetLogDriver provides the logging driver builder for a logging driver name.
+func GetLogDriver(name string) (Creator, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, ok := drivers[name]
+	if !ok {
+		return nil, fmt.Errorf("logger: no log driver named '%s' is registered", name)
+	}
+	return c, nil
+}
+
+// RegisterLogOptValidator registers the validator of the options of the
+// logging driver with given name.
+func RegisterLogOptValidator(name string, v LogOptValidator) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := validators[name]; exists {
+		return fmt.Errorf("Log opt validator already registered for %s", name)
+	}
+	validators[name] = v
+	return nil
+}
+
+// ValidateLogOpts checks that a logging driver is registered under name and
+// that it accepts the given options, so that bad options are reported when
+// they are set rather than when a container starts.
+func ValidateLogOpts(name string, cfg map[string]string) error {
+	mu.Lock()
+	_, ok := drivers[name]
+	v := validators[name]
+	mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("logger: no log driver named '%s' is registered", name)
+	}
+	if v == nil {
+		return nil
+	}
+	return v(cfg)
+}
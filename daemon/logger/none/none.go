@@ -0,0 +1,43 @@
+// Package none provides a logging driver that discards everything the
+// container writes.
+package none
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+const Name = "none"
+
+type Discard struct{}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		panic(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		panic(err)
+	}
+}
+
+// ValidateLogOpt rejects any option, the none driver takes none
+func ValidateLogOpt(cfg map[string]string) error {
+	for key := range cfg {
+		return fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+	}
+	return nil
+}
+
+func New(ctx logger.Context) (logger.Logger, error) {
+	if err := ValidateLogOpt(ctx.Config); err != nil {
+		return nil, err
+	}
+	return &Discard{}, nil
+}
+
+func (*Discard) Log(*logger.Message) error { return nil }
+
+func (*Discard) Close() error { return nil }
+
+func (*Discard) Name() string { return Name }
@@ -0,0 +1,156 @@
+// Package syslog provides the syslog logging driver. Messages are formatted
+// as RFC5424 and sent over a unix or UDP socket.
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+const (
+	Name = "syslog"
+
+	defaultAddress = "unix:///dev/log"
+
+	// facility daemon, RFC5424 section 6.2.1
+	facilityDaemon = 3
+	severityErr    = 3
+	severityInfo   = 6
+
+	// RFC5424 section 6.1, the nil value for header fields
+	nilValue = "-"
+)
+
+type Syslog struct {
+	mu       sync.Mutex
+	conn     net.Conn
+	hostname string
+	tag      string
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		panic(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		panic(err)
+	}
+}
+
+// parseLogOpt returns the network and address to dial from the
+// syslog-address option, which defaults to the local /dev/log socket.
+func parseLogOpt(cfg map[string]string) (string, string, error) {
+	address := defaultAddress
+	for key, value := range cfg {
+		switch key {
+		case "syslog-address":
+			address = value
+		default:
+			return "", "", fmt.Errorf("unknown log opt '%s' for %s log driver", key, Name)
+		}
+	}
+	return parseAddress(address)
+}
+
+// ValidateLogOpt checks the syslog-address option without dialing it
+func ValidateLogOpt(cfg map[string]string) error {
+	_, _, err := parseLogOpt(cfg)
+	return err
+}
+
+// New connects to the syslog daemon at the syslog-address option, which
+// defaults to the local /dev/log socket.
+func New(ctx logger.Context) (logger.Logger, error) {
+	proto, addr, err := parseLogOpt(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dial(proto, addr)
+	if err != nil {
+		return nil, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = nilValue
+	}
+	tag := ctx.ContainerID
+	if len(tag) > 12 {
+		tag = tag[:12]
+	}
+	return &Syslog{
+		conn:     conn,
+		hostname: hostname,
+		tag:      "docker/" + tag,
+	}, nil
+}
+
+// Log sends the message as a single RFC5424 datagram; stderr is logged with
+// severity err and stdout with severity info.
+func (s *Syslog) Log(msg *logger.Message) error {
+	severity := severityInfo
+	if msg.Source == "stderr" {
+		severity = severityErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.conn.Write(format(severity, msg.Timestamp, s.hostname, s.tag, msg.Line))
+	return err
+}
+
+func (s *Syslog) Close() error {
+	return s.conn.Close()
+}
+
+func (s *Syslog) Name() string {
+	return Name
+}
+
+// format builds an RFC5424 message:
+// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func format(severity int, t time.Time, hostname, tag string, line []byte) []byte {
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s",
+		facilityDaemon*8+severity,
+		t.UTC().Format(time.RFC3339Nano),
+		hostname, tag, nilValue, nilValue, nilValue, line))
+}
+
+// parseAddress splits a syslog-address of the form unix:///path or
+// udp://host:port into the network and address to dial
+func parseAddress(address string) (string, string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", "", err
+	}
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return "", "", fmt.Errorf("syslog-address %s has no socket path", address)
+		}
+		return u.Scheme, u.Path, nil
+	case "udp":
+		if _, _, err := net.SplitHostPort(u.Host); err != nil {
+			return u.Scheme, net.JoinHostPort(u.Host, "514"), nil
+		}
+		return u.Scheme, u.Host, nil
+	}
+	return "", "", fmt.Errorf("unsupported syslog-address scheme %s, only unix and udp are supported", u.Scheme)
+}
+
+func dial(proto, addr string) (net.Conn, error) {
+	if proto != "unix" {
+		return net.Dial(proto, addr)
+	}
+	// /dev/log is a datagram socket on most systems, fall back to a
+	// stream socket for the others
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		conn, err = net.Dial("unix", addr)
+	}
+	return conn, err
+}
@@ -0,0 +1,49 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	ts := time.Date(2014, 10, 17, 19, 21, 51, 254052384, time.UTC)
+	expected := "<30>1 2014-10-17T19:21:51.254052384Z myhost docker/a7317399f3f8 - - - hello world"
+	if res := string(format(severityInfo, ts, "myhost", "docker/a7317399f3f8", []byte("hello world"))); res != expected {
+		t.Fatalf("Wrong message: %q, expected %q", res, expected)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	for address, expected := range map[string][2]string{
+		"unix:///dev/log":        {"unix", "/dev/log"},
+		"udp://10.0.0.1":         {"udp", "10.0.0.1:514"},
+		"udp://logs.local:10514": {"udp", "logs.local:10514"},
+	} {
+		proto, addr, err := parseAddress(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if proto != expected[0] || addr != expected[1] {
+			t.Fatalf("%s: expected %v, got %s %s", address, expected, proto, addr)
+		}
+	}
+	for _, address := range []string{"tcp://10.0.0.1:514", "unix://", "/dev/log"} {
+		if _, _, err := parseAddress(address); err == nil {
+			t.Fatalf("Expected an error for %s", address)
+		}
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"tag": "web"},
+		{"syslog-address": "tcp://10.0.0.1:514"},
+	} {
+		if err := ValidateLogOpt(cfg); err == nil {
+			t.Fatalf("Expected an error for %v", cfg)
+		}
+	}
+	if err := ValidateLogOpt(map[string]string{"syslog-address": "udp://10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/pkg/jsonlog"
 	"github.com/docker/docker/pkg/log"
@@ -43,7 +45,7 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 	if container == nil {
 		return job.Errorf("No such container: %s", name)
 	}
-	cLog, err := container.ReadLog("json")
+	cLogs, err := container.readLogs()
 	if err != nil && os.IsNotExist(err) {
 		// Legacy logs
 		log.Debugf("Old logs format")
@@ -63,9 +65,19 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 				log.Errorf("Error streaming logs (stderr): %s", err)
 			}
 		}
+	} else if err == logger.ErrReadLogsNotSupported {
+		// the output can still be followed from the container's streams
+		if !follow {
+			return job.Errorf("\"logs\" command is supported only for \"%s\" logging driver", jsonfilelog.Name)
+		}
 	} else if err != nil {
 		log.Errorf("Error reading logs (json): %s", err)
 	} else {
+		defer func() {
+			for _, f := range cLogs {
+				f.Close()
+			}
+		}()
 		if tail != "all" {
 			var err error
 			lines, err = strconv.Atoi(tail)
@@ -75,9 +87,9 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 			}
 		}
 		if lines != 0 {
+			var cLog io.Reader
 			if lines > 0 {
-				f := cLog.(*os.File)
-				ls, err := tailfile.TailFile(f, lines)
+				ls, err := tailFiles(cLogs, lines)
 				if err != nil {
 					return job.Error(err)
 				}
@@ -86,6 +98,12 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 					fmt.Fprintf(tmp, "%s\n", l)
 				}
 				cLog = tmp
+			} else {
+				readers := make([]io.Reader, len(cLogs))
+				for i, f := range cLogs {
+					readers[i] = f
+				}
+				cLog = io.MultiReader(readers...)
 			}
 			dec := json.NewDecoder(cLog)
 			l := &jsonlog.JSONLog{}
@@ -133,3 +151,17 @@ func (daemon *Daemon) ContainerLogs(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// tailFiles returns the last n lines of files, which are ordered oldest
+// first, reading back into older files as long as more lines are needed.
+func tailFiles(files []*os.File, n int) ([][]byte, error) {
+	var lines [][]byte
+	for i := len(files) - 1; i >= 0 && len(lines) < n; i-- {
+		ls, err := tailfile.TailFile(files[i], n-len(lines))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(ls, lines...)
+	}
+	return lines, nil
+}
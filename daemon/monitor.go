@@ -122,7 +122,7 @@ func (m *containerMonitor) Start() error {
 	for {
 		m.container.RestartCount++
 
-		if err := m.container.startLoggingToDisk(); err != nil {
+		if err := m.container.startLogging(); err != nil {
 			m.resetContainer(false)
 
 			return err
@@ -149,6 +149,10 @@ func (m *containerMonitor) Start() error {
 		// here container.Lock is already lost
 		afterRun = true
 
+		m.container.Lock()
+		m.container.stopHealthMonitor()
+		m.container.Unlock()
+
 		m.resetMonitor(err == nil && exitStatus == 0)
 
 		if m.shouldRestart(exitStatus) {
@@ -243,7 +247,17 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 		}
 	}
 
+	// Container.Start holds the lock until the first run started, the
+	// restarts by the restart policy must take it
+	restarted := m.container.RestartCount > 0
+	if restarted {
+		m.container.Lock()
+	}
 	m.container.setRunning(pid)
+	m.container.initHealthMonitor()
+	if restarted {
+		m.container.Unlock()
+	}
 
 	// signal that the process has started
 	// close channel only if not closed
@@ -282,6 +296,8 @@ func (m *containerMonitor) resetContainer(lock bool) {
 		log.Errorf("%s: Error close stderr: %s", container.ID, err)
 	}
 
+	container.stopLogging()
+
 	if container.command != nil && container.command.ProcessConfig.Terminal != nil {
 		if err := container.command.ProcessConfig.Terminal.Close(); err != nil {
 			log.Errorf("%s: Error closing terminal: %s", container.ID, err)
@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/docker/utils"
+)
+
+// NetworkConnect attaches a container to a user-defined network. The
+// container uses the network from its next start on, so it cannot be
+// running.
+func (daemon *Daemon) NetworkConnect(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NETWORK CONTAINER", job.Name)
+	}
+	network := daemon.networks.Get(job.Args[0])
+	if network == nil {
+		return job.Errorf("No such network: %s", job.Args[0])
+	}
+	container := daemon.Get(job.Args[1])
+	if container == nil {
+		return job.Errorf("No such container: %s", job.Args[1])
+	}
+	if container.IsRunning() {
+		return job.Errorf("Conflict, cannot connect running container %s, stop it first", job.Args[1])
+	}
+
+	container.hostConfig.NetworkMode = runconfig.NetworkMode(network.Name)
+	if err := container.WriteHostConfig(); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// NetworkDisconnect detaches a container from a user-defined network,
+// moving it back to the default bridge.
+func (daemon *Daemon) NetworkDisconnect(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s NETWORK CONTAINER", job.Name)
+	}
+	network := daemon.networks.Get(job.Args[0])
+	if network == nil {
+		return job.Errorf("No such network: %s", job.Args[0])
+	}
+	container := daemon.Get(job.Args[1])
+	if container == nil {
+		return job.Errorf("No such container: %s", job.Args[1])
+	}
+	if daemon.networks.Get(string(container.hostConfig.NetworkMode)) != network {
+		return job.Errorf("Container %s is not connected to network %s", job.Args[1], job.Args[0])
+	}
+	if container.IsRunning() {
+		return job.Errorf("Conflict, cannot disconnect running container %s, stop it first", job.Args[1])
+	}
+
+	container.hostConfig.NetworkMode = runconfig.NetworkMode("bridge")
+	if err := container.WriteHostConfig(); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// NetworkRm removes a user-defined network. The network is kept as long as a
+// container is configured to use it, whether it was ever started or not.
+func (daemon *Daemon) NetworkRm(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s NETWORK", job.Name)
+	}
+	network := daemon.networks.Get(job.Args[0])
+	if network == nil {
+		return job.Errorf("No such network: %s", job.Args[0])
+	}
+	for _, container := range daemon.List() {
+		if daemon.networks.Get(string(container.hostConfig.NetworkMode)) == network {
+			return job.Errorf("Conflict, cannot remove network %s because the container %s is using it", job.Args[0], utils.TruncateID(container.ID))
+		}
+	}
+	if err := daemon.networks.Delete(network.ID); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
@@ -9,13 +9,16 @@ import (
 type PortMapping map[string]string // Deprecated
 
 type NetworkSettings struct {
-	IPAddress   string
-	IPPrefixLen int
-	MacAddress  string
-	Gateway     string
-	Bridge      string
-	PortMapping map[string]PortMapping // Deprecated
-	Ports       nat.PortMap
+	IPAddress           string
+	IPPrefixLen         int
+	MacAddress          string
+	Gateway             string
+	GlobalIPv6Address   string
+	GlobalIPv6PrefixLen int
+	IPv6Gateway         string
+	Bridge              string
+	PortMapping         map[string]PortMapping // Deprecated
+	Ports               nat.PortMap
 }
 
 func (settings *NetworkSettings) PortMappingAPI() *engine.Table {
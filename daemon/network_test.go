@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/daemon/networkdriver"
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/networks"
+	"github.com/docker/docker/runconfig"
+)
+
+// stubNetworkDriver sets up networks without touching the host.
+type stubNetworkDriver struct{}
+
+func (stubNetworkDriver) CreateNetwork(id, subnet string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR("10.20.0.1/24")
+	return network, err
+}
+
+func (stubNetworkDriver) DeleteNetwork(id string) error { return nil }
+
+func (stubNetworkDriver) Join(id, containerID string, requestedIP net.IP, requestedMac string) (*networkdriver.Interface, error) {
+	return &networkdriver.Interface{}, nil
+}
+
+func (stubNetworkDriver) Leave(id, containerID string) error { return nil }
+
+func init() {
+	networkdriver.Register("stub", stubNetworkDriver{})
+}
+
+func TestNetworkRmInUse(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-daemon-networks-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	repo, err := networks.NewRepository(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create("staging", "stub", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the container was never started, so it hasn't joined the network
+	container := &Container{
+		ID:         "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657",
+		hostConfig: &runconfig.HostConfig{NetworkMode: "staging"},
+	}
+	daemon := &Daemon{networks: repo, containers: &contStore{s: map[string]*Container{container.ID: container}}}
+	eng := engine.New()
+	eng.Logging = false
+	if err := eng.Register("network_rm", daemon.NetworkRm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := eng.Job("network_rm", "staging").Run(); err == nil {
+		t.Fatal("Expected an error removing a network a container is configured to use")
+	}
+	if repo.Get("staging") == nil {
+		t.Fatal("Expected the network to be kept")
+	}
+
+	container.hostConfig.NetworkMode = "bridge"
+	if err := eng.Job("network_rm", "staging").Run(); err != nil {
+		t.Fatal(err)
+	}
+	if repo.Get("staging") != nil {
+		t.Fatal("Expected the network to be removed")
+	}
+}
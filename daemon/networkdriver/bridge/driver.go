@@ -6,6 +6,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"syscall"
 
 	"github.com/docker/docker/daemon/networkdriver"
 	"github.com/docker/docker/daemon/networkdriver/ipallocator"
@@ -15,6 +16,7 @@ import (
 	"github.com/docker/docker/pkg/iptables"
 	"github.com/docker/docker/pkg/log"
 	"github.com/docker/docker/pkg/networkfs/resolvconf"
+	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/parsers/kernel"
 	"github.com/docker/libcontainer/netlink"
 )
@@ -27,7 +29,9 @@ const (
 // Network interface represents the networking stack of a container
 type networkInterface struct {
 	IP           net.IP
+	IPv6         net.IP     // the global IPv6 address, nil without --fixed-cidr-v6
 	PortMappings []net.Addr // there are mappings to the host interfaces
+	network      *net.IPNet // the network the IP was allocated from
 }
 
 type ifaces struct {
@@ -72,6 +76,14 @@ var (
 	bridgeIface   string
 	bridgeNetwork *net.IPNet
 
+	// the global IPv6 network the containers get their addresses from, and
+	// the address of the bridge in it
+	bridgeIPv6Network *net.IPNet
+	bridgeIPv6Gateway net.IP
+
+	// the link-local address the bridge gets with --ipv6
+	bridgeIPv6LinkLocal = &net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)}
+
 	defaultBindingIP  = net.ParseIP("0.0.0.0")
 	currentInterfaces = ifaces{c: make(map[string]*networkInterface)}
 )
@@ -85,6 +97,9 @@ func InitDriver(job *engine.Job) engine.Status {
 		ipForward      = job.GetenvBool("EnableIpForward")
 		bridgeIP       = job.Getenv("BridgeIP")
 		fixedCIDR      = job.Getenv("FixedCIDR")
+		enableIPv6     = job.GetenvBool("EnableIPv6")
+		fixedCIDRv6    = job.Getenv("FixedCIDRv6")
+		portRange      = job.Getenv("PortRange")
 	)
 
 	if defaultIP := job.Getenv("DefaultBindingIP"); defaultIP != "" {
@@ -128,9 +143,15 @@ func InitDriver(job *engine.Job) engine.Status {
 		}
 	}
 
+	if enableIPv6 {
+		if err := setupIPv6Bridge(bridgeIface, fixedCIDRv6, ipForward); err != nil {
+			return job.Error(err)
+		}
+	}
+
 	// Configure iptables for link support
 	if enableIPTables {
-		if err := setupIPTables(addr, icc, ipMasq); err != nil {
+		if err := setupIPTables(bridgeIface, addr, icc, ipMasq); err != nil {
 			return job.Error(err)
 		}
 	}
@@ -155,6 +176,10 @@ func InitDriver(job *engine.Job) engine.Status {
 		portmapper.SetIptablesChain(chain)
 	}
 
+	if err := setupPortRange(portRange); err != nil {
+		return job.Error(err)
+	}
+
 	bridgeNetwork = network
 	if fixedCIDR != "" {
 		_, subnet, err := net.ParseCIDR(fixedCIDR)
@@ -170,6 +195,8 @@ func InitDriver(job *engine.Job) engine.Status {
 	// https://github.com/docker/docker/issues/2768
 	job.Eng.Hack_SetGlobalVar("httpapi.bridgeIP", bridgeNetwork.IP)
 
+	userNetworks.configure(enableIPTables, icc, ipMasq)
+
 	for name, f := range map[string]engine.Handler{
 		"allocate_interface": Allocate,
 		"release_interface":  Release,
@@ -183,7 +210,91 @@ func InitDriver(job *engine.Job) engine.Status {
 	return engine.StatusOK
 }
 
-func setupIPTables(addr net.Addr, icc, ipmasq bool) error {
+// setupPortRange sets the range of the host ports of dynamically published
+// ports to portRange, or to the kernel's ephemeral port range without it.
+func setupPortRange(portRange string) error {
+	if portRange == "" {
+		begin, end, err := portallocator.SystemPortRange()
+		if err != nil {
+			log.Debugf("Using the default port range: %s", err)
+			return nil
+		}
+		return portallocator.SetPortRange(begin, end)
+	}
+
+	begin, end, err := parsers.ParsePortRange(portRange)
+	if err != nil {
+		return fmt.Errorf("Invalid --port-range %s: %s", portRange, err)
+	}
+	return portallocator.SetPortRange(int(begin), int(end))
+}
+
+// setupIPv6Bridge enables IPv6 on the bridge and gives it a link-local
+// address. With fixedCIDRv6 the bridge also gets the first address of that
+// network, which is the gateway of the containers, and the containers get
+// their global addresses from it.
+func setupIPv6Bridge(name, fixedCIDRv6 string, ipForward bool) error {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return err
+	}
+
+	// the kernel may have disabled IPv6 on the bridge, like on bridges
+	// without any address when the module was loaded with disable_ipv6
+	if err := ioutil.WriteFile("/proc/sys/net/ipv6/conf/"+name+"/disable_ipv6", []byte{'0', '\n'}, 0644); err != nil {
+		return fmt.Errorf("Unable to enable IPv6 on %s: %s", name, err)
+	}
+
+	if err := addBridgeIP(iface, bridgeIPv6LinkLocal.IP, bridgeIPv6LinkLocal); err != nil {
+		return err
+	}
+
+	if fixedCIDRv6 == "" {
+		return nil
+	}
+
+	_, subnet, err := net.ParseCIDR(fixedCIDRv6)
+	if err != nil {
+		return err
+	}
+	if subnet.IP.To4() != nil {
+		return fmt.Errorf("--fixed-cidr-v6 must be an IPv6 network, got %s", fixedCIDRv6)
+	}
+	log.Debugf("Subnet IPv6: %v", subnet)
+
+	// the gateway is the first address of the network, the allocator never
+	// hands it out to a container
+	gateway := make(net.IP, net.IPv6len)
+	copy(gateway, subnet.IP)
+	gateway[net.IPv6len-1] |= 1
+	if err := addBridgeIP(iface, gateway, subnet); err != nil {
+		return err
+	}
+
+	if ipForward {
+		// Enable IPv6 forwarding
+		if err := ioutil.WriteFile("/proc/sys/net/ipv6/conf/default/forwarding", []byte{'1', '\n'}, 0644); err != nil {
+			log.Infof("WARNING: unable to enable IPv6 default forwarding: %s", err)
+		}
+		if err := ioutil.WriteFile("/proc/sys/net/ipv6/conf/all/forwarding", []byte{'1', '\n'}, 0644); err != nil {
+			log.Infof("WARNING: unable to enable IPv6 forwarding: %s", err)
+		}
+	}
+
+	bridgeIPv6Network = subnet
+	bridgeIPv6Gateway = gateway
+	return nil
+}
+
+// addBridgeIP adds ip to the bridge iface unless it already has it
+func addBridgeIP(iface *net.Interface, ip net.IP, ipNet *net.IPNet) error {
+	if err := netlink.NetworkLinkAddIp(iface, ip, ipNet); err != nil && err != syscall.EEXIST {
+		return fmt.Errorf("Unable to add %s to %s: %s", ip, iface.Name, err)
+	}
+	return nil
+}
+
+func setupIPTables(bridgeIface string, addr net.Addr, icc, ipmasq bool) error {
 	// Enable NAT
 
 	if ipmasq {
@@ -273,32 +384,45 @@ func createBridge(bridgeIP string) error {
 		}
 		ifaceAddr = bridgeIP
 	} else {
-		for _, addr := range addrs {
-			_, dockerNetwork, err := net.ParseCIDR(addr)
-			if err != nil {
-				return err
-			}
-			if err := networkdriver.CheckNameserverOverlaps(nameservers, dockerNetwork); err == nil {
-				if err := networkdriver.CheckRouteOverlaps(dockerNetwork); err == nil {
-					ifaceAddr = addr
-					break
-				} else {
-					log.Debugf("%s %s", addr, err)
-				}
-			}
-		}
+		ifaceAddr = findFreeAddr(nameservers)
 	}
 
 	if ifaceAddr == "" {
 		return fmt.Errorf("Could not find a free IP address range for interface '%s'. Please configure its address manually and run 'docker -b %s'", bridgeIface, bridgeIface)
 	}
-	log.Debugf("Creating bridge %s with network %s", bridgeIface, ifaceAddr)
+	return setupBridge(bridgeIface, ifaceAddr)
+}
+
+// findFreeAddr returns the first of the candidate bridge addresses whose
+// network overlaps neither the nameservers nor the host routes, or an
+// empty string if they all do.
+func findFreeAddr(nameservers []string) string {
+	for _, addr := range addrs {
+		_, dockerNetwork, err := net.ParseCIDR(addr)
+		if err != nil {
+			continue
+		}
+		if err := networkdriver.CheckNameserverOverlaps(nameservers, dockerNetwork); err == nil {
+			if err := networkdriver.CheckRouteOverlaps(dockerNetwork); err == nil {
+				return addr
+			} else {
+				log.Debugf("%s %s", addr, err)
+			}
+		}
+	}
+	return ""
+}
 
-	if err := createBridgeIface(bridgeIface); err != nil {
+// setupBridge creates the bridge interface name, gives it the address
+// ifaceAddr and brings it up.
+func setupBridge(name, ifaceAddr string) error {
+	log.Debugf("Creating bridge %s with network %s", name, ifaceAddr)
+
+	if err := createBridgeIface(name); err != nil {
 		return err
 	}
 
-	iface, err := net.InterfaceByName(bridgeIface)
+	iface, err := net.InterfaceByName(name)
 	if err != nil {
 		return err
 	}
@@ -354,11 +478,13 @@ func generateMacAddr(ip net.IP) net.HardwareAddr {
 // Allocate a network interface
 func Allocate(job *engine.Job) engine.Status {
 	var (
-		ip          net.IP
-		mac         net.HardwareAddr
-		err         error
-		id          = job.Args[0]
-		requestedIP = net.ParseIP(job.Getenv("RequestedIP"))
+		ip            net.IP
+		ipv6          net.IP
+		mac           net.HardwareAddr
+		err           error
+		id            = job.Args[0]
+		requestedIP   = net.ParseIP(job.Getenv("RequestedIP"))
+		requestedIPv6 = net.ParseIP(job.Getenv("RequestedIPv6"))
 	)
 
 	if requestedIP != nil {
@@ -370,6 +496,18 @@ func Allocate(job *engine.Job) engine.Status {
 		return job.Error(err)
 	}
 
+	if bridgeIPv6Network != nil {
+		// a requested address outside of the network, like one from before
+		// --fixed-cidr-v6 changed, is replaced by a new one
+		if requestedIPv6 != nil && !bridgeIPv6Network.Contains(requestedIPv6) {
+			requestedIPv6 = nil
+		}
+		if ipv6, err = ipallocator.RequestIP(bridgeIPv6Network, requestedIPv6); err != nil {
+			ipallocator.ReleaseIP(bridgeNetwork, ip)
+			return job.Error(err)
+		}
+	}
+
 	// If no explicit mac address was given, generate a random one.
 	if mac, err = net.ParseMAC(job.Getenv("RequestedMac")); err != nil {
 		mac = generateMacAddr(ip)
@@ -385,8 +523,17 @@ func Allocate(job *engine.Job) engine.Status {
 	size, _ := bridgeNetwork.Mask.Size()
 	out.SetInt("IPPrefixLen", size)
 
+	if ipv6 != nil {
+		out.Set("GlobalIPv6", ipv6.String())
+		sizev6, _ := bridgeIPv6Network.Mask.Size()
+		out.SetInt("GlobalIPv6PrefixLen", sizev6)
+		out.Set("IPv6Gateway", bridgeIPv6Gateway.String())
+	}
+
 	currentInterfaces.Set(id, &networkInterface{
-		IP: ip,
+		IP:      ip,
+		IPv6:    ipv6,
+		network: bridgeNetwork,
 	})
 
 	out.WriteTo(job.Stdout)
@@ -396,13 +543,18 @@ func Allocate(job *engine.Job) engine.Status {
 
 // release an interface for a select ip
 func Release(job *engine.Job) engine.Status {
-	var (
-		id                 = job.Args[0]
-		containerInterface = currentInterfaces.Get(id)
-	)
+	if err := releaseInterface(job.Args[0]); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
 
+// releaseInterface unmaps the ports of the container id and releases its
+// IP back to the network it was allocated from.
+func releaseInterface(id string) error {
+	containerInterface := currentInterfaces.Get(id)
 	if containerInterface == nil {
-		return job.Errorf("No network information to release for %s", id)
+		return fmt.Errorf("No network information to release for %s", id)
 	}
 
 	for _, nat := range containerInterface.PortMappings {
@@ -411,10 +563,15 @@ func Release(job *engine.Job) engine.Status {
 		}
 	}
 
-	if err := ipallocator.ReleaseIP(bridgeNetwork, containerInterface.IP); err != nil {
+	if err := ipallocator.ReleaseIP(containerInterface.network, containerInterface.IP); err != nil {
 		log.Infof("Unable to release ip %s", err)
 	}
-	return engine.StatusOK
+	if containerInterface.IPv6 != nil {
+		if err := ipallocator.ReleaseIP(bridgeIPv6Network, containerInterface.IPv6); err != nil {
+			log.Infof("Unable to release ipv6 %s", err)
+		}
+	}
+	return nil
 }
 
 // Allocate an external port and map it to the interface
@@ -431,6 +588,10 @@ func AllocatePort(job *engine.Job) engine.Status {
 		network       = currentInterfaces.Get(id)
 	)
 
+	if network == nil {
+		return job.Errorf("No network information for %s", id)
+	}
+
 	if hostIP != "" {
 		ip = net.ParseIP(hostIP)
 		if ip == nil {
@@ -438,13 +599,20 @@ func AllocatePort(job *engine.Job) engine.Status {
 		}
 	}
 
+	// ports published on an IPv6 host address go to the IPv6 address of the
+	// container when it has one
+	containerIP := network.IP
+	if ip.To4() == nil && network.IPv6 != nil {
+		containerIP = network.IPv6
+	}
+
 	// host ip, proto, and host port
 	var container net.Addr
 	switch proto {
 	case "tcp":
-		container = &net.TCPAddr{IP: network.IP, Port: containerPort}
+		container = &net.TCPAddr{IP: containerIP, Port: containerPort}
 	case "udp":
-		container = &net.UDPAddr{IP: network.IP, Port: containerPort}
+		container = &net.UDPAddr{IP: containerIP, Port: containerPort}
 	default:
 		return job.Errorf("unsupported address type %s", proto)
 	}
@@ -118,3 +118,16 @@ func TestMacAddrGeneration(t *testing.T) {
 		t.Fatal("Non-unique MAC address")
 	}
 }
+
+func TestGatewayAddr(t *testing.T) {
+	addr, err := gatewayAddr("10.123.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != "10.123.0.1/16" {
+		t.Fatalf("Expected gateway 10.123.0.1/16, got %s", addr)
+	}
+	if _, err := gatewayAddr("10.123.0.0"); err == nil {
+		t.Fatal("Expected an error for a subnet without a mask")
+	}
+}
@@ -0,0 +1,225 @@
+package bridge
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/docker/docker/daemon/networkdriver"
+	"github.com/docker/docker/daemon/networkdriver/ipallocator"
+	"github.com/docker/docker/pkg/iptables"
+	"github.com/docker/docker/pkg/log"
+	"github.com/docker/libcontainer/netlink"
+)
+
+// userNetwork is a network created through the network driver interface.
+// Each one has its own bridge interface and subnet.
+type userNetwork struct {
+	iface   string
+	network *net.IPNet
+}
+
+// driver implements networkdriver.Driver on top of bridges isolated from
+// each other and from the default bridge.
+type driver struct {
+	sync.Mutex
+	networks       map[string]*userNetwork
+	initialized    bool
+	enableIPTables bool
+	icc            bool
+	ipMasq         bool
+}
+
+var userNetworks = &driver{networks: make(map[string]*userNetwork)}
+
+func init() {
+	networkdriver.Register("bridge", userNetworks)
+}
+
+func (d *driver) configure(enableIPTables, icc, ipMasq bool) {
+	d.Lock()
+	d.initialized = true
+	d.enableIPTables = enableIPTables
+	d.icc = icc
+	d.ipMasq = ipMasq
+	d.Unlock()
+}
+
+// bridgeName returns the name of the bridge interface of network id,
+// short enough for the kernel's limit on interface names.
+func bridgeName(id string) string {
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return "br-" + id
+}
+
+func (d *driver) CreateNetwork(id, subnet string) (*net.IPNet, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	if !d.initialized {
+		return nil, fmt.Errorf("Bridge networking is disabled")
+	}
+	if n, exists := d.networks[id]; exists {
+		return n.network, nil
+	}
+
+	iface := bridgeName(id)
+	addr, err := networkdriver.GetIfaceAddr(iface)
+	if err != nil {
+		// The bridge is not left over from a previous run of the daemon
+		ifaceAddr, err := gatewayAddr(subnet)
+		if err != nil {
+			return nil, err
+		}
+		if err := setupBridge(iface, ifaceAddr); err != nil {
+			return nil, err
+		}
+		if addr, err = networkdriver.GetIfaceAddr(iface); err != nil {
+			return nil, err
+		}
+	}
+	network := addr.(*net.IPNet)
+
+	if d.enableIPTables {
+		if err := setupIPTables(iface, network, d.icc, d.ipMasq); err != nil {
+			return nil, err
+		}
+		if err := d.isolate(iface, "-I"); err != nil {
+			return nil, err
+		}
+	}
+
+	d.networks[id] = &userNetwork{iface: iface, network: network}
+	return network, nil
+}
+
+// gatewayAddr returns the address to give to the bridge of a network on
+// subnet: its first IP. An empty subnet picks a free one.
+func gatewayAddr(subnet string) (string, error) {
+	if subnet == "" {
+		addr := findFreeAddr(nil)
+		if addr == "" {
+			return "", fmt.Errorf("Could not find a free IP address range for the network")
+		}
+		return addr, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return "", fmt.Errorf("Bad parameter: invalid subnet %s", subnet)
+	}
+	if err := networkdriver.CheckRouteOverlaps(ipNet); err != nil {
+		return "", fmt.Errorf("Conflict, subnet %s: %s", subnet, err)
+	}
+	gateway := make(net.IP, len(ipNet.IP))
+	copy(gateway, ipNet.IP)
+	gateway[len(gateway)-1]++
+	return (&net.IPNet{IP: gateway, Mask: ipNet.Mask}).String(), nil
+}
+
+// isolate adds (-I) or deletes (-D) the rules dropping the traffic between
+// iface and the other bridges, so that containers on different networks
+// cannot reach each other.
+func (d *driver) isolate(iface, action string) error {
+	others := []string{bridgeIface}
+	for _, n := range d.networks {
+		if n.iface != iface {
+			others = append(others, n.iface)
+		}
+	}
+	for _, other := range others {
+		for _, args := range [][]string{
+			{"FORWARD", "-i", iface, "-o", other, "-j", "DROP"},
+			{"FORWARD", "-i", other, "-o", iface, "-j", "DROP"},
+		} {
+			if action == "-I" && iptables.Exists(args...) {
+				continue
+			}
+			if output, err := iptables.Raw(append([]string{action}, args...)...); err != nil && action == "-I" {
+				return fmt.Errorf("Unable to isolate network bridge %s: %s", iface, err)
+			} else if len(output) != 0 && action == "-I" {
+				return fmt.Errorf("Error isolating network bridge %s: %s", iface, output)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *driver) DeleteNetwork(id string) error {
+	d.Lock()
+	defer d.Unlock()
+
+	n, exists := d.networks[id]
+	if !exists {
+		return fmt.Errorf("No such network: %s", id)
+	}
+
+	if d.enableIPTables {
+		d.isolate(n.iface, "-D")
+		for _, args := range [][]string{
+			{"POSTROUTING", "-t", "nat", "-s", n.network.String(), "!", "-o", n.iface, "-j", "MASQUERADE"},
+			{"FORWARD", "-i", n.iface, "-o", n.iface, "-j", "ACCEPT"},
+			{"FORWARD", "-i", n.iface, "-o", n.iface, "-j", "DROP"},
+			{"FORWARD", "-i", n.iface, "!", "-o", n.iface, "-j", "ACCEPT"},
+			{"FORWARD", "-o", n.iface, "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"},
+		} {
+			iptables.Raw(append([]string{"-D"}, args...)...)
+		}
+	}
+
+	if iface, err := net.InterfaceByName(n.iface); err == nil {
+		if err := netlink.NetworkLinkDown(iface); err != nil {
+			log.Infof("Unable to bring down network bridge %s: %s", n.iface, err)
+		}
+	}
+	if err := netlink.DeleteBridge(n.iface); err != nil {
+		return fmt.Errorf("Unable to delete network bridge %s: %s", n.iface, err)
+	}
+
+	delete(d.networks, id)
+	return nil
+}
+
+func (d *driver) Join(id, containerID string, requestedIP net.IP, requestedMac string) (*networkdriver.Interface, error) {
+	d.Lock()
+	n, exists := d.networks[id]
+	d.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("No such network: %s", id)
+	}
+
+	ip, err := ipallocator.RequestIP(n.network, requestedIP)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := net.ParseMAC(requestedMac)
+	if err != nil {
+		mac = generateMacAddr(ip)
+	}
+
+	currentInterfaces.Set(containerID, &networkInterface{
+		IP:      ip,
+		network: n.network,
+	})
+
+	size, _ := n.network.Mask.Size()
+	return &networkdriver.Interface{
+		IP:          ip,
+		IPPrefixLen: size,
+		Gateway:     n.network.IP,
+		MacAddress:  mac.String(),
+		Bridge:      n.iface,
+	}, nil
+}
+
+func (d *driver) Leave(id, containerID string) error {
+	d.Lock()
+	_, exists := d.networks[id]
+	d.Unlock()
+	if !exists {
+		return fmt.Errorf("No such network: %s", id)
+	}
+	return releaseInterface(containerID)
+}
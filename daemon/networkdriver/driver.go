@@ -0,0 +1,59 @@
+package networkdriver
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Driver is implemented by network drivers. A driver sets up networks on
+// the host, identified by ID, and the interfaces of the containers
+// attached to them.
+type Driver interface {
+	// CreateNetwork sets up the network id. An empty subnet lets the driver
+	// pick a free one. It returns the network address of the gateway, with
+	// the network mask. Creating a network which is already set up, e.g.
+	// when the daemon restarts, must succeed.
+	CreateNetwork(id, subnet string) (*net.IPNet, error)
+	DeleteNetwork(id string) error
+	// Join allocates an interface for the container on the network id,
+	// using the requested IP and MAC addresses when they are set.
+	Join(id, containerID string, requestedIP net.IP, requestedMac string) (*Interface, error)
+	Leave(id, containerID string) error
+}
+
+// Interface describes the network interface of a container joined to a
+// network.
+type Interface struct {
+	IP          net.IP
+	IPPrefixLen int
+	Gateway     net.IP
+	MacAddress  string
+	Bridge      string
+}
+
+var (
+	driversLock sync.Mutex
+	drivers     = make(map[string]Driver)
+)
+
+// Register makes a network driver available by name.
+func Register(name string, driver Driver) error {
+	driversLock.Lock()
+	defer driversLock.Unlock()
+	if _, exists := drivers[name]; exists {
+		return fmt.Errorf("Name already registered %s", name)
+	}
+	drivers[name] = driver
+	return nil
+}
+
+// GetDriver returns the network driver registered with the given name.
+func GetDriver(name string) (Driver, error) {
+	driversLock.Lock()
+	defer driversLock.Unlock()
+	if driver, exists := drivers[name]; exists {
+		return driver, nil
+	}
+	return nil, fmt.Errorf("No such network driver: %s", name)
+}
@@ -2,6 +2,7 @@ package ipallocator
 
 import (
 	"fmt"
+	"math/big"
 	"net"
 	"testing"
 )
@@ -29,7 +30,7 @@ func TestRequestNewIps(t *testing.T) {
 			t.Fatalf("Expected ip %s got %s", expected, ip.String())
 		}
 	}
-	value := intToIP(ipToInt(ip) + 1).String()
+	value := bigIntToIP(big.NewInt(0).Add(ipToBigInt(ip), big.NewInt(1)), 4).String()
 	if err := ReleaseIP(network, ip); err != nil {
 		t.Fatal(err)
 	}
@@ -122,18 +123,103 @@ func TestRequestSpecificIp(t *testing.T) {
 	}
 }
 
+func TestRequestNewIpV6(t *testing.T) {
+	defer reset()
+	network := &net.IPNet{
+		IP:   []byte{0x2a, 0x00, 0x14, 0x50, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		Mask: []byte{255, 255, 255, 255, 255, 255, 255, 255, 0, 0, 0, 0, 0, 0, 0, 0}, // /64
+	}
+
+	var ip net.IP
+	var err error
+	for i := 2; i < 10; i++ {
+		ip, err = RequestIP(network, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := fmt.Sprintf("2a00:1450::%d", i); ip.String() != expected {
+			t.Fatalf("Expected ip %s got %s", expected, ip.String())
+		}
+	}
+	value := bigIntToIP(big.NewInt(0).Add(ipToBigInt(ip), big.NewInt(1)), 16).String()
+	if err := ReleaseIP(network, ip); err != nil {
+		t.Fatal(err)
+	}
+	ip, err = RequestIP(network, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip.String() != value {
+		t.Fatalf("Expected to receive the next ip %s got %s", value, ip.String())
+	}
+
+	if _, err := RequestIP(network, net.ParseIP("2a00:1450::2")); err != ErrIPAlreadyAllocated {
+		t.Fatalf("Expected ErrIPAlreadyAllocated error, got %v", err)
+	}
+	if _, err := RequestIP(network, net.ParseIP("2a00:1451::2")); err != ErrIPOutOfRange {
+		t.Fatalf("Expected ErrIPOutOfRange error, got %v", err)
+	}
+}
+
+func TestAllocateFromRangeV6(t *testing.T) {
+	defer reset()
+	network := &net.IPNet{
+		IP:   net.ParseIP("2a00:1450::"),
+		Mask: net.CIDRMask(64, 128),
+	}
+	// 2a00:1450::1:1 - 2a00:1450::1:6
+	subnet := &net.IPNet{
+		IP:   net.ParseIP("2a00:1450::1:0"),
+		Mask: net.CIDRMask(125, 128),
+	}
+	if err := RegisterSubnet(network, subnet); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 6; i++ {
+		rip, err := RequestIP(network, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIPEquals(t, net.ParseIP(fmt.Sprintf("2a00:1450::1:%d", i)), rip)
+	}
+	if _, err := RequestIP(network, nil); err != ErrNoAvailableIPs {
+		t.Fatalf("Expected ErrNoAvailableIPs error, got %v", err)
+	}
+}
+
 func TestConversion(t *testing.T) {
 	ip := net.ParseIP("127.0.0.1")
-	i := ipToInt(ip)
-	if i == 0 {
-		t.Fatal("converted to zero")
+	i := ipToBigInt(ip)
+	if i.Cmp(big.NewInt(0x7f000001)) != 0 {
+		t.Fatalf("127.0.0.1 converted to %s", i)
 	}
-	conv := intToIP(i)
+	conv := bigIntToIP(i, 4)
 	if !ip.Equal(conv) {
 		t.Error(conv.String())
 	}
 }
 
+func TestConversionIPv6(t *testing.T) {
+	ip := net.ParseIP("2a00:1450::1")
+	ip2 := net.ParseIP("2a00:1450::2")
+	ip3 := net.ParseIP("2a00:1450::1:1")
+	i := ipToBigInt(ip)
+	val, success := big.NewInt(0).SetString("55827987809411540836515382960316219393", 10)
+	if !success || i.Cmp(val) != 0 {
+		t.Fatalf("2a00:1450::1 converted to %s", i)
+	}
+	if conv := bigIntToIP(i, 16); !ip.Equal(conv) {
+		t.Error(conv.String())
+	}
+	if conv := bigIntToIP(big.NewInt(0).Add(i, big.NewInt(1)), 16); !ip2.Equal(conv) {
+		t.Error(conv.String())
+	}
+	if conv := bigIntToIP(big.NewInt(0).Add(i, big.NewInt(65536)), 16); !ip3.Equal(conv) {
+		t.Error(conv.String())
+	}
+}
+
 func TestIPAllocator(t *testing.T) {
 	expectedIPs := []net.IP{
 		0: net.IPv4(127, 0, 0, 2),
@@ -237,15 +323,15 @@ func TestAllocateFirstIP(t *testing.T) {
 	}
 
 	firstIP := network.IP.To4().Mask(network.Mask)
-	first := ipToInt(firstIP) + 1
+	first := big.NewInt(0).Add(ipToBigInt(firstIP), big.NewInt(1))
 
 	ip, err := RequestIP(network, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	allocated := ipToInt(ip)
+	allocated := ipToBigInt(ip)
 
-	if allocated == first {
+	if allocated.Cmp(first) == 0 {
 		t.Fatalf("allocated ip should not equal first ip: %d == %d", first, allocated)
 	}
 }
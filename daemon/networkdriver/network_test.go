@@ -187,4 +187,14 @@ func TestNetworkRange(t *testing.T) {
 	if size := NetworkSize(network.Mask); size != 64 {
 		t.Error(size)
 	}
+
+	// IPv6 network
+	_, network, _ = net.ParseCIDR("2001:db8:1::1234/64")
+	first, last = NetworkRange(network)
+	if !first.Equal(net.ParseIP("2001:db8:1::")) {
+		t.Error(first.String())
+	}
+	if !last.Equal(net.ParseIP("2001:db8:1::ffff:ffff:ffff:ffff")) {
+		t.Error(last.String())
+	}
 }
@@ -3,6 +3,7 @@ package portallocator
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"sync"
 )
@@ -29,11 +30,15 @@ func newProtoMap() protoMap {
 
 type ipMapping map[string]protoMap
 
+// The default range of the ports handed out when no port is requested
 const (
 	BeginPortRange = 49153
 	EndPortRange   = 65535
 )
 
+// the file with the kernel's range of ephemeral ports
+const portRangeKernelParam = "/proc/sys/net/ipv4/ip_local_port_range"
+
 var (
 	ErrAllPortsAllocated = errors.New("all ports are allocated")
 	ErrUnknownProtocol   = errors.New("unknown protocol")
@@ -44,6 +49,9 @@ var (
 
 	defaultIP = net.ParseIP("0.0.0.0")
 	globalMap = ipMapping{}
+
+	beginPortRange = BeginPortRange
+	endPortRange   = EndPortRange
 )
 
 type ErrPortAlreadyAllocated struct {
@@ -110,6 +118,39 @@ func RequestPort(ip net.IP, proto string, port int) (int, error) {
 	return port, nil
 }
 
+// SetPortRange sets the range of the ports RequestPort hands out when no port
+// is requested.
+func SetPortRange(begin, end int) error {
+	if begin <= 0 || end > 65535 || begin > end {
+		return fmt.Errorf("Invalid port range %d-%d", begin, end)
+	}
+	mutex.Lock()
+	beginPortRange, endPortRange = begin, end
+	mutex.Unlock()
+	return nil
+}
+
+// PortRange returns the range of the ports RequestPort hands out when no port
+// is requested.
+func PortRange() (int, int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return beginPortRange, endPortRange
+}
+
+// SystemPortRange returns the kernel's range of ephemeral ports.
+func SystemPortRange() (int, int, error) {
+	data, err := ioutil.ReadFile(portRangeKernelParam)
+	if err != nil {
+		return 0, 0, err
+	}
+	var begin, end int
+	if n, err := fmt.Sscanf(string(data), "%d %d", &begin, &end); n != 2 || err != nil {
+		return 0, 0, fmt.Errorf("Invalid content of %s: %q", portRangeKernelParam, data)
+	}
+	return begin, end, nil
+}
+
 // ReleasePort releases port from global ports pool for specified ip and proto.
 func ReleasePort(ip net.IP, proto string, port int) error {
 	mutex.Lock()
@@ -135,15 +176,13 @@ func ReleaseAll() error {
 }
 
 func (pm *portMap) findPort() (int, error) {
-	if pm.last == 0 {
-		pm.p[BeginPortRange] = struct{}{}
-		pm.last = BeginPortRange
-		return BeginPortRange, nil
-	}
-
-	for port := pm.last + 1; port != pm.last; port++ {
-		if port > EndPortRange {
-			port = BeginPortRange
+	// the range may have changed since the last port was handed out, go
+	// around it once starting after the last port
+	port := pm.last
+	for i := 0; i <= endPortRange-beginPortRange; i++ {
+		port++
+		if port < beginPortRange || port > endPortRange {
+			port = beginPortRange
 		}
 
 		if _, ok := pm.p[port]; !ok {
@@ -214,3 +214,38 @@ func TestPortAllocation(t *testing.T) {
 		t.Fatal("Requesting a dynamic port should never allocate a used port")
 	}
 }
+
+func TestSetPortRange(t *testing.T) {
+	defer reset()
+	defer SetPortRange(BeginPortRange, EndPortRange)
+
+	for _, r := range [][2]int{{0, 100}, {100, 65536}, {200, 100}} {
+		if err := SetPortRange(r[0], r[1]); err == nil {
+			t.Fatalf("Expected an error setting the port range %d-%d", r[0], r[1])
+		}
+	}
+
+	// the dynamic ports already handed out are kept
+	if _, err := RequestPort(defaultIP, "tcp", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetPortRange(10000, 10002); err != nil {
+		t.Fatal(err)
+	}
+	if begin, end := PortRange(); begin != 10000 || end != 10002 {
+		t.Fatalf("Expected the port range 10000-10002, got %d-%d", begin, end)
+	}
+	for i := 10000; i <= 10002; i++ {
+		port, err := RequestPort(defaultIP, "tcp", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if port != i {
+			t.Fatalf("Expected port %d got %d", i, port)
+		}
+	}
+	if _, err := RequestPort(defaultIP, "tcp", 0); err != ErrAllPortsAllocated {
+		t.Fatalf("Expected error %s got %s", ErrAllPortsAllocated, err)
+	}
+}
@@ -169,7 +169,9 @@ func getIPAndPort(a net.Addr) (net.IP, int) {
 }
 
 func forward(action iptables.Action, proto string, sourceIP net.IP, sourcePort int, containerIP string, containerPort int) error {
-	if chain == nil {
+	// the DOCKER chain is an iptables chain, ports published on IPv6
+	// addresses are only forwarded by the userland proxy
+	if chain == nil || sourceIP.To4() == nil {
 		return nil
 	}
 	return chain.Forward(action, sourceIP, sourcePort, proto, containerIP, containerPort)
@@ -56,10 +56,13 @@ func NetworkOverlaps(netX *net.IPNet, netY *net.IPNet) bool {
 
 // Calculates the first and last IP addresses in an IPNet
 func NetworkRange(network *net.IPNet) (net.IP, net.IP) {
+	netIP := network.IP.To4()
+	if netIP == nil {
+		netIP = network.IP.To16()
+	}
 	var (
-		netIP   = network.IP.To4()
 		firstIP = netIP.Mask(network.Mask)
-		lastIP  = net.IPv4(0, 0, 0, 0).To4()
+		lastIP  = make(net.IP, len(firstIP))
 	)
 
 	for i := 0; i < len(lastIP); i++ {
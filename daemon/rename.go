@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"path"
+	"strings"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/log"
+	"github.com/docker/docker/pkg/networkfs/etchosts"
+	"github.com/docker/docker/utils"
+)
+
+func (daemon *Daemon) ContainerRename(job *engine.Job) engine.Status {
+	if len(job.Args) != 2 {
+		return job.Errorf("Usage: %s OLD_NAME NEW_NAME", job.Name)
+	}
+	oldName, newName := job.Args[0], job.Args[1]
+
+	container := daemon.Get(oldName)
+	if container == nil {
+		return job.Errorf("No such container: %s", oldName)
+	}
+
+	if !validContainerNamePattern.MatchString(newName) {
+		return job.Errorf("Invalid container name (%s), only %s are allowed", newName, validContainerNameChars)
+	}
+	newName, err := GetFullContainerName(newName)
+	if err != nil {
+		return job.Error(err)
+	}
+	oldName = container.Name
+	if oldName == newName {
+		return job.Errorf("Renaming a container with the same name as its current name")
+	}
+
+	if conflictingContainer, err := daemon.GetByName(newName); err == nil {
+		nameAsKnownByUser := strings.TrimPrefix(newName, "/")
+		return job.Errorf("Conflict, The name %s is already assigned to %s. You have to delete (or rename) that container to be able to assign %s to a container again.",
+			nameAsKnownByUser, utils.TruncateID(conflictingContainer.ID), nameAsKnownByUser)
+	}
+
+	if err := daemon.containerGraph.Rename(oldName, newName); err != nil {
+		return job.Errorf("Cannot rename container %s: %s", oldName, err)
+	}
+
+	container.Lock()
+	container.Name = newName
+	err = container.toDisk()
+	container.Unlock()
+	if err != nil {
+		// Undo the rename so the name in graphdb matches the one on disk
+		container.Lock()
+		container.Name = oldName
+		container.Unlock()
+		if err := daemon.containerGraph.Rename(newName, oldName); err != nil {
+			log.Errorf("Failed to restore name %s for %s: %s", oldName, container.ID, err)
+		}
+		return job.Errorf("Cannot rename container %s: %s", oldName, err)
+	}
+
+	if err := container.renameLinks(oldName[1:], newName[1:]); err != nil {
+		log.Errorf("Error renaming the links to %s: %s", oldName, err)
+	}
+
+	container.LogEvent("rename")
+	return engine.StatusOK
+}
+
+// renameLinks renames the links to this container that have its old name as
+// alias, in the links database and in the /etc/hosts file of the linking
+// containers. Links with another alias are left alone. A running linking
+// container keeps the environment variables of the old alias until it is
+// restarted.
+func (container *Container) renameLinks(oldName, newName string) error {
+	parents, err := container.daemon.Parents(container.Name)
+	if err != nil {
+		return err
+	}
+	for _, cid := range parents {
+		if cid == "0" {
+			continue
+		}
+
+		c := container.daemon.Get(cid)
+		if c == nil {
+			continue
+		}
+		oldLink, newLink := path.Join(c.Name, oldName), path.Join(c.Name, newName)
+		if e := container.daemon.containerGraph.Get(oldLink); e == nil || e.ID() != container.ID {
+			continue
+		}
+		if container.daemon.containerGraph.Exists(newLink) {
+			log.Debugf("Not renaming the link %s, %s already exists", oldLink, newLink)
+			continue
+		}
+		if err := container.daemon.containerGraph.Rename(oldLink, newLink); err != nil {
+			return err
+		}
+		if c.HostsPath == "" {
+			continue
+		}
+		if err := etchosts.Rename(c.HostsPath, oldName, newName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/stats"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/engine"
+	"github.com/docker/libcontainer/cgroups"
+)
+
+const (
+	statsInterval = 1 * time.Second
+	// USER_HZ, the unit of the counters in /proc/stat, is 100 on all
+	// architectures docker supports
+	clockTicksPerSecond  = 100
+	nanoSecondsPerSecond = 1e9
+)
+
+// ContainerStats streams a sample of the resource usage of a running
+// container to job.Stdout every statsInterval, until the container stops
+// or the client goes away.
+func (daemon *Daemon) ContainerStats(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("Usage: %s CONTAINER", job.Name)
+	}
+	name := job.Args[0]
+	container := daemon.Get(name)
+	if container == nil {
+		return job.Errorf("No such container: %s", name)
+	}
+	if !container.IsRunning() {
+		return job.Errorf("Container %s is not running", name)
+	}
+
+	enc := json.NewEncoder(job.Stdout)
+	for container.IsRunning() {
+		s, err := daemon.Stats(container)
+		if err != nil {
+			// the container may have exited between the check and the sample
+			if !container.IsRunning() {
+				break
+			}
+			return job.Error(err)
+		}
+		ss := convertStats(s)
+		ss.MemoryStats.Limit = uint64(container.Config.Memory)
+		if ss.CpuStats.SystemUsage, err = getSystemCpuUsage(); err != nil {
+			return job.Error(err)
+		}
+		if err := enc.Encode(ss); err != nil {
+			// the client closed the connection
+			return engine.StatusOK
+		}
+		time.Sleep(statsInterval)
+	}
+	return engine.StatusOK
+}
+
+// convertStats converts the driver's sample into the API's stats type.
+func convertStats(rs *execdriver.ResourceStats) *stats.Stats {
+	s := &stats.Stats{Read: rs.Read}
+	if ns := rs.NetworkStats; ns != nil {
+		s.Network = stats.Network{
+			RxBytes:   ns.RxBytes,
+			RxPackets: ns.RxPackets,
+			RxErrors:  ns.RxErrors,
+			RxDropped: ns.RxDropped,
+			TxBytes:   ns.TxBytes,
+			TxPackets: ns.TxPackets,
+			TxErrors:  ns.TxErrors,
+			TxDropped: ns.TxDropped,
+		}
+	}
+	cs := rs.CgroupStats
+	if cs == nil {
+		return s
+	}
+	cpu := cs.CpuStats
+	s.CpuStats = stats.CpuStats{
+		CpuUsage: stats.CpuUsage{
+			TotalUsage:        cpu.CpuUsage.TotalUsage,
+			PercpuUsage:       cpu.CpuUsage.PercpuUsage,
+			UsageInKernelmode: cpu.CpuUsage.UsageInKernelmode,
+			UsageInUsermode:   cpu.CpuUsage.UsageInUsermode,
+		},
+		ThrottlingData: stats.ThrottlingData{
+			Periods:          cpu.ThrottlingData.Periods,
+			ThrottledPeriods: cpu.ThrottlingData.ThrottledPeriods,
+			ThrottledTime:    cpu.ThrottlingData.ThrottledTime,
+		},
+	}
+	mem := cs.MemoryStats
+	s.MemoryStats = stats.MemoryStats{
+		Usage:    mem.Usage,
+		MaxUsage: mem.MaxUsage,
+		Stats:    mem.Stats,
+		Failcnt:  mem.Failcnt,
+	}
+	blkio := cs.BlkioStats
+	s.BlkioStats = stats.BlkioStats{
+		IoServiceBytesRecursive: copyBlkioEntry(blkio.IoServiceBytesRecursive),
+		IoServicedRecursive:     copyBlkioEntry(blkio.IoServicedRecursive),
+		IoQueuedRecursive:       copyBlkioEntry(blkio.IoQueuedRecursive),
+		SectorsRecursive:        copyBlkioEntry(blkio.SectorsRecursive),
+	}
+	return s
+}
+
+func copyBlkioEntry(entries []cgroups.BlkioStatEntry) []stats.BlkioStatEntry {
+	out := make([]stats.BlkioStatEntry, len(entries))
+	for i, re := range entries {
+		out[i] = stats.BlkioStatEntry{
+			Major: re.Major,
+			Minor: re.Minor,
+			Op:    re.Op,
+			Value: re.Value,
+		}
+	}
+	return out
+}
+
+// getSystemCpuUsage returns the host's cumulative CPU usage in nanoseconds,
+// as the sum of the cpu line of /proc/stat.
+func getSystemCpuUsage() (uint64, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		parts := strings.Fields(s.Text())
+		if len(parts) == 0 || parts[0] != "cpu" {
+			continue
+		}
+		if len(parts) < 8 {
+			return 0, fmt.Errorf("invalid number of cpu fields")
+		}
+		var totalClockTicks uint64
+		for _, i := range parts[1:8] {
+			v, err := strconv.ParseUint(i, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("Unable to convert value %s to int: %s", i, err)
+			}
+			totalClockTicks += v
+		}
+		return (totalClockTicks * nanoSecondsPerSecond) / clockTicksPerSecond, nil
+	}
+	if err := s.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("invalid stat format")
+}
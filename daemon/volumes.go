@@ -77,12 +77,55 @@ func (m *Mount) initialize() error {
 	m.volume.AddContainer(m.container.ID)
 	if m.Writable && !m.volume.IsBindMount {
 		// Copy whatever is in the container at the mntToPath to the volume
-		copyExistingContents(containerMntPath, m.volume.Path)
+		volumePath, err := m.volume.Mount()
+		if err != nil {
+			return err
+		}
+		copyExistingContents(containerMntPath, volumePath)
+		if err := m.volume.Unmount(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// mountVolumes asks the volume drivers to make the container's volumes
+// available on the host for as long as the container runs.
+func (container *Container) mountVolumes() error {
+	for path := range container.VolumePaths() {
+		vol := container.daemon.volumes.Get(path)
+		if vol == nil {
+			log.Debugf("Volume %s was not found and could not be mounted", path)
+			continue
+		}
+		volumePath, err := vol.Mount()
+		if err != nil {
+			return err
+		}
+		container.mountedVolumes = append(container.mountedVolumes, vol)
+		if volumePath == path {
+			continue
+		}
+		// the driver moved the volume since the container last used it
+		for mountToPath, p := range container.Volumes {
+			if p == path {
+				container.Volumes[mountToPath] = volumePath
+			}
+		}
+	}
+	return nil
+}
+
+func (container *Container) unmountVolumes() {
+	for _, vol := range container.mountedVolumes {
+		if err := vol.Unmount(); err != nil {
+			log.Errorf("%v: Failed to unmount volume %s: %v", container.ID, vol.DisplayName(), err)
+		}
+	}
+	container.mountedVolumes = nil
+}
+
 func (container *Container) VolumePaths() map[string]struct{} {
 	var paths = make(map[string]struct{})
 	for _, path := range container.Volumes {
@@ -110,8 +153,14 @@ func (container *Container) parseVolumeMountConfig() (map[string]*Mount, error)
 		if err != nil {
 			return nil, err
 		}
-		// Check if a volume already exists for this and use it
-		vol, err := container.daemon.volumes.FindOrCreateVolume(path, writable)
+		var vol *volumes.Volume
+		if filepath.IsAbs(path) {
+			// Check if a volume already exists for this and use it
+			vol, err = container.daemon.volumes.FindOrCreateVolume(path, "", writable)
+		} else {
+			// Not a host path, so this refers to a named volume
+			vol, err = container.daemon.volumes.FindOrCreateNamedVolume(path, container.hostConfig.VolumeDriver)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -125,7 +174,7 @@ func (container *Container) parseVolumeMountConfig() (map[string]*Mount, error)
 			continue
 		}
 
-		vol, err := container.daemon.volumes.FindOrCreateVolume("", true)
+		vol, err := container.daemon.volumes.FindOrCreateVolume("", container.hostConfig.VolumeDriver, true)
 		if err != nil {
 			return nil, err
 		}
@@ -155,10 +204,6 @@ func parseBindMountSpec(spec string) (string, string, bool, error) {
 		return "", "", false, fmt.Errorf("Invalid volume specification: %s", spec)
 	}
 
-	if !filepath.IsAbs(path) {
-		return "", "", false, fmt.Errorf("cannot bind mount volume: %s volume paths must be absolute.", path)
-	}
-
 	return path, mountToPath, writable, nil
 }
 
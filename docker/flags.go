@@ -57,7 +57,7 @@ func init() {
 			{"attach", "Attach to a running container"},
 			{"build", "Build an image from a Dockerfile"},
 			{"commit", "Create a new image from a container's changes"},
-			{"cp", "Copy files/folders from a container's filesystem to the host path"},
+			{"cp", "Copy files/folders between a container's filesystem and the host path"},
 			{"create", "Create a new container"},
 			{"diff", "Inspect changes on a container's filesystem"},
 			{"events", "Get real time events from the server"},
@@ -73,11 +73,13 @@ func init() {
 			{"login", "Register or log in to a Docker registry server"},
 			{"logout", "Log out from a Docker registry server"},
 			{"logs", "Fetch the logs of a container"},
+			{"network", "Manage Docker networks"},
 			{"port", "Lookup the public-facing port that is NAT-ed to PRIVATE_PORT"},
 			{"pause", "Pause all processes within a container"},
 			{"ps", "List containers"},
 			{"pull", "Pull an image or a repository from a Docker registry server"},
 			{"push", "Push an image or a repository to a Docker registry server"},
+			{"rename", "Rename an existing container"},
 			{"restart", "Restart a running container"},
 			{"rm", "Remove one or more containers"},
 			{"rmi", "Remove one or more images"},
@@ -85,11 +87,13 @@ func init() {
 			{"save", "Save an image to a tar archive"},
 			{"search", "Search for an image on the Docker Hub"},
 			{"start", "Start a stopped container"},
+			{"stats", "Display a live stream of container resource usage statistics"},
 			{"stop", "Stop a running container"},
 			{"tag", "Tag an image into a repository"},
 			{"top", "Lookup the running processes of a container"},
 			{"unpause", "Unpause a paused container"},
 			{"version", "Show the Docker version information"},
+			{"volume", "Manage Docker volumes"},
 			{"wait", "Block until a container stops, then print its exit code"},
 		} {
 			help += fmt.Sprintf("    %-10.10s%s\n", command[0], command[1])
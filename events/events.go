@@ -2,10 +2,12 @@ package events
 
 import (
 	"encoding/json"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/parsers/filters"
 	"github.com/docker/docker/utils"
 )
 
@@ -48,6 +50,11 @@ func (e *Events) Get(job *engine.Job) engine.Status {
 		timeout = time.NewTimer(time.Unix(until, 0).Sub(time.Now()))
 	)
 
+	eventFilters, err := filters.FromParam(job.Getenv("filters"))
+	if err != nil {
+		return job.Error(err)
+	}
+
 	// If no until, disable timeout
 	if until == 0 {
 		timeout.Stop()
@@ -61,7 +68,7 @@ func (e *Events) Get(job *engine.Job) engine.Status {
 
 	// Resend every event in the [since, until] time interval.
 	if since != 0 {
-		if err := e.writeCurrent(job, since, until); err != nil {
+		if err := e.writeCurrent(job, since, until, eventFilters); err != nil {
 			return job.Error(err)
 		}
 	}
@@ -72,6 +79,9 @@ func (e *Events) Get(job *engine.Job) engine.Status {
 			if !ok {
 				return engine.StatusOK
 			}
+			if !matchFilters(event, eventFilters) {
+				continue
+			}
 			if err := writeEvent(job, event); err != nil {
 				return job.Error(err)
 			}
@@ -82,11 +92,17 @@ func (e *Events) Get(job *engine.Job) engine.Status {
 }
 
 func (e *Events) Log(job *engine.Job) engine.Status {
-	if len(job.Args) != 3 {
-		return job.Errorf("usage: %s ACTION ID FROM", job.Name)
+	if len(job.Args) != 3 && len(job.Args) != 4 {
+		return job.Errorf("usage: %s ACTION ID FROM [NAME]", job.Name)
 	}
+	var name string
+	if len(job.Args) == 4 {
+		name = job.Args[3]
+	}
+	jm := &utils.JSONMessage{Status: job.Args[0], ID: job.Args[1], From: job.Args[2], Name: name}
+	job.GetenvJson("Labels", &jm.Labels)
 	// not waiting for receivers
-	go e.log(job.Args[0], job.Args[1], job.Args[2])
+	go e.logMessage(jm)
 	return engine.StatusOK
 }
 
@@ -113,10 +129,59 @@ func writeEvent(job *engine.Job, event *utils.JSONMessage) error {
 	return nil
 }
 
-func (e *Events) writeCurrent(job *engine.Job, since, until int64) error {
+// matchFilters reports whether the event passes the "event", "container",
+// "image" and "label" filters. Values of the same filter are ORed, different
+// filters are ANDed; label filters must all match the container's labels.
+func matchFilters(event *utils.JSONMessage, eventFilters filters.Args) bool {
+	if values, ok := eventFilters["event"]; ok && !matchAny(values, event.Status) {
+		return false
+	}
+	if values, ok := eventFilters["container"]; ok {
+		var found bool
+		for _, value := range values {
+			// match the full or truncated ID, or the container name
+			if (value != "" && strings.HasPrefix(event.ID, value)) || (event.Name != "" && value == event.Name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !eventFilters.MatchKVList("label", event.Labels) {
+		return false
+	}
+	if values, ok := eventFilters["image"]; ok {
+		// image events carry the image ID and no origin
+		if event.From == "" {
+			return matchAny(values, event.ID)
+		}
+		// an image filter without a tag matches every tag of the repository
+		from := event.From
+		if i := strings.LastIndex(from, ":"); i > strings.LastIndex(from, "/") {
+			from = from[:i]
+		}
+		if !matchAny(values, event.From) && !matchAny(values, from) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Events) writeCurrent(job *engine.Job, since, until int64, eventFilters filters.Args) error {
 	e.mu.RLock()
 	for _, event := range e.events {
-		if event.Time >= since && (event.Time <= until || until == 0) {
+		if event.Time >= since && (event.Time <= until || until == 0) && matchFilters(event, eventFilters) {
 			if err := writeEvent(job, event); err != nil {
 				e.mu.RUnlock()
 				return err
@@ -134,10 +199,13 @@ func (e *Events) subscribersCount() int {
 	return c
 }
 
-func (e *Events) log(action, id, from string) {
+func (e *Events) log(action, id, from, name string) {
+	e.logMessage(&utils.JSONMessage{Status: action, ID: id, From: from, Name: name})
+}
+
+func (e *Events) logMessage(jm *utils.JSONMessage) {
 	e.mu.Lock()
-	now := time.Now().UTC().Unix()
-	jm := &utils.JSONMessage{Status: action, ID: id, From: from, Time: now}
+	jm.Time = time.Now().UTC().Unix()
 	if len(e.events) == cap(e.events) {
 		// discard oldest event
 		copy(e.events, e.events[1:])
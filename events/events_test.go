@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/parsers/filters"
 	"github.com/docker/docker/utils"
 )
 
@@ -22,7 +23,7 @@ func TestEventsPublish(t *testing.T) {
 	if count != 2 {
 		t.Fatalf("Must be 2 subscribers, got %d", count)
 	}
-	go e.log("test", "cont", "image")
+	go e.log("test", "cont", "image", "")
 	select {
 	case msg := <-l1:
 		if len(e.events) != 1 {
@@ -66,7 +67,7 @@ func TestEventsPublishTimeout(t *testing.T) {
 
 	c := make(chan struct{})
 	go func() {
-		e.log("test", "cont", "image")
+		e.log("test", "cont", "image", "")
 		close(c)
 	}()
 
@@ -152,3 +153,73 @@ func TestEventsCountJob(t *testing.T) {
 		t.Fatalf("There must be 2 subscribers, got %d", count)
 	}
 }
+
+func TestMatchFilters(t *testing.T) {
+	containerEvent := &utils.JSONMessage{Status: "start", ID: "4386fb97867d", From: "ubuntu:14.04", Name: "web", Labels: map[string]string{"owner": "web-team"}}
+	imageEvent := &utils.JSONMessage{Status: "delete", ID: "12de384bfb10"}
+
+	cases := []struct {
+		event   *utils.JSONMessage
+		filters filters.Args
+		match   bool
+	}{
+		{containerEvent, filters.Args{}, true},
+		{containerEvent, filters.Args{"event": {"start"}}, true},
+		{containerEvent, filters.Args{"event": {"stop", "die"}}, false},
+		{containerEvent, filters.Args{"container": {"4386"}}, true},
+		{containerEvent, filters.Args{"container": {"web"}}, true},
+		{containerEvent, filters.Args{"container": {"db"}}, false},
+		{containerEvent, filters.Args{"image": {"ubuntu"}}, true},
+		{containerEvent, filters.Args{"image": {"ubuntu:14.04"}}, true},
+		{containerEvent, filters.Args{"image": {"ubuntu:12.04"}}, false},
+		{containerEvent, filters.Args{"event": {"start"}, "image": {"busybox"}}, false},
+		{imageEvent, filters.Args{"image": {"12de384bfb10"}}, true},
+		{imageEvent, filters.Args{"container": {"4386"}}, false},
+		{containerEvent, filters.Args{"label": {"owner=web-team"}}, true},
+		{containerEvent, filters.Args{"label": {"owner=db-team"}}, false},
+		{imageEvent, filters.Args{"label": {"owner"}}, false},
+	}
+	for _, c := range cases {
+		if matchFilters(c.event, c.filters) != c.match {
+			t.Fatalf("Expected match=%v for %v with filters %v", c.match, c.event, c.filters)
+		}
+	}
+}
+
+func TestFilteredEventsJob(t *testing.T) {
+	e := New()
+	eng := engine.New()
+	if err := e.Install(eng); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"create", "cont_1", "image_1", "one"},
+		{"start", "cont_1", "image_1", "one"},
+		{"start", "cont_2", "image_2", "two"},
+	} {
+		if err := eng.Job("log", args...).Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	job := eng.Job("events")
+	job.SetenvInt64("since", 1)
+	job.SetenvInt64("until", time.Now().Unix())
+	job.Setenv("filters", `{"event":["start"],"container":["one"]}`)
+	buf := bytes.NewBuffer(nil)
+	job.Stdout.Add(buf)
+	if err := job.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var jm utils.JSONMessage
+	if err := json.NewDecoder(buf).Decode(&jm); err != nil {
+		t.Fatal(err)
+	}
+	if jm.Status != "start" || jm.ID != "cont_1" || jm.Name != "one" {
+		t.Fatalf("Unexpected event %v", jm)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("Expected a single event, got more: %s", buf.String())
+	}
+}
@@ -0,0 +1,150 @@
+package graph
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/registry"
+)
+
+// ErrNoDigest is returned for the images with a layer registered before
+// the layers were checksummed.
+var ErrNoDigest = errors.New("The image has no digest")
+
+var validDigest = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// IsDigest returns true if ref is an image digest, like in the reference
+// name@sha256:<hex>.
+func IsDigest(ref string) bool {
+	return validDigest.MatchString(ref)
+}
+
+// digestPayload is what the digest of an image is computed over
+type digestPayload struct {
+	Layers []string    `json:"layers"` // The tarsums of the layers, from the base image up
+	Config interface{} `json:"config"` // The config of the image JSON, in canonical form
+}
+
+// computeDigest returns the content digest of an image with the given layer
+// tarsums, from the base image up, and JSON. The config is hashed as found in
+// the JSON rather than as runconfig.Config, whose fields change between
+// versions: decoding it into generic values and encoding them again sorts
+// the keys and drops the whitespace, keeping the numbers as written.
+func computeDigest(layers []string, imgJSON []byte) (string, error) {
+	var img struct {
+		Config json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal(imgJSON, &img); err != nil {
+		return "", err
+	}
+	var config interface{}
+	if len(img.Config) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(img.Config))
+		decoder.UseNumber()
+		if err := decoder.Decode(&config); err != nil {
+			return "", err
+		}
+	}
+	payload, err := json.Marshal(digestPayload{Layers: layers, Config: config})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Digest returns the content digest of img, computed over the tarsums of
+// its layers and its config. Unlike the ID, the digest is the same for the
+// same content wherever the image comes from.
+func (graph *Graph) Digest(img *image.Image) (string, error) {
+	graph.digestsLock.Lock()
+	digest, exists := graph.digests[img.ID]
+	graph.digestsLock.Unlock()
+	if exists {
+		return digest, nil
+	}
+
+	history, err := img.History()
+	if err != nil {
+		return "", err
+	}
+	imgJSON, err := img.RawJson()
+	if err != nil {
+		return "", err
+	}
+	layers := make([]string, len(history))
+	for i, layer := range history {
+		if layer.Tarsum == "" {
+			return "", ErrNoDigest
+		}
+		layers[len(history)-1-i] = layer.Tarsum
+	}
+	if digest, err = computeDigest(layers, imgJSON); err != nil {
+		return "", err
+	}
+
+	graph.digestsLock.Lock()
+	graph.digests[img.ID] = digest
+	graph.digestsLock.Unlock()
+	return digest, nil
+}
+
+// manifestDigest returns the content digest of the image described by a v2
+// manifest, without pulling it.
+func manifestDigest(manifest *registry.ManifestData) (string, error) {
+	if len(manifest.History) == 0 || len(manifest.BlobSums) != len(manifest.History) {
+		return "", fmt.Errorf("Invalid manifest for %s:%s", manifest.Name, manifest.Tag)
+	}
+	// the manifest lists the layers from the top image down
+	layers := make([]string, len(manifest.BlobSums))
+	for i, sum := range manifest.BlobSums {
+		layers[len(layers)-1-i] = sum
+	}
+	return computeDigest(layers, []byte(manifest.History[0]))
+}
+
+// GetImageByDigest returns the image with the given digest, preferably one
+// pulled by that digest or tagged in the repository repoName. An image that
+// is no longer tagged, like one whose tag was moved by a pull, still matches.
+func (store *TagStore) GetImageByDigest(repoName, digest string) (*image.Image, error) {
+	repo, err := store.Get(repoName)
+	if err != nil {
+		return nil, err
+	}
+	store.Lock()
+	ids := make([]string, 0, len(repo)+1)
+	if id, exists := store.Digests[repoName][digest]; exists {
+		ids = append(ids, id)
+	}
+	for _, id := range repo {
+		ids = append(ids, id)
+	}
+	store.Unlock()
+
+	for _, id := range ids {
+		img, err := store.graph.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if d, err := store.graph.Digest(img); err == nil && d == digest {
+			return img, nil
+		}
+	}
+
+	images, err := store.graph.Map()
+	if err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		if d, err := store.graph.Digest(img); err == nil && d == digest {
+			return img, nil
+		}
+	}
+	return nil, fmt.Errorf("No such image: %s@%s", repoName, digest)
+}
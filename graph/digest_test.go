@@ -0,0 +1,168 @@
+package graph
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/tarsum"
+	"github.com/docker/docker/registry"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/docker/utils"
+)
+
+func layerTarsum(t *testing.T, layer io.Reader) string {
+	ts, err := tarsum.NewTarSum(layer, true, tarsum.Version0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(ioutil.Discard, ts); err != nil {
+		t.Fatal(err)
+	}
+	return ts.Sum(nil)
+}
+
+func TestIsDigest(t *testing.T) {
+	if !IsDigest("sha256:" + strings.Repeat("0a", 32)) {
+		t.Fatal("Expected a sha256 digest to be valid")
+	}
+	for _, ref := range []string{"latest", "sha256:abc", "md5:" + strings.Repeat("0a", 32), "sha256:" + strings.Repeat("0A", 32)} {
+		if IsDigest(ref) {
+			t.Fatalf("Expected %q not to be a digest", ref)
+		}
+	}
+}
+
+func TestDigest(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestTagStore(tmp, t)
+
+	layer, err := layerTar("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := &image.Image{ID: "bar", Parent: testImageID, Config: &runconfig.Config{Cmd: []string{"/bin/sh"}}}
+	if err := store.graph.Register(child, nil, layer); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := store.graph.Get("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := store.graph.Digest(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsDigest(digest) {
+		t.Fatalf("Invalid digest %q", digest)
+	}
+
+	// the digest computed from a manifest listing the same layers and
+	// config is the same
+	fooLayer, err := fakeTar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	barLayer, err := layerTar("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	barJSON, err := json.Marshal(child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &registry.ManifestData{
+		BlobSums: []string{layerTarsum(t, barLayer), layerTarsum(t, fooLayer)},
+		History:  []string{string(barJSON), `{"id":"foo"}`},
+	}
+	if d, err := manifestDigest(manifest); err != nil || d != digest {
+		t.Fatalf("Expected the digest of the manifest to be %s, got %s (%v)", digest, d, err)
+	}
+
+	// the digest doesn't depend on the IDs
+	copyLayer, err := layerTar("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.graph.Register(&image.Image{ID: "baz", Parent: testImageID, Config: child.Config}, nil, copyLayer); err != nil {
+		t.Fatal(err)
+	}
+	baz, err := store.graph.Get("baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := store.graph.Digest(baz); err != nil || d != digest {
+		t.Fatalf("Expected the copy to have the digest %s, got %s (%v)", digest, d, err)
+	}
+
+	// the config is hashed as written in the JSON, whatever the order of its
+	// fields or the fields runconfig.Config has
+	var fields map[string]interface{}
+	if err := json.Unmarshal(barJSON, &fields); err != nil {
+		t.Fatal(err)
+	}
+	reordered, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := manifestDigest(&registry.ManifestData{BlobSums: manifest.BlobSums, History: []string{string(reordered), `{"id":"foo"}`}}); err != nil || d != digest {
+		t.Fatalf("Expected the reformatted JSON to have the digest %s, got %s (%v)", digest, d, err)
+	}
+	fields["config"].(map[string]interface{})["FieldOfANewerVersion"] = true
+	extended, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := manifestDigest(&registry.ManifestData{BlobSums: manifest.BlobSums, History: []string{string(extended), `{"id":"foo"}`}}); err != nil || d == digest {
+		t.Fatalf("Expected a config field unknown to this version to change the digest, got %s (%v)", d, err)
+	}
+
+	// a change of config changes the digest
+	if d, err := manifestDigest(&registry.ManifestData{BlobSums: manifest.BlobSums, History: []string{`{"id":"bar"}`, `{"id":"foo"}`}}); err != nil || d == digest {
+		t.Fatalf("Expected another config to change the digest, got %s (%v)", d, err)
+	}
+}
+
+func TestLookupImageByDigest(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestTagStore(tmp, t)
+
+	img, err := store.LookupImage(testImageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := store.graph.Digest(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if img, err := store.LookupImage(testImageName + "@" + digest); err != nil || img == nil || img.ID != testImageID {
+		t.Fatalf("Expected to find %s by digest, got %v (%v)", testImageID, img, err)
+	}
+
+	// an untagged image is still found by its digest
+	if _, err := store.Delete(testImageName, DEFAULTTAG); err != nil {
+		t.Fatal(err)
+	}
+	if img, err := store.LookupImage(testImageName + "@" + digest); err != nil || img == nil || img.ID != testImageID {
+		t.Fatalf("Expected to find the untagged %s by digest, got %v (%v)", testImageID, img, err)
+	}
+
+	unknown := "sha256:" + strings.Repeat("0", 64)
+	if _, err := store.LookupImage(testImageName + "@" + unknown); err == nil || !store.graph.IsNotExist(err) {
+		t.Fatalf("Expected no image with the digest %s, got %v", unknown, err)
+	}
+}
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -27,6 +29,9 @@ type Graph struct {
 	Root    string
 	idIndex *truncindex.TruncIndex
 	driver  graphdriver.Driver
+
+	digests     map[string]string // the digests of the images, by ID
+	digestsLock sync.Mutex
 }
 
 // NewGraph instantiates a new graph at the given root path in the filesystem.
@@ -45,6 +50,7 @@ func NewGraph(root string, driver graphdriver.Driver) (*Graph, error) {
 		Root:    abspath,
 		idIndex: truncindex.NewTruncIndex([]string{}),
 		driver:  driver,
+		digests: make(map[string]string),
 	}
 	if err := graph.restore(); err != nil {
 		return nil, err
@@ -138,6 +144,70 @@ func (graph *Graph) Create(layerData archive.ArchiveReader, containerID, contain
 	return img, nil
 }
 
+// errStopWalk stops walking the history of an image
+var errStopWalk = errors.New("stop walking the history")
+
+// Squash creates an image with the filesystem and configuration of img, and a
+// single layer on top of base. base must be an ancestor of img, or "" to
+// squash all the layers of img into one. The images whose layers are merged
+// are recorded in the squashed history of the new image.
+func (graph *Graph) Squash(img *image.Image, base string) (*image.Image, error) {
+	var (
+		history []*image.HistoryEntry
+		found   = base == ""
+	)
+	err := img.WalkHistory(func(parent *image.Image) error {
+		if parent.ID == base {
+			found = true
+			return errStopWalk
+		}
+		if parent.ID != img.ID {
+			history = append(history, &image.HistoryEntry{
+				ID:        parent.ID,
+				Created:   parent.Created,
+				CreatedBy: strings.Join(parent.ContainerConfig.Cmd, " "),
+				Author:    parent.Author,
+				Comment:   parent.Comment,
+			})
+		}
+		history = append(history, parent.SquashedHistory...)
+		return nil
+	})
+	if err != nil && err != errStopWalk {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("Image %s is not an ancestor of %s", base, img.ID)
+	}
+
+	// the diff between img and base, not only the layer of img
+	layerData, err := graphdriver.NaiveDiffDriver(graph.driver).Diff(img.ID, base)
+	if err != nil {
+		return nil, err
+	}
+	defer layerData.Close()
+
+	squashed := &image.Image{
+		ID:              utils.GenerateRandomID(),
+		Parent:          base,
+		Comment:         img.Comment,
+		Created:         time.Now().UTC(),
+		Container:       img.Container,
+		ContainerConfig: img.ContainerConfig,
+		DockerVersion:   dockerversion.VERSION,
+		Author:          img.Author,
+		Config:          img.Config,
+		Architecture:    runtime.GOARCH,
+		OS:              runtime.GOOS,
+		SquashedHistory: history,
+	}
+
+	if err := graph.Register(squashed, nil, layerData); err != nil {
+		return nil, err
+	}
+	return squashed, nil
+}
+
 // Register imports a pre-existing image into the graph.
 func (graph *Graph) Register(img *image.Image, jsonData []byte, layerData archive.ArchiveReader) (err error) {
 	defer func() {
@@ -176,7 +246,7 @@ func (graph *Graph) Register(img *image.Image, jsonData []byte, layerData archiv
 	}
 
 	// Create root filesystem in the driver
-	if err := graph.driver.Create(img.ID, img.Parent); err != nil {
+	if err := graph.driver.Create(img.ID, img.Parent, nil); err != nil {
 		return fmt.Errorf("Driver %s failed to create image rootfs %s: %s", graph.driver, img.ID, err)
 	}
 	// Apply the diff/layer
@@ -303,6 +373,9 @@ func (graph *Graph) Delete(name string) error {
 	}
 	tmp, err := graph.Mktemp("")
 	graph.idIndex.Delete(id)
+	graph.digestsLock.Lock()
+	delete(graph.digests, id)
+	graph.digestsLock.Unlock()
 	if err == nil {
 		err = os.Rename(graph.ImageRoot(id), tmp)
 		// On err make tmp point to old dir and cleanup unused tmp dir
@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/docker/utils"
+	"github.com/docker/docker/vendor/src/code.google.com/p/go/src/pkg/archive/tar"
+)
+
+// layerTar returns a layer with the given files, a file named .wh.<name>
+// deletes <name>
+func layerTar(names ...string) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name: name,
+			Uid:  os.Getuid(),
+			Gid:  os.Getgid(),
+			Mode: 0644,
+			Size: int64(len(name)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		tw.Write([]byte(name))
+	}
+	tw.Close()
+	return buf, nil
+}
+
+func TestSquash(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	driver, err := graphdriver.New(tmp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Cleanup()
+	graph, err := NewGraph(tmp, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layers := []struct {
+		img   *image.Image
+		files []string
+	}{
+		{&image.Image{ID: "base"}, []string{"/etc/passwd", "/etc/group"}},
+		{&image.Image{ID: "first", Parent: "base", ContainerConfig: runconfig.Config{Cmd: []string{"add", "a"}}}, []string{"/a", "/etc/.wh.passwd"}},
+		{&image.Image{ID: "second", Parent: "first", ContainerConfig: runconfig.Config{Cmd: []string{"add", "b"}}}, []string{"/b"}},
+	}
+	for _, layer := range layers {
+		archive, err := layerTar(layer.files...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := graph.Register(layer.img, nil, archive); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	img, err := graph.Get("second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := graph.Squash(img, "notanancestor"); err == nil {
+		t.Fatal("Expected an error squashing onto an image that is not an ancestor")
+	}
+
+	squashed, err := graph.Squash(img, "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if squashed.Parent != "base" {
+		t.Fatalf("Expected the squashed image to have the parent base, got %q", squashed.Parent)
+	}
+	if len(squashed.SquashedHistory) != 1 || squashed.SquashedHistory[0].ID != "first" || squashed.SquashedHistory[0].CreatedBy != "add a" {
+		t.Fatalf("Expected the squashed history to hold the first image, got %v", squashed.SquashedHistory)
+	}
+
+	root, err := driver.Get(squashed.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Put(squashed.ID)
+
+	for _, name := range []string{"a", "b", "etc/group"} {
+		if _, err := os.Stat(path.Join(root, name)); err != nil {
+			t.Fatalf("Expected %s in the squashed image: %s", name, err)
+		}
+	}
+	if _, err := os.Stat(path.Join(root, "etc/passwd")); !os.IsNotExist(err) {
+		t.Fatalf("Expected etc/passwd to be deleted in the squashed image, got %v", err)
+	}
+
+	// squash the squashed image and its base, the history is kept
+	flattened, err := graph.Squash(squashed, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flattened.Parent != "" {
+		t.Fatalf("Expected the flattened image to have no parent, got %q", flattened.Parent)
+	}
+	if n := len(flattened.SquashedHistory); n != 2 {
+		t.Fatalf("Expected 2 entries in the history of the flattened image, got %d", n)
+	}
+	if id := flattened.SquashedHistory[1].ID; id != "base" {
+		t.Fatalf("Expected the base image last in the history, got %s", id)
+	}
+}
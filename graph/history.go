@@ -37,6 +37,16 @@ func (s *TagStore) CmdHistory(job *engine.Job) engine.Status {
 		out.SetList("Tags", lookupMap[img.ID])
 		out.SetInt64("Size", img.Size)
 		outs.Add(out)
+
+		// the images squashed into this one have no layer of their own
+		for _, entry := range img.SquashedHistory {
+			out := &engine.Env{}
+			out.Set("Id", "<missing>")
+			out.SetInt64("Created", entry.Created.Unix())
+			out.Set("CreatedBy", entry.CreatedBy)
+			out.SetInt64("Size", 0)
+			outs.Add(out)
+		}
 		return nil
 	})
 	if _, err := outs.WriteListTo(job.Stdout); err != nil {
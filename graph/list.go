@@ -52,6 +52,10 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 				log.Printf("Warning: couldn't load %s from %s/%s: %s", id, name, tag, err)
 				continue
 			}
+			if !imageFilters.MatchKVList("label", imageLabels(image)) {
+				delete(allImages, id)
+				continue
+			}
 
 			if out, exists := lookup[id]; exists {
 				if filt_tagged {
@@ -65,6 +69,7 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 					out.Set("ParentId", image.Parent)
 					out.SetList("RepoTags", []string{fmt.Sprintf("%s:%s", name, tag)})
 					out.Set("Id", image.ID)
+					out.Set("Digest", s.imageDigest(image))
 					out.SetInt64("Created", image.Created.Unix())
 					out.SetInt64("Size", image.Size)
 					out.SetInt64("VirtualSize", image.GetParentsSize(0)+image.Size)
@@ -74,6 +79,37 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 
 		}
 	}
+	// the images pulled by digest are referenced, but have no tag
+	for name, digests := range s.Digests {
+		if job.Getenv("filter") != "" {
+			if match, _ := path.Match(job.Getenv("filter"), name); !match {
+				continue
+			}
+		}
+		for _, id := range digests {
+			if _, exists := lookup[id]; exists {
+				continue
+			}
+			image, err := s.graph.Get(id)
+			if err != nil {
+				log.Printf("Warning: couldn't load %s pulled by digest from %s: %s", id, name, err)
+				continue
+			}
+			delete(allImages, id)
+			if !filt_tagged || !imageFilters.MatchKVList("label", imageLabels(image)) {
+				continue
+			}
+			out := &engine.Env{}
+			out.Set("ParentId", image.Parent)
+			out.SetList("RepoTags", []string{name + ":<none>"})
+			out.Set("Id", image.ID)
+			out.Set("Digest", s.imageDigest(image))
+			out.SetInt64("Created", image.Created.Unix())
+			out.SetInt64("Size", image.Size)
+			out.SetInt64("VirtualSize", image.GetParentsSize(0)+image.Size)
+			lookup[id] = out
+		}
+	}
 	s.Unlock()
 
 	outs := engine.NewTable("Created", len(lookup))
@@ -84,10 +120,14 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 	// Display images which aren't part of a repository/tag
 	if job.Getenv("filter") == "" {
 		for _, image := range allImages {
+			if !imageFilters.MatchKVList("label", imageLabels(image)) {
+				continue
+			}
 			out := &engine.Env{}
 			out.Set("ParentId", image.Parent)
 			out.SetList("RepoTags", []string{"<none>:<none>"})
 			out.Set("Id", image.ID)
+			out.Set("Digest", s.imageDigest(image))
 			out.SetInt64("Created", image.Created.Unix())
 			out.SetInt64("Size", image.Size)
 			out.SetInt64("VirtualSize", image.GetParentsSize(0)+image.Size)
@@ -101,3 +141,20 @@ func (s *TagStore) CmdImages(job *engine.Job) engine.Status {
 	}
 	return engine.StatusOK
 }
+
+// imageDigest returns the digest of img, or an empty string if it has none.
+func (s *TagStore) imageDigest(img *image.Image) string {
+	digest, err := s.graph.Digest(img)
+	if err != nil {
+		return ""
+	}
+	return digest
+}
+
+// imageLabels returns the labels set on an image with LABEL, if any.
+func imageLabels(img *image.Image) map[string]string {
+	if img.Config == nil {
+		return nil
+	}
+	return img.Config.Labels
+}
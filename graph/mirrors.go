@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/log"
+	"github.com/docker/docker/utils"
+)
+
+const (
+	// a failing mirror is skipped for mirrorBackoffMin, doubled on every
+	// consecutive failure up to mirrorBackoffMax
+	mirrorBackoffMin = 30 * time.Second
+	mirrorBackoffMax = 10 * time.Minute
+)
+
+// timeNow is replaced in tests
+var timeNow = time.Now
+
+// MirrorStatus is the health of a registry mirror
+type MirrorStatus struct {
+	Endpoint     string
+	Healthy      bool      // false after a failure, until the next successful pull
+	Failures     int       // Number of consecutive failures
+	LastError    string    // The error of the last failure
+	RetryAt      time.Time // The mirror is skipped until then
+	LastPull     string    // The last image pulled from the mirror
+	LastPullTime time.Time
+}
+
+// mirrorSet tracks the failures of the registry mirrors, and backs off from
+// the failing ones
+type mirrorSet struct {
+	sync.Mutex
+	mirrors []*MirrorStatus
+}
+
+func newMirrorSet(endpoints []string) *mirrorSet {
+	m := &mirrorSet{}
+	for _, ep := range endpoints {
+		m.mirrors = append(m.mirrors, &MirrorStatus{Endpoint: ep, Healthy: true})
+	}
+	return m
+}
+
+func (m *mirrorSet) get(endpoint string) *MirrorStatus {
+	for _, mirror := range m.mirrors {
+		if mirror.Endpoint == endpoint {
+			return mirror
+		}
+	}
+	return nil
+}
+
+// available returns the mirrors to try, in the order of preference, without
+// the ones backed off from.
+func (m *mirrorSet) available() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	var (
+		endpoints []string
+		now       = timeNow()
+	)
+	for _, mirror := range m.mirrors {
+		if now.Before(mirror.RetryAt) {
+			log.Debugf("Skipping the mirror %s until %s", mirror.Endpoint, mirror.RetryAt)
+			continue
+		}
+		endpoints = append(endpoints, mirror.Endpoint)
+	}
+	return endpoints
+}
+
+// isDown returns true if endpoint is a mirror backed off from.
+func (m *mirrorSet) isDown(endpoint string) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	mirror := m.get(endpoint)
+	return mirror != nil && timeNow().Before(mirror.RetryAt)
+}
+
+// failure records that a request to endpoint failed with err. Client
+// errors returned by the mirror, like a missing image, don't count as
+// failures: the mirror is up. Other endpoints than the mirrors are ignored.
+func (m *mirrorSet) failure(endpoint string, err error) {
+	if jsonErr, ok := err.(*utils.JSONError); ok && jsonErr.Code < 500 {
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	mirror := m.get(endpoint)
+	if mirror == nil {
+		return
+	}
+	mirror.Healthy = false
+	mirror.Failures++
+	mirror.LastError = err.Error()
+
+	backoff := mirrorBackoffMin
+	for i := 1; i < mirror.Failures && backoff < mirrorBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > mirrorBackoffMax {
+		backoff = mirrorBackoffMax
+	}
+	mirror.RetryAt = timeNow().Add(backoff)
+	log.Infof("Registry mirror %s failed (%s), skipping it for %s", endpoint, err, backoff)
+}
+
+// success records that the image named name was pulled from endpoint.
+func (m *mirrorSet) success(endpoint, name string) {
+	m.Lock()
+	defer m.Unlock()
+
+	mirror := m.get(endpoint)
+	if mirror == nil {
+		return
+	}
+	mirror.Healthy = true
+	mirror.Failures = 0
+	mirror.RetryAt = time.Time{}
+	mirror.LastPull = name
+	mirror.LastPullTime = timeNow().UTC()
+}
+
+// status returns a copy of the status of the mirrors.
+func (m *mirrorSet) status() []MirrorStatus {
+	m.Lock()
+	defer m.Unlock()
+
+	status := make([]MirrorStatus, len(m.mirrors))
+	for i, mirror := range m.mirrors {
+		status[i] = *mirror
+	}
+	return status
+}
+
+// MirrorStatus returns the health of the registry mirrors.
+func (s *TagStore) MirrorStatus() []MirrorStatus {
+	return s.mirrors.status()
+}
@@ -0,0 +1,75 @@
+package graph
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/utils"
+)
+
+func TestMirrorSetBackoff(t *testing.T) {
+	defer func(f func() time.Time) { timeNow = f }(timeNow)
+	now := time.Date(2014, 11, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	m := newMirrorSet([]string{"https://mirror1/v1/", "https://mirror2/v1/"})
+	if endpoints := m.available(); !reflect.DeepEqual(endpoints, []string{"https://mirror1/v1/", "https://mirror2/v1/"}) {
+		t.Fatalf("Expected both mirrors to be available, got %v", endpoints)
+	}
+
+	// a missing image isn't a failure of the mirror
+	m.failure("https://mirror1/v1/", &utils.JSONError{Code: 404, Message: "HTTP code 404"})
+	if m.isDown("https://mirror1/v1/") {
+		t.Fatal("Expected a mirror answering 404 to be up")
+	}
+
+	// the origin isn't tracked
+	m.failure("https://registry-1.docker.io/v1/", errors.New("connection refused"))
+
+	m.failure("https://mirror1/v1/", errors.New("connection refused"))
+	if endpoints := m.available(); !reflect.DeepEqual(endpoints, []string{"https://mirror2/v1/"}) {
+		t.Fatalf("Expected only mirror2 to be available, got %v", endpoints)
+	}
+	if !m.isDown("https://mirror1/v1/") {
+		t.Fatal("Expected mirror1 to be down")
+	}
+
+	// the mirror is retried after the backoff, which doubles on failure
+	now = now.Add(mirrorBackoffMin)
+	if m.isDown("https://mirror1/v1/") {
+		t.Fatalf("Expected mirror1 to be retried after %s", mirrorBackoffMin)
+	}
+	m.failure("https://mirror1/v1/", errors.New("connection refused"))
+	now = now.Add(mirrorBackoffMin)
+	if !m.isDown("https://mirror1/v1/") {
+		t.Fatal("Expected the backoff of mirror1 to double")
+	}
+
+	for i := 0; i < 10; i++ {
+		m.failure("https://mirror1/v1/", errors.New("connection refused"))
+	}
+	status := m.status()
+	if status[0].Failures != 12 || status[0].Healthy || status[0].LastError != "connection refused" {
+		t.Fatalf("Unexpected status of mirror1: %+v", status[0])
+	}
+	if backoff := status[0].RetryAt.Sub(now); backoff != mirrorBackoffMax {
+		t.Fatalf("Expected the backoff to be capped to %s, got %s", mirrorBackoffMax, backoff)
+	}
+
+	m.success("https://mirror1/v1/", "busybox:latest")
+	status = m.status()
+	if !status[0].Healthy || status[0].Failures != 0 || status[0].LastPull != "busybox:latest" || !status[0].LastPullTime.Equal(now) {
+		t.Fatalf("Unexpected status of mirror1 after a pull: %+v", status[0])
+	}
+	if m.isDown("https://mirror1/v1/") {
+		t.Fatal("Expected mirror1 to be up after a pull")
+	}
+
+	// a server error is a failure of the mirror
+	m.failure("https://mirror2/v1/", &utils.JSONError{Code: 503, Message: "HTTP code 503"})
+	if !m.isDown("https://mirror2/v1/") {
+		t.Fatal("Expected a mirror answering 503 to be down")
+	}
+}
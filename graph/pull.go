@@ -108,7 +108,7 @@ func (s *TagStore) CmdPull(job *engine.Job) engine.Status {
 		return job.Error(err)
 	}
 
-	endpoint, err := registry.NewEndpoint(hostname)
+	endpoint, err := registry.NewEndpoint(hostname, s.insecureRegistries)
 	if err != nil {
 		return job.Error(err)
 	}
@@ -128,8 +128,8 @@ func (s *TagStore) CmdPull(job *engine.Job) engine.Status {
 			remoteName = "library/" + remoteName
 		}
 
-		// Use provided mirrors, if any
-		mirrors = s.mirrors
+		// Use provided mirrors, if any, except the failing ones
+		mirrors = s.mirrors.available()
 	}
 
 	if isOfficial || endpoint.Version == registry.APIVersion2 {
@@ -139,18 +139,41 @@ func (s *TagStore) CmdPull(job *engine.Job) engine.Status {
 		}
 
 		if err := s.pullV2Repository(job.Eng, r, job.Stdout, localName, remoteName, tag, sf, job.GetenvBool("parallel")); err == nil {
+			s.writeDigest(job.Stdout, sf, localName, tag)
 			return engine.StatusOK
+		} else if IsDigest(tag) && err != registry.ErrDoesNotExist {
+			return job.Error(err)
 		} else if err != registry.ErrDoesNotExist {
 			log.Errorf("Error from V2 registry: %s", err)
 		}
 	}
+	if IsDigest(tag) {
+		// v1 registries know the images by ID only
+		return job.Errorf("Pulling %s@%s by digest requires a registry with the v2 API", localName, tag)
+	}
 	if err = s.pullRepository(r, job.Stdout, localName, remoteName, tag, sf, job.GetenvBool("parallel"), mirrors); err != nil {
 		return job.Error(err)
 	}
+	s.writeDigest(job.Stdout, sf, localName, tag)
 
 	return engine.StatusOK
 }
 
+// writeDigest reports the digest of the image pulled as localName:tag, if
+// a single tag was pulled.
+func (s *TagStore) writeDigest(out io.Writer, sf *utils.StreamFormatter, localName, tag string) {
+	if tag == "" || IsDigest(tag) {
+		return
+	}
+	img, err := s.GetImage(localName, tag)
+	if err != nil || img == nil {
+		return
+	}
+	if digest, err := s.graph.Digest(img); err == nil {
+		out.Write(sf.FormatStatus("", "Digest: %s", digest))
+	}
+}
+
 func (s *TagStore) pullRepository(r *registry.Session, out io.Writer, localName, remoteName, askedTag string, sf *utils.StreamFormatter, parallel bool, mirrors []string) error {
 	out.Write(sf.FormatStatus("", "Pulling repository %s", localName))
 
@@ -238,8 +261,12 @@ func (s *TagStore) pullRepository(r *registry.Session, out io.Writer, localName,
 			var is_downloaded bool
 			if mirrors != nil {
 				for _, ep := range mirrors {
+					// the mirror may have failed since the pull started
+					if s.mirrors.isDown(ep) {
+						continue
+					}
 					out.Write(sf.FormatProgress(utils.TruncateID(img.ID), fmt.Sprintf("Pulling image (%s) from %s, mirror: %s", img.Tag, localName, ep), nil))
-					if is_downloaded, err = s.pullImage(r, out, img.ID, ep, repoData.Tokens, sf); err != nil {
+					if is_downloaded, err = s.pullImage(r, out, img.ID, localName+":"+img.Tag, ep, repoData.Endpoints, repoData.Tokens, sf); err != nil {
 						// Don't report errors when pulling from mirrors.
 						log.Debugf("Error pulling image (%s) from %s, mirror: %s, %s", img.Tag, localName, ep, err)
 						continue
@@ -252,7 +279,7 @@ func (s *TagStore) pullRepository(r *registry.Session, out io.Writer, localName,
 			if !success {
 				for _, ep := range repoData.Endpoints {
 					out.Write(sf.FormatProgress(utils.TruncateID(img.ID), fmt.Sprintf("Pulling image (%s) from %s, endpoint: %s", img.Tag, localName, ep), nil))
-					if is_downloaded, err = s.pullImage(r, out, img.ID, ep, repoData.Tokens, sf); err != nil {
+					if is_downloaded, err = s.pullImage(r, out, img.ID, localName+":"+img.Tag, ep, nil, repoData.Tokens, sf); err != nil {
 						// It's not ideal that only the last error is returned, it would be better to concatenate the errors.
 						// As the error is also given to the output stream the user will see the error.
 						lastErr = err
@@ -314,16 +341,24 @@ func (s *TagStore) pullRepository(r *registry.Session, out io.Writer, localName,
 	return nil
 }
 
-func (s *TagStore) pullImage(r *registry.Session, out io.Writer, imgID, endpoint string, token []string, sf *utils.StreamFormatter) (bool, error) {
+// pullImage pulls the image imgID and its parents from endpoint. If endpoint
+// is a mirror, the layers it fails to serve are pulled from the fallbacks,
+// the endpoints of the repository, and the pull of name is credited to the
+// mirror only if it served every layer.
+func (s *TagStore) pullImage(r *registry.Session, out io.Writer, imgID, name, endpoint string, fallbacks []string, token []string, sf *utils.StreamFormatter) (bool, error) {
 	history, err := r.GetRemoteHistory(imgID, endpoint, token)
 	if err != nil {
+		s.mirrors.failure(endpoint, err)
 		return false, err
 	}
 	out.Write(sf.FormatProgress(utils.TruncateID(imgID), "Pulling dependent layers", nil))
 	// FIXME: Try to stream the images?
 	// FIXME: Launch the getRemoteImage() in goroutines
 
-	layers_downloaded := false
+	var (
+		layers_downloaded = false
+		mirrored          = len(fallbacks) > 0
+	)
 	for i := len(history) - 1; i >= 0; i-- {
 		id := history[i]
 
@@ -335,72 +370,109 @@ func (s *TagStore) pullImage(r *registry.Session, out io.Writer, imgID, endpoint
 		defer s.poolRemove("pull", "layer:"+id)
 
 		if !s.graph.Exists(id) {
-			out.Write(sf.FormatProgress(utils.TruncateID(id), "Pulling metadata", nil))
-			var (
-				imgJSON []byte
-				imgSize int
-				err     error
-				img     *image.Image
-			)
-			retries := 5
-			for j := 1; j <= retries; j++ {
-				imgJSON, imgSize, err = r.GetRemoteImageJSON(id, endpoint, token)
-				if err != nil && j == retries {
-					out.Write(sf.FormatProgress(utils.TruncateID(id), "Error pulling dependent layers", nil))
-					return layers_downloaded, err
-				} else if err != nil {
-					time.Sleep(time.Duration(j) * 500 * time.Millisecond)
-					continue
-				}
-				img, err = image.NewImgJSON(imgJSON)
-				layers_downloaded = true
-				if err != nil && j == retries {
-					out.Write(sf.FormatProgress(utils.TruncateID(id), "Error pulling dependent layers", nil))
-					return layers_downloaded, fmt.Errorf("Failed to parse json: %s", err)
-				} else if err != nil {
-					time.Sleep(time.Duration(j) * 500 * time.Millisecond)
-					continue
-				} else {
-					break
-				}
+			endpoints := append([]string{endpoint}, fallbacks...)
+			if len(fallbacks) > 0 && s.mirrors.isDown(endpoint) {
+				// the mirror failed for a previous layer, don't wait for it again
+				endpoints = fallbacks
 			}
 
-			for j := 1; j <= retries; j++ {
-				// Get the layer
-				status := "Pulling fs layer"
-				if j > 1 {
-					status = fmt.Sprintf("Pulling fs layer [retries: %d]", j)
-				}
-				out.Write(sf.FormatProgress(utils.TruncateID(id), status, nil))
-				layer, err := r.GetRemoteImageLayer(img.ID, endpoint, token, int64(imgSize))
-				if uerr, ok := err.(*url.Error); ok {
-					err = uerr.Err
+			var (
+				is_downloaded bool
+				err           error
+			)
+			for j, ep := range endpoints {
+				if ep != endpoint {
+					mirrored = false
 				}
-				if terr, ok := err.(net.Error); ok && terr.Timeout() && j < retries {
-					time.Sleep(time.Duration(j) * 500 * time.Millisecond)
-					continue
-				} else if err != nil {
-					out.Write(sf.FormatProgress(utils.TruncateID(id), "Error pulling dependent layers", nil))
-					return layers_downloaded, err
+				if j > 0 {
+					out.Write(sf.FormatProgress(utils.TruncateID(id), fmt.Sprintf("Pulling fs layer from %s", ep), nil))
 				}
-				layers_downloaded = true
-				defer layer.Close()
-
-				err = s.graph.Register(img, imgJSON,
-					utils.ProgressReader(layer, imgSize, out, sf, false, utils.TruncateID(id), "Downloading"))
-				if terr, ok := err.(net.Error); ok && terr.Timeout() && j < retries {
-					time.Sleep(time.Duration(j) * 500 * time.Millisecond)
-					continue
-				} else if err != nil {
-					out.Write(sf.FormatProgress(utils.TruncateID(id), "Error downloading dependent layers", nil))
-					return layers_downloaded, err
-				} else {
+				is_downloaded, err = s.pullLayer(r, out, id, ep, token, sf)
+				layers_downloaded = layers_downloaded || is_downloaded
+				if err == nil {
 					break
 				}
+				s.mirrors.failure(ep, err)
+			}
+			if err != nil {
+				return layers_downloaded, err
 			}
 		}
 		out.Write(sf.FormatProgress(utils.TruncateID(id), "Download complete", nil))
 	}
+	if mirrored {
+		s.mirrors.success(endpoint, name)
+	}
+	return layers_downloaded, nil
+}
+
+// pullLayer pulls the metadata and the fs layer of the image id from endpoint,
+// and registers it in the graph.
+func (s *TagStore) pullLayer(r *registry.Session, out io.Writer, id, endpoint string, token []string, sf *utils.StreamFormatter) (bool, error) {
+	out.Write(sf.FormatProgress(utils.TruncateID(id), "Pulling metadata", nil))
+	var (
+		imgJSON           []byte
+		imgSize           int
+		err               error
+		img               *image.Image
+		layers_downloaded bool
+	)
+	retries := 5
+	for j := 1; j <= retries; j++ {
+		imgJSON, imgSize, err = r.GetRemoteImageJSON(id, endpoint, token)
+		if err != nil && j == retries {
+			out.Write(sf.FormatProgress(utils.TruncateID(id), "Error pulling dependent layers", nil))
+			return layers_downloaded, err
+		} else if err != nil {
+			time.Sleep(time.Duration(j) * 500 * time.Millisecond)
+			continue
+		}
+		img, err = image.NewImgJSON(imgJSON)
+		layers_downloaded = true
+		if err != nil && j == retries {
+			out.Write(sf.FormatProgress(utils.TruncateID(id), "Error pulling dependent layers", nil))
+			return layers_downloaded, fmt.Errorf("Failed to parse json: %s", err)
+		} else if err != nil {
+			time.Sleep(time.Duration(j) * 500 * time.Millisecond)
+			continue
+		} else {
+			break
+		}
+	}
+
+	for j := 1; j <= retries; j++ {
+		// Get the layer
+		status := "Pulling fs layer"
+		if j > 1 {
+			status = fmt.Sprintf("Pulling fs layer [retries: %d]", j)
+		}
+		out.Write(sf.FormatProgress(utils.TruncateID(id), status, nil))
+		layer, err := r.GetRemoteImageLayer(img.ID, endpoint, token, int64(imgSize))
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		if terr, ok := err.(net.Error); ok && terr.Timeout() && j < retries {
+			time.Sleep(time.Duration(j) * 500 * time.Millisecond)
+			continue
+		} else if err != nil {
+			out.Write(sf.FormatProgress(utils.TruncateID(id), "Error pulling dependent layers", nil))
+			return layers_downloaded, err
+		}
+		layers_downloaded = true
+		defer layer.Close()
+
+		err = s.graph.Register(img, imgJSON,
+			utils.ProgressReader(layer, imgSize, out, sf, false, utils.TruncateID(id), "Downloading"))
+		if terr, ok := err.(net.Error); ok && terr.Timeout() && j < retries {
+			time.Sleep(time.Duration(j) * 500 * time.Millisecond)
+			continue
+		} else if err != nil {
+			out.Write(sf.FormatProgress(utils.TruncateID(id), "Error downloading dependent layers", nil))
+			return layers_downloaded, err
+		} else {
+			break
+		}
+	}
 	return layers_downloaded, nil
 }
 
@@ -423,6 +495,9 @@ type downloadInfo struct {
 }
 
 func (s *TagStore) pullV2Repository(eng *engine.Engine, r *registry.Session, out io.Writer, localName, remoteName, tag string, sf *utils.StreamFormatter, parallel bool) error {
+	if IsDigest(tag) {
+		return s.pullV2Digest(eng, r, out, localName, remoteName, tag, sf, parallel)
+	}
 	if tag == "" {
 		log.Debugf("Pulling tag list from V2 registry for %s", remoteName)
 		tags, err := r.GetV2RemoteTags(remoteName, nil)
@@ -443,26 +518,92 @@ func (s *TagStore) pullV2Repository(eng *engine.Engine, r *registry.Session, out
 	return nil
 }
 
-func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Writer, localName, remoteName, tag string, sf *utils.StreamFormatter, parallel bool) error {
-	log.Debugf("Pulling tag from V2 registry: %q", tag)
-	manifestBytes, err := r.GetV2ImageManifest(remoteName, tag, nil)
+// pullV2Digest pulls the image of the given digest, found among the tags of
+// the repository. The digests of the tags are computed from their manifests,
+// so that only the matching image is pulled. The image isn't tagged, it is
+// referred to by its digest.
+func (s *TagStore) pullV2Digest(eng *engine.Engine, r *registry.Session, out io.Writer, localName, remoteName, digest string, sf *utils.StreamFormatter, parallel bool) error {
+	if img, err := s.GetImageByDigest(localName, digest); err == nil {
+		if err := s.SetDigest(localName, digest, img.ID); err != nil {
+			return err
+		}
+		out.Write(sf.FormatStatus("", "Status: Image is up to date for %s@%s", localName, digest))
+		return nil
+	}
+
+	log.Debugf("Pulling tag list from V2 registry for %s", remoteName)
+	tags, err := r.GetV2RemoteTags(remoteName, nil)
 	if err != nil {
 		return err
 	}
+	for _, tag := range tags {
+		manifest, verified, err := s.getV2Manifest(eng, r, remoteName, tag)
+		if err != nil {
+			// another tag may still have the image
+			log.Debugf("Skipping %s:%s: %s", remoteName, tag, err)
+			continue
+		}
+		if d, err := manifestDigest(manifest); err != nil || d != digest {
+			continue
+		}
+
+		if _, err := s.pullV2Manifest(r, out, localName, remoteName, digest, manifest, verified, sf, parallel); err != nil {
+			return err
+		}
+		// the layers were checksummed again when they were registered
+		img, err := s.GetImageByDigest(localName, digest)
+		if err != nil {
+			return fmt.Errorf("The image pulled from %s:%s doesn't have the digest %s", localName, tag, digest)
+		}
+		if err := s.SetDigest(localName, digest, img.ID); err != nil {
+			return err
+		}
+		out.Write(sf.FormatStatus("", "Digest: %s", digest))
+		return nil
+	}
+	return fmt.Errorf("No tag of %s has an image with the digest %s", localName, digest)
+}
+
+// getV2Manifest fetches and verifies the manifest of remoteName:tag.
+func (s *TagStore) getV2Manifest(eng *engine.Engine, r *registry.Session, remoteName, tag string) (*registry.ManifestData, bool, error) {
+	manifestBytes, err := r.GetV2ImageManifest(remoteName, tag, nil)
+	if err != nil {
+		return nil, false, err
+	}
 
 	manifest, verified, err := s.verifyManifest(eng, manifestBytes)
 	if err != nil {
-		return fmt.Errorf("error verifying manifest: %s", err)
+		return nil, false, fmt.Errorf("error verifying manifest: %s", err)
 	}
 
 	if len(manifest.BlobSums) != len(manifest.History) {
-		return fmt.Errorf("length of history not equal to number of layers")
+		return nil, false, fmt.Errorf("length of history not equal to number of layers")
+	}
+	return manifest, verified, nil
+}
+
+func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Writer, localName, remoteName, tag string, sf *utils.StreamFormatter, parallel bool) error {
+	log.Debugf("Pulling tag from V2 registry: %q", tag)
+	manifest, verified, err := s.getV2Manifest(eng, r, remoteName, tag)
+	if err != nil {
+		return err
+	}
+
+	imgID, err := s.pullV2Manifest(r, out, localName, remoteName, tag, manifest, verified, sf, parallel)
+	if err != nil {
+		return err
 	}
+	return s.Set(localName, tag, imgID, true)
+}
 
+// pullV2Manifest pulls and registers the layers of manifest that are missing,
+// and returns the ID of the image. ref is the tag or digest being pulled.
+func (s *TagStore) pullV2Manifest(r *registry.Session, out io.Writer, localName, remoteName, ref string, manifest *registry.ManifestData, verified bool, sf *utils.StreamFormatter, parallel bool) (string, error) {
+	var err error
 	if verified {
 		out.Write(sf.FormatStatus("", "The image you are pulling has been digitally signed by Docker, Inc."))
 	}
-	out.Write(sf.FormatStatus(tag, "Pulling from %s", localName))
+	out.Write(sf.FormatStatus(ref, "Pulling from %s", localName))
 
 	downloads := make([]downloadInfo, len(manifest.BlobSums))
 
@@ -474,7 +615,7 @@ func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Wri
 
 		img, err := image.NewImgJSON(imgJSON)
 		if err != nil {
-			return fmt.Errorf("failed to parse json: %s", err)
+			return "", fmt.Errorf("failed to parse json: %s", err)
 		}
 		downloads[i].img = img
 
@@ -486,7 +627,7 @@ func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Wri
 
 		chunks := strings.SplitN(sumStr, ":", 2)
 		if len(chunks) < 2 {
-			return fmt.Errorf("expected 2 parts in the sumStr, got %#v", chunks)
+			return "", fmt.Errorf("expected 2 parts in the sumStr, got %#v", chunks)
 		}
 		sumType, checksum := chunks[0], chunks[1]
 		out.Write(sf.FormatProgress(utils.TruncateID(img.ID), "Pulling fs layer", nil))
@@ -536,7 +677,7 @@ func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Wri
 		} else {
 			err := downloadFunc(&downloads[i])
 			if err != nil {
-				return err
+				return "", err
 			}
 		}
 	}
@@ -546,7 +687,7 @@ func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Wri
 		if d.err != nil {
 			err := <-d.err
 			if err != nil {
-				return err
+				return "", err
 			}
 		}
 		if d.downloaded {
@@ -558,7 +699,7 @@ func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Wri
 				err = s.graph.Register(d.img, d.imgJSON,
 					utils.ProgressReader(d.tmpFile, int(d.length), out, sf, false, utils.TruncateID(d.img.ID), "Extracting"))
 				if err != nil {
-					return err
+					return "", err
 				}
 
 				// FIXME: Pool release here for parallel tag pull (ensures any downloads block until fully extracted)
@@ -571,9 +712,5 @@ func (s *TagStore) pullV2Tag(eng *engine.Engine, r *registry.Session, out io.Wri
 
 	}
 
-	if err = s.Set(localName, tag, downloads[0].img.ID, true); err != nil {
-		return err
-	}
-
-	return nil
+	return downloads[0].img.ID, nil
 }
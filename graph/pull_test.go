@@ -0,0 +1,288 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/registry"
+	"github.com/docker/docker/utils"
+	"github.com/docker/libtrust"
+)
+
+// v1TestRegistry serves the images of a v1 registry. The layers listed in
+// failingLayers are answered with a server error.
+type v1TestRegistry struct {
+	sync.Mutex
+	ancestry      map[string][]string
+	images        map[string][]byte
+	layers        map[string][]byte
+	failingLayers map[string]bool
+	layerPulls    []string
+}
+
+func (reg *v1TestRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.Lock()
+	defer reg.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/images/"), "/")
+	if len(parts) != 2 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	id := parts[0]
+	switch parts[1] {
+	case "ancestry":
+		json.NewEncoder(w).Encode(reg.ancestry[id])
+	case "json":
+		w.Write(reg.images[id])
+	case "layer":
+		reg.layerPulls = append(reg.layerPulls, id)
+		if reg.failingLayers[id] {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(reg.layers[id])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newV1TestRegistry(t *testing.T, failingLayers ...string) *v1TestRegistry {
+	reg := &v1TestRegistry{
+		ancestry: map[string][]string{
+			"pullbase": {"pullbase"},
+			"pulltop":  {"pulltop", "pullbase"},
+		},
+		images:        make(map[string][]byte),
+		layers:        make(map[string][]byte),
+		failingLayers: make(map[string]bool),
+	}
+	for _, img := range []*image.Image{{ID: "pullbase"}, {ID: "pulltop", Parent: "pullbase"}} {
+		jsonData, err := json.Marshal(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reg.images[img.ID] = jsonData
+		layer, err := layerTar("/" + img.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := new(bytes.Buffer)
+		if _, err := io.Copy(buf, layer); err != nil {
+			t.Fatal(err)
+		}
+		reg.layers[img.ID] = buf.Bytes()
+	}
+	for _, id := range failingLayers {
+		reg.failingLayers[id] = true
+	}
+	return reg
+}
+
+func TestPullImageLayerFallback(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestTagStore(tmp, t)
+
+	// the mirror fails to serve the top layer only
+	mirrorRegistry := newV1TestRegistry(t, "pulltop")
+	mirror := httptest.NewServer(mirrorRegistry)
+	defer mirror.Close()
+	originRegistry := newV1TestRegistry(t)
+	origin := httptest.NewServer(originRegistry)
+	defer origin.Close()
+
+	mirrorEndpoint, originEndpoint := mirror.URL+"/v1/", origin.URL+"/v1/"
+	store.mirrors = newMirrorSet([]string{mirrorEndpoint})
+
+	originURL, err := url.Parse(origin.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := registry.NewSession(&registry.AuthConfig{}, registry.HTTPRequestFactory(nil), &registry.Endpoint{URL: originURL, Version: registry.APIVersion1, Insecure: true}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sf := utils.NewStreamFormatter(false)
+	if _, err := store.pullImage(r, ioutil.Discard, "pulltop", "pulltop:latest", mirrorEndpoint, []string{originEndpoint}, nil, sf); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"pullbase", "pulltop"} {
+		if !store.graph.Exists(id) {
+			t.Fatalf("Expected the layer %s to be pulled", id)
+		}
+	}
+
+	if len(mirrorRegistry.layerPulls) != 2 {
+		t.Fatalf("Expected both layers to be asked from the mirror, got %v", mirrorRegistry.layerPulls)
+	}
+	if len(originRegistry.layerPulls) != 1 || originRegistry.layerPulls[0] != "pulltop" {
+		t.Fatalf("Expected only the layer the mirror failed to serve from the origin, got %v", originRegistry.layerPulls)
+	}
+	if !store.mirrors.isDown(mirrorEndpoint) {
+		t.Fatal("Expected the mirror answering 503 to be backed off from")
+	}
+	// the pull completed by the origin isn't credited to the mirror
+	if status := store.mirrors.status()[0]; status.Failures != 1 || status.RetryAt.IsZero() || status.Healthy || status.LastPull != "" {
+		t.Fatalf("Expected the failure of the mirror to be kept, got %+v", status)
+	}
+}
+
+func TestPullImageFromMirror(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestTagStore(tmp, t)
+
+	mirror := httptest.NewServer(newV1TestRegistry(t))
+	defer mirror.Close()
+	origin := httptest.NewServer(newV1TestRegistry(t))
+	defer origin.Close()
+
+	mirrorEndpoint := mirror.URL + "/v1/"
+	store.mirrors = newMirrorSet([]string{mirrorEndpoint})
+
+	originURL, err := url.Parse(origin.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := registry.NewSession(&registry.AuthConfig{}, registry.HTTPRequestFactory(nil), &registry.Endpoint{URL: originURL, Version: registry.APIVersion1, Insecure: true}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sf := utils.NewStreamFormatter(false)
+	if _, err := store.pullImage(r, ioutil.Discard, "pulltop", "pulltop:latest", mirrorEndpoint, []string{origin.URL + "/v1/"}, nil, sf); err != nil {
+		t.Fatal(err)
+	}
+	if status := store.mirrors.status()[0]; !status.Healthy || status.LastPull != "pulltop:latest" {
+		t.Fatalf("Expected the pull to be credited to the mirror, got %+v", status)
+	}
+}
+
+func TestPullV2Digest(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	pusher := mkTestTagStore(filepath.Join(tmp, "pusher"), t)
+	if pusher.trustKey, err = libtrust.GenerateECP256PrivateKey(); err != nil {
+		t.Fatal(err)
+	}
+	puller := mkTestTagStore(filepath.Join(tmp, "puller"), t)
+
+	reg := newV2TestRegistry()
+	server := httptest.NewServer(reg)
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := registry.NewSession(&registry.AuthConfig{}, registry.HTTPRequestFactory(nil), &registry.Endpoint{URL: serverURL, Version: registry.APIVersion2, Insecure: true}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localName := serverURL.Host + "/myapp"
+	sf := utils.NewStreamFormatter(false)
+
+	layer, err := layerTar("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pusher.graph.Register(&image.Image{ID: "bar"}, nil, layer); err != nil {
+		t.Fatal(err)
+	}
+	if err := pusher.Set(localName, DEFAULTTAG, "bar", false); err != nil {
+		t.Fatal(err)
+	}
+	localRepo, err := pusher.Get(localName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pusher.pushV2Repository(r, ioutil.Discard, localName, "myapp", localRepo, DEFAULTTAG, sf); err != nil {
+		t.Fatal(err)
+	}
+
+	// the digest of the image is the one of the manifest the registry serves
+	reg.Lock()
+	js, err := libtrust.ParsePrettySignature(reg.manifests["myapp/"+DEFAULTTAG], "signatures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := js.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest registry.ManifestData
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := manifestDigest(&manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a tag with a broken manifest doesn't stop the search
+	reg.manifests["myapp/broken"] = []byte("not a manifest")
+	reg.Unlock()
+
+	eng := engine.New()
+	eng.Logging = false
+	eng.Register("trust_key_check", func(job *engine.Job) engine.Status {
+		job.Stdout.Write([]byte("not verified\n"))
+		return engine.StatusOK
+	})
+	if err := puller.pullV2Repository(eng, r, ioutil.Discard, localName, "myapp", digest, sf, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if img, err := puller.GetImageByDigest(localName, digest); err != nil || img.ID != "bar" {
+		t.Fatalf("Expected to find bar by its digest, got %v (%v)", img, err)
+	}
+	// the pull by digest doesn't tag the image
+	if img, err := puller.GetImage(localName, DEFAULTTAG); err != nil || img != nil {
+		t.Fatalf("Expected %s:%s not to be tagged, got %v (%v)", localName, DEFAULTTAG, img, err)
+	}
+	// but keeps a reference to it, so it isn't dangling
+	if err := puller.Install(eng); err != nil {
+		t.Fatal(err)
+	}
+	job := eng.Job("images")
+	job.Setenv("filters", `{"dangling":["true"]}`)
+	dangling, err := job.Stdout.AddListTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := job.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range dangling.Data {
+		if out.Get("Id") == "bar" {
+			t.Fatal("Expected the image pulled by digest not to be dangling")
+		}
+	}
+	if err := puller.DeleteAll("bar"); err != nil {
+		t.Fatal(err)
+	}
+	if len(puller.Digests) != 0 {
+		t.Fatalf("Expected the reference to the deleted image to be removed, got %v", puller.Digests)
+	}
+}
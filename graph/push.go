@@ -1,19 +1,29 @@
 package graph
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/log"
+	"github.com/docker/docker/pkg/tarsum"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/utils"
+	"github.com/docker/libtrust"
 )
 
+// ErrV2RegistryUnavailable is returned by pushV2Repository when the endpoint
+// does not speak the v2 protocol, in which case the push falls back to v1.
+var ErrV2RegistryUnavailable = errors.New("error v2 registry unavailable")
+
 // Retrieve the all the images to be uploaded in the correct order
 func (s *TagStore) getImageList(localRepo map[string]string, requestedTag string) ([]string, map[string][]string, error) {
 	var (
@@ -188,6 +198,155 @@ func (s *TagStore) pushImage(r *registry.Session, out io.Writer, remote, imgID,
 	return imgData.Checksum, nil
 }
 
+func (s *TagStore) pushV2Repository(r *registry.Session, out io.Writer, localName, remoteName string, localRepo map[string]string, tag string, sf *utils.StreamFormatter) error {
+	if _, err := r.GetV2Version(nil); err != nil {
+		log.Debugf("Registry does not support v2 protocol: %s", err)
+		return ErrV2RegistryUnavailable
+	}
+
+	var tags []string
+	if tag == "" {
+		for t := range localRepo {
+			tags = append(tags, t)
+		}
+	} else {
+		if _, exists := localRepo[tag]; !exists {
+			return fmt.Errorf("Tag does not exist: %s", tag)
+		}
+		tags = []string{tag}
+	}
+	if len(tags) == 0 {
+		return fmt.Errorf("No tags to push for %s", localName)
+	}
+
+	// Layers shared between tags are only checksummed and uploaded once
+	pushed := make(map[string]string)
+	for _, tag := range tags {
+		if err := s.pushV2Tag(r, out, localName, remoteName, localRepo[tag], tag, pushed, sf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TagStore) pushV2Tag(r *registry.Session, out io.Writer, localName, remoteName, imgID, tag string, pushed map[string]string, sf *utils.StreamFormatter) error {
+	if s.trustKey == nil {
+		return fmt.Errorf("no trust key to sign the manifest with")
+	}
+	out.Write(sf.FormatStatus(tag, "Pushing %s to v2 registry", localName))
+
+	img, err := s.graph.Get(imgID)
+	if err != nil {
+		return err
+	}
+	layers, err := img.History()
+	if err != nil {
+		return err
+	}
+
+	// The manifest lists layers from the tagged image down to the base
+	// image, which is the order pullV2Tag expects.
+	manifest := &registry.ManifestData{
+		Name:          remoteName,
+		Tag:           tag,
+		Architecture:  img.Architecture,
+		BlobSums:      make([]string, len(layers)),
+		History:       make([]string, len(layers)),
+		SchemaVersion: 1,
+	}
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+		jsonData, err := layer.RawJson()
+		if err != nil {
+			return fmt.Errorf("cannot retrieve the json of %s: %s", layer.ID, err)
+		}
+		manifest.History[i] = string(jsonData)
+
+		checksum, exists := pushed[layer.ID]
+		if !exists {
+			if checksum, err = s.pushV2Image(r, out, remoteName, layer.ID, sf); err != nil {
+				return err
+			}
+			pushed[layer.ID] = checksum
+		}
+		manifest.BlobSums[i] = checksum
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "   ")
+	if err != nil {
+		return err
+	}
+	js, err := libtrust.NewJSONSignature(manifestBytes)
+	if err != nil {
+		return err
+	}
+	if err := js.Sign(s.trustKey); err != nil {
+		return err
+	}
+	signedBody, err := js.PrettySignature("signatures")
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Pushing v2 manifest for %s:%s", remoteName, tag)
+	if err := r.PutV2ImageManifest(remoteName, tag, bytes.NewReader(signedBody), nil); err != nil {
+		return err
+	}
+	out.Write(sf.FormatStatus(tag, "Manifest successfully pushed"))
+	return nil
+}
+
+// pushV2Image uploads the layer of imgID as a tarsum-addressed blob, unless
+// the registry can mount an identical blob it already has. It returns the
+// blob's checksum in "sumtype:sum" form.
+func (s *TagStore) pushV2Image(r *registry.Session, out io.Writer, remoteName, imgID string, sf *utils.StreamFormatter) (string, error) {
+	out = utils.NewWriteFlusher(out)
+	out.Write(sf.FormatProgress(utils.TruncateID(imgID), "Preparing", nil))
+
+	layerData, err := s.graph.TempLayerArchive(imgID, archive.Uncompressed, sf, out)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate layer archive: %s", err)
+	}
+	defer os.RemoveAll(layerData.Name())
+
+	ts, err := tarsum.NewTarSum(layerData, true, tarsum.Version0)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(ioutil.Discard, ts); err != nil {
+		return "", err
+	}
+	checksum := ts.Sum(nil)
+	sumParts := strings.SplitN(checksum, ":", 2)
+	if len(sumParts) < 2 {
+		return "", fmt.Errorf("Invalid checksum: %s", checksum)
+	}
+
+	mounted, err := r.PostV2ImageMountBlob(remoteName, sumParts[0], sumParts[1], nil)
+	if err != nil {
+		return "", err
+	}
+	if mounted {
+		out.Write(sf.FormatProgress(utils.TruncateID(imgID), "Image already exists", nil))
+		return checksum, nil
+	}
+
+	if _, err := layerData.Seek(0, 0); err != nil {
+		return "", err
+	}
+	serverChecksum, err := r.PutV2ImageBlob(remoteName, sumParts[0], utils.ProgressReader(layerData, int(layerData.Size), out, sf, false, utils.TruncateID(imgID), "Pushing"), nil)
+	if err != nil {
+		return "", err
+	}
+	if serverChecksum != checksum {
+		return "", fmt.Errorf("Checksum mismatch for %s: registry computed %s, expected %s", utils.TruncateID(imgID), serverChecksum, checksum)
+	}
+
+	out.Write(sf.FormatProgress(utils.TruncateID(imgID), "Image successfully pushed", nil))
+	return checksum, nil
+}
+
 // FIXME: Allow to interrupt current push when new push of same image is done.
 func (s *TagStore) CmdPush(job *engine.Job) engine.Status {
 	if n := len(job.Args); n != 1 {
@@ -214,7 +373,7 @@ func (s *TagStore) CmdPush(job *engine.Job) engine.Status {
 		return job.Error(err)
 	}
 
-	endpoint, err := registry.NewEndpoint(hostname)
+	endpoint, err := registry.NewEndpoint(hostname, s.insecureRegistries)
 	if err != nil {
 		return job.Error(err)
 	}
@@ -233,6 +392,14 @@ func (s *TagStore) CmdPush(job *engine.Job) engine.Status {
 		job.Stdout.Write(sf.FormatStatus("", "The push refers to a repository [%s] (len: %d)", localName, reposLen))
 		// If it fails, try to get the repository
 		if localRepo, exists := s.Repositories[localName]; exists {
+			err := s.pushV2Repository(r, job.Stdout, localName, remoteName, localRepo, tag, sf)
+			if err == nil {
+				return engine.StatusOK
+			}
+			if err != ErrV2RegistryUnavailable {
+				return job.Errorf("Error pushing to registry: %s", err)
+			}
+			log.Debugf("Falling back to v1 push for %s", localName)
 			if err := s.pushRepository(r, job.Stdout, localName, remoteName, localRepo, tag, sf); err != nil {
 				return job.Error(err)
 			}
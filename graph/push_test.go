@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/tarsum"
+	"github.com/docker/docker/registry"
+	"github.com/docker/docker/utils"
+	"github.com/docker/libtrust"
+)
+
+// v2TestRegistry is a registry speaking the v2 protocol, which keeps the
+// blobs and manifests pushed to it.
+type v2TestRegistry struct {
+	sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	v1Calls   []string
+}
+
+func newV2TestRegistry() *v2TestRegistry {
+	return &v2TestRegistry{blobs: make(map[string][]byte), manifests: make(map[string][]byte)}
+}
+
+func (reg *v2TestRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.Lock()
+	defer reg.Unlock()
+
+	switch {
+	case r.URL.Path == "/v1/_ping":
+		w.Write([]byte("{}"))
+	case strings.HasPrefix(r.URL.Path, "/v1/"):
+		reg.v1Calls = append(reg.v1Calls, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	case r.URL.Path == "/v2/version":
+		w.Write([]byte("{}"))
+	case strings.HasPrefix(r.URL.Path, "/v2/mountblob/"):
+		w.WriteHeader(300)
+	case strings.HasPrefix(r.URL.Path, "/v2/blob/") && r.Method == "PUT":
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ts, err := tarsum.NewTarSum(bytes.NewReader(body), true, tarsum.Version0)
+		if err == nil {
+			_, err = io.Copy(ioutil.Discard, ts)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reg.blobs[ts.Sum(nil)] = body
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"checksum": ts.Sum(nil)})
+	case strings.HasPrefix(r.URL.Path, "/v2/blob/"):
+		// /v2/blob/<name>/<sumtype>/<sum>
+		parts := strings.Split(r.URL.Path, "/")
+		blob, exists := reg.blobs[parts[len(parts)-2]+":"+parts[len(parts)-1]]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+		w.Write(blob)
+	case strings.HasPrefix(r.URL.Path, "/v2/manifest/") && r.Method == "PUT":
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reg.manifests[strings.TrimPrefix(r.URL.Path, "/v2/manifest/")] = body
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(r.URL.Path, "/v2/manifest/"):
+		manifest, exists := reg.manifests[strings.TrimPrefix(r.URL.Path, "/v2/manifest/")]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(manifest)
+	case strings.HasPrefix(r.URL.Path, "/v2/tags/"):
+		prefix := strings.TrimPrefix(r.URL.Path, "/v2/tags/") + "/"
+		tags := []string{}
+		for name := range reg.manifests {
+			if strings.HasPrefix(name, prefix) {
+				tags = append(tags, strings.TrimPrefix(name, prefix))
+			}
+		}
+		sort.Strings(tags)
+		json.NewEncoder(w).Encode(tags)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestPushV2(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestTagStore(tmp, t)
+	if store.trustKey, err = libtrust.GenerateECP256PrivateKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := newV2TestRegistry()
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	// a registry on the loopback network falls back to HTTP
+	localName := strings.TrimPrefix(server.URL, "http://") + "/myapp"
+	if err := store.Set(localName, DEFAULTTAG, testImageID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	eng := engine.New()
+	eng.Logging = false
+	if err := store.Install(eng); err != nil {
+		t.Fatal(err)
+	}
+	job := eng.Job("push", localName)
+	job.Setenv("tag", DEFAULTTAG)
+	if err := job.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	reg.Lock()
+	defer reg.Unlock()
+	if len(reg.v1Calls) != 0 {
+		t.Fatalf("Expected the push not to fall back to v1, got %v", reg.v1Calls)
+	}
+	signed, exists := reg.manifests["myapp/"+DEFAULTTAG]
+	if !exists {
+		t.Fatalf("Expected a manifest for myapp:%s, got %v", DEFAULTTAG, reg.manifests)
+	}
+	js, err := libtrust.ParsePrettySignature(signed, "signatures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := js.Verify(); err != nil {
+		t.Fatalf("Invalid manifest signature: %s", err)
+	}
+	payload, err := js.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest registry.ManifestData
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != "myapp" || manifest.Tag != DEFAULTTAG || len(manifest.BlobSums) != 1 || reg.blobs[manifest.BlobSums[0]] == nil {
+		t.Fatalf("Unexpected manifest %s", payload)
+	}
+}
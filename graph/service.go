@@ -138,6 +138,7 @@ func (s *TagStore) CmdLookup(job *engine.Job) engine.Status {
 
 		out := &engine.Env{}
 		out.Set("Id", image.ID)
+		out.Set("Digest", s.imageDigest(image))
 		out.Set("Parent", image.Parent)
 		out.Set("Comment", image.Comment)
 		out.SetAuto("Created", image.Created)
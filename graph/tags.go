@@ -14,6 +14,7 @@ import (
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/utils"
+	"github.com/docker/libtrust"
 )
 
 const DEFAULTTAG = "latest"
@@ -23,10 +24,13 @@ var (
 )
 
 type TagStore struct {
-	path         string
-	graph        *Graph
-	mirrors      []string
-	Repositories map[string]Repository
+	path               string
+	graph              *Graph
+	mirrors            *mirrorSet
+	insecureRegistries []string
+	trustKey           libtrust.PrivateKey
+	Repositories       map[string]Repository
+	Digests            map[string]Repository // The images pulled by digest, by repository and digest
 	sync.Mutex
 	// FIXME: move push/pull-related fields
 	// to a helper type
@@ -54,18 +58,21 @@ func (r Repository) Contains(u Repository) bool {
 	return true
 }
 
-func NewTagStore(path string, graph *Graph, mirrors []string) (*TagStore, error) {
+func NewTagStore(path string, graph *Graph, mirrors []string, insecureRegistries []string, key libtrust.PrivateKey) (*TagStore, error) {
 	abspath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
 	}
 	store := &TagStore{
-		path:         abspath,
-		graph:        graph,
-		mirrors:      mirrors,
-		Repositories: make(map[string]Repository),
-		pullingPool:  make(map[string]chan struct{}),
-		pushingPool:  make(map[string]chan struct{}),
+		path:               abspath,
+		graph:              graph,
+		mirrors:            newMirrorSet(mirrors),
+		insecureRegistries: insecureRegistries,
+		trustKey:           key,
+		Repositories:       make(map[string]Repository),
+		Digests:            make(map[string]Repository),
+		pullingPool:        make(map[string]chan struct{}),
+		pushingPool:        make(map[string]chan struct{}),
 	}
 	// Load the json file if it exists, otherwise create it.
 	if err := store.reload(); os.IsNotExist(err) {
@@ -105,6 +112,9 @@ func (store *TagStore) LookupImage(name string) (*image.Image, error) {
 	// FIXME: standardize on returning nil when the image doesn't exist, and err for everything else
 	// (so we can pass all errors here)
 	repos, tag := parsers.ParseRepositoryTag(name)
+	if IsDigest(tag) {
+		return store.GetImageByDigest(repos, tag)
+	}
 	if tag == "" {
 		tag = DEFAULTTAG
 	}
@@ -149,6 +159,9 @@ func (store *TagStore) ImageName(id string) string {
 }
 
 func (store *TagStore) DeleteAll(id string) error {
+	if err := store.deleteDigests(id); err != nil {
+		return err
+	}
 	names, exists := store.ByID()[id]
 	if !exists || len(names) == 0 {
 		return nil
@@ -229,6 +242,44 @@ func (store *TagStore) Set(repoName, tag, imageName string, force bool) error {
 	return store.save()
 }
 
+// SetDigest records that the image with the given ID was pulled by its
+// digest from the repository repoName. Like a tag, the reference keeps the
+// image from being dangling.
+func (store *TagStore) SetDigest(repoName, digest, id string) error {
+	store.Lock()
+	defer store.Unlock()
+	if err := store.reload(); err != nil {
+		return err
+	}
+	repo, exists := store.Digests[repoName]
+	if !exists {
+		repo = make(Repository)
+		store.Digests[repoName] = repo
+	}
+	repo[digest] = id
+	return store.save()
+}
+
+// deleteDigests removes the references to the image id by its digest.
+func (store *TagStore) deleteDigests(id string) error {
+	store.Lock()
+	defer store.Unlock()
+	if err := store.reload(); err != nil {
+		return err
+	}
+	for repoName, repo := range store.Digests {
+		for digest, imgID := range repo {
+			if imgID == id {
+				delete(repo, digest)
+			}
+		}
+		if len(repo) == 0 {
+			delete(store.Digests, repoName)
+		}
+	}
+	return store.save()
+}
+
 func (store *TagStore) Get(repoName string) (Repository, error) {
 	store.Lock()
 	defer store.Unlock()
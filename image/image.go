@@ -1,8 +1,10 @@
 package image
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/log"
+	"github.com/docker/docker/pkg/tarsum"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
@@ -32,11 +35,26 @@ type Image struct {
 	Config          *runconfig.Config `json:"config,omitempty"`
 	Architecture    string            `json:"architecture,omitempty"`
 	OS              string            `json:"os,omitempty"`
+	// The images merged into the layer of a squashed image, newest first
+	SquashedHistory []*HistoryEntry `json:"squashed_history,omitempty"`
 	Size            int64
+	// The tarsum of the layer, empty for the images registered before the
+	// layers were checksummed
+	Tarsum string `json:"-"`
 
 	graph Graph
 }
 
+// HistoryEntry keeps how an image that was squashed into another image was
+// created
+type HistoryEntry struct {
+	ID        string    `json:"id"`
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+}
+
 func LoadImage(root string) (*Image, error) {
 	// Load the json data
 	jsonData, err := ioutil.ReadFile(jsonPath(root))
@@ -67,6 +85,14 @@ func LoadImage(root string) (*Image, error) {
 		img.Size = int64(size)
 	}
 
+	if buf, err := ioutil.ReadFile(path.Join(root, "tarsum")); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	} else {
+		img.Tarsum = string(buf)
+	}
+
 	return img, nil
 }
 
@@ -78,18 +104,43 @@ func StoreImage(img *Image, jsonData []byte, layerData archive.ArchiveReader, ro
 		driver = img.graph.Driver()
 	)
 
+	// The layer is checksummed while it is unpacked, a nil layer is
+	// checksummed as an empty archive
+	var layer io.Reader = bytes.NewReader(nil)
+	if layerData != nil {
+		decompressed, err := archive.DecompressStream(layerData)
+		if err != nil {
+			return err
+		}
+		defer decompressed.Close()
+		layer = decompressed
+	}
+	ts, err := tarsum.NewTarSum(layer, true, tarsum.Version0)
+	if err != nil {
+		return err
+	}
+
 	// If layerData is not nil, unpack it into the new layer
 	if layerData != nil {
-		if size, err = driver.ApplyDiff(img.ID, img.Parent, layerData); err != nil {
+		if size, err = driver.ApplyDiff(img.ID, img.Parent, ts); err != nil {
 			return err
 		}
 	}
+	// the end of the archive may be left unread
+	if _, err := io.Copy(ioutil.Discard, ts); err != nil {
+		return err
+	}
 
 	img.Size = size
 	if err := img.SaveSize(root); err != nil {
 		return err
 	}
 
+	img.Tarsum = ts.Sum(nil)
+	if err := ioutil.WriteFile(path.Join(root, "tarsum"), []byte(img.Tarsum), 0600); err != nil {
+		return fmt.Errorf("Error storing image tarsum in %s/tarsum: %s", root, err)
+	}
+
 	// If raw json is provided, then use it
 	if jsonData != nil {
 		if err := ioutil.WriteFile(jsonPath(root), jsonData, 0600); err != nil {
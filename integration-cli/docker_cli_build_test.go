@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -2423,3 +2424,206 @@ func TestBuildCmdJSONNoShDashC(t *testing.T) {
 
 	logDone("build - cmd should not have /bin/sh -c for json")
 }
+
+func TestBuildArg(t *testing.T) {
+	name := "testbuildarg"
+	defer deleteImages(name)
+	dockerfile := `FROM busybox
+ARG FOO
+ARG BAR=bar
+ENV BAZ $FOO-$BAR
+RUN [ "$FOO" = "foo" ] && [ "$BAR" = "bar" ]`
+
+	buildCmd := exec.Command(dockerBinary, "build", "-t", name, "--build-arg", "FOO=foo", "-")
+	buildCmd.Stdin = strings.NewReader(dockerfile)
+	if out, _, err := runCommandWithOutput(buildCmd); err != nil {
+		t.Fatalf("build failed to complete: %s, %v", out, err)
+	}
+
+	res, err := inspectField(name, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(res, "FOO=") || strings.Contains(res, "BAR=") {
+		t.Fatalf("build-time variables should not be kept in Config.Env: %s", res)
+	}
+	if !strings.Contains(res, "BAZ=foo-bar") {
+		t.Fatalf("expected BAZ=foo-bar in Config.Env: %s", res)
+	}
+
+	logDone("build - build-time variables are available to RUN and expansion but not persisted")
+}
+
+func TestBuildArgNotConsumed(t *testing.T) {
+	name := "testbuildargnotconsumed"
+	defer deleteImages(name)
+
+	buildCmd := exec.Command(dockerBinary, "build", "-t", name, "--build-arg", "FOO=foo", "-")
+	buildCmd.Stdin = strings.NewReader("FROM busybox\nRUN true")
+	out, _, err := runCommandWithOutput(buildCmd)
+	if err == nil {
+		t.Fatal("expected build to fail with an undeclared build-arg")
+	}
+	if !strings.Contains(out, "were not consumed") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	logDone("build - undeclared build-time variables fail the build")
+}
+
+func TestBuildMultiStage(t *testing.T) {
+	name := "testbuildmultistage"
+	defer deleteImages(name)
+	_, err := buildImage(name,
+		`FROM busybox AS first
+RUN mkdir /out && echo hello > /out/file
+ENV FIRST 1
+
+FROM busybox
+COPY --from=first /out/file /copied
+COPY --from=0 /out /dir/
+RUN [ "$(cat /copied)" = "hello" ] && [ "$(cat /dir/file)" = "hello" ]`,
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := inspectField(name, "Config.Env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(res, "FIRST") {
+		t.Fatalf("config of an earlier stage leaked into the final image: %s", res)
+	}
+
+	logDone("build - multi-stage build with COPY --from")
+}
+
+func TestBuildMultiStageDuplicateName(t *testing.T) {
+	name := "testbuildmultistageduplicatename"
+	defer deleteImages(name)
+	_, out, err := buildImageWithOut(name,
+		`FROM busybox AS stage
+FROM busybox AS stage`,
+		true)
+	if err == nil || !strings.Contains(out, "Duplicate build stage name") {
+		t.Fatalf("expected duplicate stage name to fail the build: %v %s", err, out)
+	}
+
+	logDone("build - multi-stage build rejects duplicate stage names")
+}
+
+func TestBuildHealthcheck(t *testing.T) {
+	name := "testbuildhealthcheck"
+	defer deleteImages(name)
+	_, err := buildImage(name,
+		`FROM busybox
+		HEALTHCHECK --interval=5s --timeout=3s --retries=2 CMD cat /etc/hostname`,
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := inspectFieldJSON(name, "Config.Healthcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Test":["CMD-SHELL","cat /etc/hostname"],"Interval":5000000000,"Timeout":3000000000,"Retries":2}`
+	if res != expected {
+		t.Fatalf("expected healthcheck %s, got %s", expected, res)
+	}
+
+	_, err = buildImage(name,
+		fmt.Sprintf(`FROM %s
+		HEALTHCHECK NONE`, name),
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = inspectFieldJSON(name, "Config.Healthcheck.Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != `["NONE"]` {
+		t.Fatalf("expected the healthcheck to be disabled, got %s", res)
+	}
+	logDone("build - healthcheck")
+}
+
+func TestBuildStopSignal(t *testing.T) {
+	name := "testbuildstopsignal"
+	defer deleteImages(name)
+	_, err := buildImage(name,
+		`FROM busybox
+		STOPSIGNAL SIGKILL`,
+		true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := inspectField(name, "Config.StopSignal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "SIGKILL" {
+		t.Fatalf("Signal %s, expected SIGKILL", res)
+	}
+
+	_, err = buildImage(name,
+		`FROM busybox
+		STOPSIGNAL SIGNOTASIGNAL`,
+		true)
+	if err == nil || !strings.Contains(err.Error(), "Invalid signal: SIGNOTASIGNAL") {
+		t.Fatalf("expected an invalid signal error, got %v", err)
+	}
+	logDone("build - stopsignal")
+}
+
+func TestBuildSquash(t *testing.T) {
+	name := "testbuildsquash"
+	defer deleteImages(name)
+
+	buildCmd := exec.Command(dockerBinary, "build", "--squash", "-t", name, "-")
+	buildCmd.Stdin = strings.NewReader(`FROM busybox
+		RUN dd if=/dev/zero of=/bigfile bs=1024 count=1024
+		RUN rm /bigfile
+		RUN echo squashed > /file`)
+	if out, _, err := runCommandWithOutput(buildCmd); err != nil {
+		t.Fatal(err, out)
+	}
+
+	busyboxID, err := getIDByName("busybox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := inspectField(name, "Parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent != busyboxID {
+		t.Fatalf("expected the squashed image on top of busybox %s, got %s", busyboxID, parent)
+	}
+
+	// the squashed layer has neither the big file nor its deletion
+	size, err := inspectField(name, "Size")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := strconv.Atoi(size); err != nil || n > 100*1024 {
+		t.Fatalf("expected a small squashed layer, got %s", size)
+	}
+
+	out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "run", "--rm", name, "cat", "/file"))
+	if err != nil || strings.TrimSpace(out) != "squashed" {
+		t.Fatalf("expected the file of the last step in the image, got %v: %s", err, out)
+	}
+
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "history", "--no-trunc", name))
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	if !strings.Contains(out, "rm /bigfile") || !strings.Contains(out, "<missing>") {
+		t.Fatalf("expected the squashed steps in the history, got %s", out)
+	}
+
+	logDone("build - squash")
+}
@@ -137,3 +137,48 @@ func TestCommitWithHostBindMount(t *testing.T) {
 
 	logDone("commit - commit bind mounted file")
 }
+
+func TestCommitSquash(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "--name", "squash", "busybox", "touch", "/foo")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(err, out)
+	}
+	defer deleteAllContainers()
+
+	commitCmd := exec.Command(dockerBinary, "commit", "--squash", "squash", "squashed")
+	out, _, err := runCommandWithOutput(commitCmd)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	defer deleteImages("squashed")
+
+	parent, err := inspectField("squashed", "Parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent != "" {
+		t.Fatalf("expected the squashed image to have no parent, got %s", parent)
+	}
+
+	// the layers of busybox are flattened too, and kept in the history
+	historyCmd := exec.Command(dockerBinary, "history", "-q", "busybox")
+	baseHistory, _, err := runCommandWithOutput(historyCmd)
+	if err != nil {
+		t.Fatal(err, baseHistory)
+	}
+	historyCmd = exec.Command(dockerBinary, "history", "-q", "squashed")
+	history, _, err := runCommandWithOutput(historyCmd)
+	if err != nil {
+		t.Fatal(err, history)
+	}
+	if missing, base := strings.Count(history, "<missing>"), len(strings.Fields(baseHistory)); missing != base {
+		t.Fatalf("expected the %d layers of busybox in the history of the squashed image, got %d:\n%s", base, missing, history)
+	}
+
+	runCmd = exec.Command(dockerBinary, "run", "--rm", "squashed", "ls", "/foo", "/bin/sh")
+	if out, _, err := runCommandWithOutput(runCmd); err != nil {
+		t.Fatal(err, out)
+	}
+
+	logDone("commit - squash the image into one layer")
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -371,3 +372,91 @@ func TestCpUnprivilegedUser(t *testing.T) {
 
 	logDone("cp - unprivileged user")
 }
+
+// Check that files can be copied into a container, and that the copy can't
+// escape the container's rootfs through a symlink
+func TestCpToContainer(t *testing.T) {
+	out, exitCode, err := cmd(t, "run", "-d", "busybox", "/bin/sh", "-c", "mkdir -p '"+cpTestPath+"' && ln -s "+cpTestPathParent+" /escape")
+	if err != nil || exitCode != 0 {
+		t.Fatal("failed to create a container", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	out, _, err = cmd(t, "wait", cleanedContainerID)
+	if err != nil || stripTrailingCharacters(out) != "0" {
+		t.Fatal("failed to set up container", out, err)
+	}
+
+	tmpdir, err := ioutil.TempDir("", "docker-integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	hostFile := filepath.Join(tmpdir, cpTestName)
+	if err := ioutil.WriteFile(hostFile, []byte(cpHostContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// into an existing directory, and through a symlink to a new name
+	if _, _, err := cmd(t, "cp", hostFile, cleanedContainerID+":"+cpTestPath); err != nil {
+		t.Fatalf("couldn't copy to the container: %s", err)
+	}
+	if _, _, err := cmd(t, "cp", hostFile, cleanedContainerID+":/escape/renamed"); err != nil {
+		t.Fatalf("couldn't copy to the container: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(cpTestPathParent, "renamed")); !os.IsNotExist(err) {
+		t.Fatalf("copy escaped the container's rootfs: %v", err)
+	}
+
+	outDir := filepath.Join(tmpdir, "out")
+	for _, path := range []string{cpFullPath, cpTestPathParent + "/renamed"} {
+		if _, _, err := cmd(t, "cp", cleanedContainerID+":"+path, outDir); err != nil {
+			t.Fatalf("couldn't copy %s back from the container: %s", path, err)
+		}
+		content, err := ioutil.ReadFile(filepath.Join(outDir, filepath.Base(path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != cpHostContents {
+			t.Fatalf("expected %q in %s, got %q", cpHostContents, path, content)
+		}
+	}
+
+	logDone("cp - copy from the host to a container")
+}
+
+// Check that nothing can be copied into the root filesystem of a container
+// run with --read-only, but its volumes stay writable
+func TestCpToReadOnlyContainer(t *testing.T) {
+	out, exitCode, err := cmd(t, "run", "-d", "--read-only", "-v", "/data", "busybox", "true")
+	if err != nil || exitCode != 0 {
+		t.Fatal("failed to create a container", out, err)
+	}
+
+	cleanedContainerID := stripTrailingCharacters(out)
+	defer deleteContainer(cleanedContainerID)
+
+	tmpdir, err := ioutil.TempDir("", "docker-integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	hostFile := filepath.Join(tmpdir, cpTestName)
+	if err := ioutil.WriteFile(hostFile, []byte(cpHostContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, err = runCommandWithOutput(exec.Command(dockerBinary, "cp", hostFile, cleanedContainerID+":/tmp"))
+	if err == nil || !strings.Contains(out, "read-only") {
+		t.Fatalf("expected the copy to the read-only rootfs to fail, got %q (%v)", out, err)
+	}
+	if _, _, err := cmd(t, "cp", hostFile, cleanedContainerID+":/data"); err != nil {
+		t.Fatalf("couldn't copy to the volume: %s", err)
+	}
+
+	logDone("cp - copy from the host to a read-only container")
+}
@@ -48,3 +48,31 @@ func TestDaemonRestartWithRunningContainersPorts(t *testing.T) {
 
 	logDone("daemon - running containers on daemon restart")
 }
+
+func TestDaemonUlimitDefaults(t *testing.T) {
+	d := NewDaemon(t)
+	if err := d.StartWithBusybox("--default-ulimit", "nofile=42:42", "--default-ulimit", "nproc=1024:1024"); err != nil {
+		t.Fatalf("Could not start daemon with busybox: %v", err)
+	}
+	defer d.Stop()
+
+	out, err := d.Cmd("run", "--ulimit", "nproc=2048", "busybox:latest", "/bin/sh", "-c", "ulimit -p && ulimit -n")
+	if err != nil {
+		t.Fatalf("Could not run container: err=%v\n%s", err, out)
+	}
+
+	outArr := strings.Split(strings.TrimSpace(out), "\n")
+	if len(outArr) < 2 {
+		t.Fatalf("got unexpected output: %s", out)
+	}
+	nproc, nofile := outArr[0], outArr[1]
+
+	if nofile != "42" {
+		t.Fatalf("expected `ulimit -n` to be `42`, got: %s", nofile)
+	}
+	if nproc != "2048" {
+		t.Fatalf("expected `ulimit -p` to be 2048, got: %s", nproc)
+	}
+
+	logDone("daemon - default ulimits are applied")
+}
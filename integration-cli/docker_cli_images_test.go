@@ -60,3 +60,38 @@ func TestImagesOrderedByCreationDate(t *testing.T) {
 
 	logDone("images - ordering by creation date")
 }
+
+func TestImagesDigests(t *testing.T) {
+	defer deleteImages("digest:test")
+	id, err := buildImage("digest:test",
+		`FROM scratch
+		MAINTAINER dockerio`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := inspectField("digest:test", "Digest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(digest, "sha256:") {
+		t.Fatalf("Expected a sha256 digest, got %q", digest)
+	}
+
+	out, _, err := runCommandWithOutput(exec.Command(dockerBinary, "images", "--digests", "--no-trunc", "digest"))
+	errorOut(err, t, fmt.Sprintf("listing images failed with errors: %v", err))
+	if !strings.Contains(out, "DIGEST") || !strings.Contains(out, digest) {
+		t.Fatalf("Expected the digest %s to be listed, got %s", digest, out)
+	}
+
+	// the image can be referred to by its digest
+	digestID, err := inspectField("digest@"+digest, "Id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digestID != id {
+		t.Fatalf("Expected digest@%s to be %s, got %s", digest, id, digestID)
+	}
+
+	logDone("images - digests are listed and can be used as references")
+}
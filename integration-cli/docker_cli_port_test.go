@@ -104,6 +104,40 @@ func TestPortList(t *testing.T) {
 	logDone("port - test port list")
 }
 
+func TestPortRange(t *testing.T) {
+	defer deleteAllContainers()
+
+	runCmd := exec.Command(dockerBinary, "run", "-d", "-p", "9800-9802:80-82/udp", "busybox", "top")
+	out, _, err := runCommandWithOutput(runCmd)
+	errorOut(err, t, out)
+	ID := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "port", ID)
+	out, _, err = runCommandWithOutput(runCmd)
+	errorOut(err, t, out)
+
+	if !assertPortList(t, out, []string{
+		"80/udp -> 0.0.0.0:9800",
+		"81/udp -> 0.0.0.0:9801",
+		"82/udp -> 0.0.0.0:9802"}) {
+		t.Error("Port list is not correct\n", out)
+	}
+
+	// 9802 is taken, none of the ports of the range may stay allocated
+	runCmd = exec.Command(dockerBinary, "run", "-d", "-p", "9802-9804:80-82/udp", "busybox", "top")
+	if out, _, err = runCommandWithOutput(runCmd); err == nil {
+		t.Fatalf("Expected an error publishing a range with a port already allocated: %s", out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "run", "-d", "-p", "9803-9804:80-81/udp", "busybox", "top")
+	out, _, err = runCommandWithOutput(runCmd)
+	if err != nil {
+		t.Fatalf("The ports of the failed range are still allocated: %s, %v", out, err)
+	}
+
+	logDone("port - test port range")
+}
+
 func assertPortList(t *testing.T, out string, expected []string) bool {
 	//lines := strings.Split(out, "\n")
 	lines := strings.Split(strings.Trim(out, "\n "), "\n")
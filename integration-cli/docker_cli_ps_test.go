@@ -282,3 +282,33 @@ func TestPsListContainersFilterStatus(t *testing.T) {
 
 	logDone("ps - test ps filter status")
 }
+
+func TestPsListContainersFilterLabel(t *testing.T) {
+	runCmd := exec.Command(dockerBinary, "run", "-d", "-l", "match=me", "busybox")
+	out, _, err := runCommandWithOutput(runCmd)
+	errorOut(err, t, out)
+	firstID := stripTrailingCharacters(out)
+
+	runCmd = exec.Command(dockerBinary, "run", "-d", "-l", "match=notme", "busybox")
+	out, _, err = runCommandWithOutput(runCmd)
+	errorOut(err, t, out)
+
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--no-trunc", "--filter=label=match=me")
+	out, _, err = runCommandWithOutput(runCmd)
+	errorOut(err, t, out)
+	containerOut := strings.TrimSpace(out)
+	if containerOut != firstID {
+		t.Fatalf("Expected id %s, got %s for label filter, output: %q", firstID, containerOut, out)
+	}
+
+	runCmd = exec.Command(dockerBinary, "ps", "-a", "-q", "--filter=label=match")
+	out, _, err = runCommandWithOutput(runCmd)
+	errorOut(err, t, out)
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 {
+		t.Fatalf("Expected 2 containers with label match, got %d, output: %q", len(lines), out)
+	}
+
+	deleteAllContainers()
+
+	logDone("ps - test ps filter label")
+}
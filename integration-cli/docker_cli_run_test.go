@@ -2303,3 +2303,204 @@ func TestRunReuseBindVolumeThatIsSymlink(t *testing.T) {
 	deleteAllContainers()
 	logDone("run - can remount old bindmount volume")
 }
+
+func TestRunContainerWithReadonlyRootfs(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "--read-only", "--rm", "busybox", "touch", "/file")
+	out, _, err := runCommandWithOutput(cmd)
+	if err == nil {
+		t.Fatal("expected container to error on run with read only rootfs")
+	}
+	if !strings.Contains(out, "Read-only file system") {
+		t.Fatalf("expected output from failure to contain Read-only file system but contains %q", out)
+	}
+
+	// volumes and the generated files in /etc stay writable
+	cmd = exec.Command(dockerBinary, "run", "--read-only", "--rm", "-v", "/data", "busybox", "sh", "-c", "touch /data/file && echo test >> /etc/hosts && touch /dev/file")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(err, out)
+	}
+
+	deleteAllContainers()
+	logDone("run - read only rootfs")
+}
+
+func TestRunWithUlimits(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "--ulimit", "nofile=42", "busybox", "/bin/sh", "-c", "ulimit -n")
+	out, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+
+	ul := strings.TrimSpace(out)
+	if ul != "42" {
+		t.Fatalf("expected `ulimit -n` to be `42`, got: %s", ul)
+	}
+
+	deleteAllContainers()
+	logDone("run - ulimits are set")
+}
+
+func TestRunModePidHost(t *testing.T) {
+	hostPid, err := os.Readlink("/proc/1/ns/pid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(dockerBinary, "run", "--pid=host", "busybox", "readlink", "/proc/self/ns/pid")
+	out2, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(err, out2)
+	}
+
+	out2 = strings.Trim(out2, "\n")
+	if hostPid != out2 {
+		t.Fatalf("PID different with --pid=host %s != %s\n", hostPid, out2)
+	}
+
+	cmd = exec.Command(dockerBinary, "run", "busybox", "readlink", "/proc/self/ns/pid")
+	out2, _, err = runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(err, out2)
+	}
+
+	out2 = strings.Trim(out2, "\n")
+	if hostPid == out2 {
+		t.Fatalf("PID should be different without --pid=host %s == %s\n", hostPid, out2)
+	}
+	deleteAllContainers()
+
+	logDone("run - pid host mode")
+}
+
+func TestRunModeIpcContainer(t *testing.T) {
+	cmd := exec.Command(dockerBinary, "run", "-d", "busybox", "top")
+	out, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	id := strings.TrimSpace(out)
+
+	cmd = exec.Command(dockerBinary, "inspect", "--format", "{{.State.Pid}}", id)
+	out, _, err = runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	pid := strings.TrimSpace(out)
+
+	parentContainerIpc, err := os.Readlink(fmt.Sprintf("/proc/%s/ns/ipc", pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = exec.Command(dockerBinary, "run", fmt.Sprintf("--ipc=container:%s", id), "busybox", "readlink", "/proc/self/ns/ipc")
+	out2, _, err := runCommandWithOutput(cmd)
+	if err != nil {
+		t.Fatal(err, out2)
+	}
+
+	out2 = strings.Trim(out2, "\n")
+	if parentContainerIpc != out2 {
+		t.Fatalf("IPC different with --ipc=container:%s %s != %s\n", id, parentContainerIpc, out2)
+	}
+	deleteAllContainers()
+
+	logDone("run - ipc container mode")
+}
+
+func TestRunHealthcheck(t *testing.T) {
+	defer deleteAllContainers()
+
+	waitForHealth := func(name, status string) error {
+		for i := 0; i < 20; i++ {
+			out, err := inspectField(name, "State.Health.Status")
+			if err != nil {
+				return err
+			}
+			if out == status {
+				return nil
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+		return fmt.Errorf("%s did not become %s", name, status)
+	}
+
+	cmd := exec.Command(dockerBinary, "run", "-d", "--name=healthy",
+		"--health-cmd=cat /status", "--health-interval=1s", "--health-retries=1",
+		"busybox", "sh", "-c", "echo ok > /status; top")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(err, out)
+	}
+	if err := waitForHealth("healthy", "healthy"); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = exec.Command(dockerBinary, "exec", "healthy", "rm", "/status")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(err, out)
+	}
+	if err := waitForHealth("healthy", "unhealthy"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := inspectField("healthy", "State.Health.FailingStreak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out == "0" {
+		t.Fatal("expected the failing streak of an unhealthy container to not be 0")
+	}
+
+	cmd = exec.Command(dockerBinary, "run", "--health-cmd=true", "--no-healthcheck", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "--no-healthcheck conflicts") {
+		t.Fatalf("expected --no-healthcheck to conflict with --health-cmd, got %v: %s", err, out)
+	}
+
+	logDone("run - health check")
+}
+
+func TestRunStopSignal(t *testing.T) {
+	defer deleteAllContainers()
+
+	cmd := exec.Command(dockerBinary, "run", "-d", "--name=stopsignal", "--stop-signal=SIGUSR1",
+		"busybox", "sh", "-c", "trap 'exit 42' USR1; while true; do sleep 1; done")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(err, out)
+	}
+
+	cmd = exec.Command(dockerBinary, "stop", "-t", "10", "stopsignal")
+	if out, _, err := runCommandWithOutput(cmd); err != nil {
+		t.Fatal(err, out)
+	}
+
+	out, err := inspectField("stopsignal", "State.ExitCode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "42" {
+		t.Fatalf("expected the container to exit on SIGUSR1 with 42, got %s", out)
+	}
+
+	cmd = exec.Command(dockerBinary, "run", "--stop-signal=SIGNOTASIGNAL", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "Invalid signal") {
+		t.Fatalf("expected an invalid signal error, got %v: %s", err, out)
+	}
+
+	logDone("run - stop signal")
+}
+
+func TestRunStorageOptInvalid(t *testing.T) {
+	defer deleteAllContainers()
+
+	// no storage driver knows this option, the container must not be created
+	cmd := exec.Command(dockerBinary, "run", "--name=storageopt", "--storage-opt", "notanoption=1", "busybox", "true")
+	if out, _, err := runCommandWithOutput(cmd); err == nil || !strings.Contains(out, "storage") {
+		t.Fatalf("expected a storage option error, got %v: %s", err, out)
+	}
+
+	cmd = exec.Command(dockerBinary, "inspect", "storageopt")
+	if _, _, err := runCommandWithOutput(cmd); err == nil {
+		t.Fatal("expected the container not to be created")
+	}
+
+	logDone("run - invalid storage option")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	std_log "log"
 	"net"
 	"net/url"
@@ -340,7 +341,7 @@ func TestDaemonCreate(t *testing.T) {
 	}
 	container, _, err = daemon.Create(config, &runconfig.HostConfig{}, "")
 
-	_, err = daemon.Commit(container, "testrepo", "testtag", "", "", true, config)
+	_, err = daemon.Commit(container, "testrepo", "testtag", "", "", true, false, config)
 	if err != nil {
 		t.Error(err)
 	}
@@ -856,6 +857,66 @@ func TestGetAllChildren(t *testing.T) {
 	}
 }
 
+func TestRenameLinkedContainer(t *testing.T) {
+	eng := NewTestEngine(t)
+	daemon := mkDaemonFromEngine(eng, t)
+	defer nuke(daemon)
+
+	config, _, _, err := parseRun([]string{unitTestImageID, "echo test"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webapp := daemon.Get(createNamedTestContainer(eng, config, t, "/webapp"))
+	db := daemon.Get(createNamedTestContainer(eng, config, t, "/db"))
+
+	// one link has the name of the container as alias, the other doesn't
+	if err := daemon.RegisterLink(webapp, db, "db"); err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.RegisterLink(webapp, db, "database"); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := ioutil.TempFile("", "docker-hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts.Close()
+	defer os.Remove(hosts.Name())
+	if err := ioutil.WriteFile(hosts.Name(), []byte("172.17.0.3\twebapp\n172.17.0.2\tdb\n172.17.0.2\tdatabase\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	webapp.HostsPath = hosts.Name()
+
+	if err := eng.Job("container_rename", "db", "db2").Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err := daemon.GetByName("/db2"); err != nil || c.ID != db.ID {
+		t.Fatalf("Expected /db2 to be %s, got %v (%v)", db.ID, c, err)
+	}
+	children, err := daemon.Children(webapp.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := children["/webapp/db"]; exists || len(children) != 2 {
+		t.Fatalf("Expected the link /webapp/db to be renamed, got %v", children)
+	}
+	for _, link := range []string{"/webapp/db2", "/webapp/database"} {
+		if c, exists := children[link]; !exists || c.ID != db.ID {
+			t.Fatalf("Expected the link %s to %s, got %v", link, db.ID, children)
+		}
+	}
+
+	content, err := ioutil.ReadFile(hosts.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "172.17.0.3\twebapp\n172.17.0.2\tdb2\n172.17.0.2\tdatabase\n"; string(content) != expected {
+		t.Fatalf("Expected the hosts file %q, got %q", expected, content)
+	}
+}
+
 func TestDestroyWithInitLayer(t *testing.T) {
 	daemon := mkDaemon(t)
 	defer nuke(daemon)
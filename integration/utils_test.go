@@ -21,6 +21,7 @@ import (
 	"github.com/docker/docker/pkg/log"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/sysinfo"
+	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
@@ -181,6 +182,7 @@ func newTestEngine(t log.Fataler, autorestart bool, root string) *engine.Engine
 	eng.Logging = false
 	// Load default plugins
 	builtins.Register(eng)
+	registry.NewService(nil).Install(eng)
 	// (This is manually copied and modified from main() until we have a more generic plugin system)
 	cfg := &daemon.Config{
 		Root:        root,
@@ -106,6 +106,18 @@ func ParsePortSpecs(ports []string) (map[Port]struct{}, map[Port][]PortBinding,
 			proto = rawPort[i+1:]
 			rawPort = rawPort[:i]
 		}
+
+		// an IPv6 host ip is written in brackets, like [::1]:8080:80
+		var bracketIp string
+		if strings.HasPrefix(rawPort, "[") {
+			i := strings.Index(rawPort, "]")
+			if i == -1 || strings.Count(rawPort[i+1:], ":") != 2 || !strings.HasPrefix(rawPort[i+1:], ":") {
+				return nil, nil, fmt.Errorf("Invalid port format: %s", rawPort)
+			}
+			bracketIp = rawPort[1:i]
+			rawPort = rawPort[i+1:]
+		}
+
 		if !strings.Contains(rawPort, ":") {
 			rawPort = fmt.Sprintf("::%s", rawPort)
 		} else if len(strings.Split(rawPort, ":")) == 2 {
@@ -123,37 +135,65 @@ func ParsePortSpecs(ports []string) (map[Port]struct{}, map[Port][]PortBinding,
 			hostPort      = parts["hostPort"]
 		)
 
+		if bracketIp != "" {
+			rawIp = bracketIp
+			if ip := net.ParseIP(rawIp); ip == nil || ip.To4() != nil {
+				return nil, nil, fmt.Errorf("Invalid IPv6 address: %s", rawIp)
+			}
+		}
 		if rawIp != "" && net.ParseIP(rawIp) == nil {
 			return nil, nil, fmt.Errorf("Invalid ip address: %s", rawIp)
 		}
 		if containerPort == "" {
 			return nil, nil, fmt.Errorf("No port specified: %s<empty>", rawPort)
 		}
-		if _, err := strconv.ParseUint(containerPort, 10, 16); err != nil {
+		startPort, endPort, err := parsers.ParsePortRange(containerPort)
+		if err != nil {
 			return nil, nil, fmt.Errorf("Invalid containerPort: %s", containerPort)
 		}
-		if _, err := strconv.ParseUint(hostPort, 10, 16); hostPort != "" && err != nil {
-			return nil, nil, fmt.Errorf("Invalid hostPort: %s", hostPort)
+
+		var startHostPort, endHostPort uint64
+		if hostPort != "" {
+			startHostPort, endHostPort, err = parsers.ParsePortRange(hostPort)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Invalid hostPort: %s", hostPort)
+			}
+			// a range of container ports is published on a range of host
+			// ports of the same size, port by port
+			if endPort-startPort != endHostPort-startHostPort {
+				return nil, nil, fmt.Errorf("Invalid ranges specified for container and host Ports: %s and %s", containerPort, hostPort)
+			}
 		}
 
 		if !validateProto(proto) {
 			return nil, nil, fmt.Errorf("Invalid proto: %s", proto)
 		}
 
-		port := NewPort(proto, containerPort)
-		if _, exists := exposedPorts[port]; !exists {
-			exposedPorts[port] = struct{}{}
-		}
-
-		binding := PortBinding{
-			HostIp:   rawIp,
-			HostPort: hostPort,
-		}
-		bslice, exists := bindings[port]
-		if !exists {
-			bslice = []PortBinding{}
+		isRange := strings.Contains(containerPort, "-")
+		for i := uint64(0); i <= endPort-startPort; i++ {
+			// single ports are kept as they were written
+			if isRange {
+				containerPort = strconv.FormatUint(startPort+i, 10)
+				if hostPort != "" {
+					hostPort = strconv.FormatUint(startHostPort+i, 10)
+				}
+			}
+
+			port := NewPort(proto, containerPort)
+			if _, exists := exposedPorts[port]; !exists {
+				exposedPorts[port] = struct{}{}
+			}
+
+			binding := PortBinding{
+				HostIp:   rawIp,
+				HostPort: hostPort,
+			}
+			bslice, exists := bindings[port]
+			if !exists {
+				bslice = []PortBinding{}
+			}
+			bindings[port] = append(bslice, binding)
 		}
-		bindings[port] = append(bslice, binding)
 	}
 	return exposedPorts, bindings, nil
 }
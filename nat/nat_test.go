@@ -1,6 +1,7 @@
 package nat
 
 import (
+	"strconv"
 	"testing"
 )
 
@@ -199,3 +200,75 @@ func TestParsePortSpecs(t *testing.T) {
 		t.Fatal("Received no error while trying to parse a hostname instead of ip")
 	}
 }
+
+func TestParsePortSpecsIPv6(t *testing.T) {
+	portMap, bindingMap, err := ParsePortSpecs([]string{"[::1]:1234:1234/tcp", "[2001:db8::1]::2345/udp"})
+	if err != nil {
+		t.Fatalf("Error while processing ParsePortSpecs: %s", err.Error())
+	}
+
+	if _, ok := portMap[Port("1234/tcp")]; !ok {
+		t.Fatal("1234/tcp was not parsed properly")
+	}
+	if _, ok := portMap[Port("2345/udp")]; !ok {
+		t.Fatal("2345/udp was not parsed properly")
+	}
+
+	if bindings := bindingMap[Port("1234/tcp")]; len(bindings) != 1 || bindings[0].HostIp != "::1" || bindings[0].HostPort != "1234" {
+		t.Fatalf("Wrong bindings for 1234/tcp: %v", bindings)
+	}
+	if bindings := bindingMap[Port("2345/udp")]; len(bindings) != 1 || bindings[0].HostIp != "2001:db8::1" || bindings[0].HostPort != "" {
+		t.Fatalf("Wrong bindings for 2345/udp: %v", bindings)
+	}
+
+	for _, spec := range []string{"[::1]:1234", "[::1:1234:1234", "[::1]1234:1234", "[127.0.0.1]:1234:1234", "[foo]:1234:1234"} {
+		if _, _, err := ParsePortSpecs([]string{spec}); err == nil {
+			t.Fatalf("Received no error while trying to parse %s", spec)
+		}
+	}
+}
+
+func TestParsePortSpecsWithRange(t *testing.T) {
+	portMap, bindingMap, err := ParsePortSpecs([]string{"1234-1236/tcp", "2345-2347:3345-3347/udp", "0.0.0.0:4345-4346:5345-5346"})
+	if err != nil {
+		t.Fatalf("Error while processing ParsePortSpecs: %s", err.Error())
+	}
+
+	for _, port := range []Port{"1234/tcp", "1235/tcp", "1236/tcp", "3345/udp", "3346/udp", "3347/udp", "5345/tcp", "5346/tcp"} {
+		if _, ok := portMap[port]; !ok {
+			t.Fatalf("%s was not parsed properly", port)
+		}
+	}
+	if len(portMap) != 8 || len(bindingMap) != 8 {
+		t.Fatalf("Expected 8 ports and bindings, got %d and %d", len(portMap), len(bindingMap))
+	}
+
+	for portspec, bindings := range bindingMap {
+		proto, port := SplitProtoPort(string(portspec))
+		p, _ := strconv.Atoi(port)
+
+		if len(bindings) != 1 {
+			t.Fatalf("%s should have exactly one binding", portspec)
+		}
+
+		var hostIp, hostPort string
+		switch {
+		case proto == "udp":
+			hostPort = strconv.Itoa(p - 1000)
+		case p >= 5345:
+			hostIp, hostPort = "0.0.0.0", strconv.Itoa(p-1000)
+		}
+		if bindings[0].HostIp != hostIp {
+			t.Fatalf("HostIp should be %q for %s, got %q", hostIp, portspec, bindings[0].HostIp)
+		}
+		if bindings[0].HostPort != hostPort {
+			t.Fatalf("HostPort should be %q for %s, got %q", hostPort, portspec, bindings[0].HostPort)
+		}
+	}
+
+	for _, spec := range []string{"1234-1236:2345-2346", "1236-1234", "1234-:2345", "1234-1236:2345"} {
+		if _, _, err := ParsePortSpecs([]string{spec}); err == nil {
+			t.Fatalf("Received no error while trying to parse %s", spec)
+		}
+	}
+}
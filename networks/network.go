@@ -0,0 +1,66 @@
+package networks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// Network is a user-defined network that containers can be attached to.
+type Network struct {
+	ID         string
+	Name       string
+	Driver     string
+	Subnet     string
+	Gateway    string
+	containers map[string]struct{}
+	configPath string
+	lock       sync.Mutex
+}
+
+func (n *Network) Containers() []string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	var containers []string
+	for c := range n.containers {
+		containers = append(containers, c)
+	}
+	return containers
+}
+
+func (n *Network) addContainer(containerID string) {
+	n.lock.Lock()
+	n.containers[containerID] = struct{}{}
+	n.lock.Unlock()
+}
+
+func (n *Network) removeContainer(containerID string) {
+	n.lock.Lock()
+	delete(n.containers, containerID)
+	n.lock.Unlock()
+}
+
+func (n *Network) toDisk() error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(n.configPath, data, 0600)
+}
+
+func (n *Network) fromDisk() error {
+	data, err := ioutil.ReadFile(n.configPath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, n)
+}
+
+func (n *Network) removeFromDisk() error {
+	if err := os.Remove(n.configPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package networks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/daemon/networkdriver"
+	"github.com/docker/docker/pkg/log"
+	"github.com/docker/docker/utils"
+)
+
+// DefaultDriver is the network driver used when none is specified.
+const DefaultDriver = "bridge"
+
+// reservedNames are the network modes which are not user-defined networks.
+var reservedNames = map[string]bool{
+	"bridge":    true,
+	"host":      true,
+	"none":      true,
+	"container": true,
+	"default":   true,
+}
+
+// Repository keeps track of the user-defined networks, storing their
+// configuration under its configPath.
+type Repository struct {
+	configPath string
+	networks   map[string]*Network
+	lock       sync.Mutex
+}
+
+func NewRepository(configPath string) (*Repository, error) {
+	abspath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(abspath, 0700); err != nil && !os.IsExist(err) {
+		return nil, err
+	}
+
+	repo := &Repository{
+		configPath: abspath,
+		networks:   make(map[string]*Network),
+	}
+	return repo, repo.restore()
+}
+
+// restore loads the networks stored on disk and asks their drivers to set
+// them up again.
+func (r *Repository) restore() error {
+	dir, err := ioutil.ReadDir(r.configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, fi := range dir {
+		if !strings.HasSuffix(fi.Name(), ".json") {
+			continue
+		}
+		n := &Network{
+			containers: make(map[string]struct{}),
+			configPath: filepath.Join(r.configPath, fi.Name()),
+		}
+		if err := n.fromDisk(); err != nil {
+			log.Debugf("Error restoring network %s: %s", fi.Name(), err)
+			continue
+		}
+		driver, err := networkdriver.GetDriver(n.Driver)
+		if err != nil {
+			log.Errorf("Error restoring network %s: %s", n.Name, err)
+			continue
+		}
+		if _, err := driver.CreateNetwork(n.ID, n.Subnet); err != nil {
+			log.Errorf("Error restoring network %s: %s", n.Name, err)
+			continue
+		}
+		r.networks[n.ID] = n
+	}
+	return nil
+}
+
+// Get returns the network with the given name or ID, or nil if there is no
+// such network.
+func (r *Repository) Get(name string) *Network {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.get(name)
+}
+
+func (r *Repository) get(name string) *Network {
+	if n, exists := r.networks[name]; exists {
+		return n
+	}
+	for _, n := range r.networks {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// List returns all the user-defined networks.
+func (r *Repository) List() []*Network {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var networks []*Network
+	for _, n := range r.networks {
+		networks = append(networks, n)
+	}
+	return networks
+}
+
+// Create sets up a new network with the given driver. An empty subnet lets
+// the driver pick a free one.
+func (r *Repository) Create(name, driverName, subnet string) (*Network, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	if r.get(name) != nil {
+		return nil, fmt.Errorf("Conflict, network %s already exists", name)
+	}
+	if driverName == "" {
+		driverName = DefaultDriver
+	}
+	driver, err := networkdriver.GetDriver(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	id := utils.GenerateRandomID()
+	network, err := driver.CreateNetwork(id, subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Network{
+		ID:         id,
+		Name:       name,
+		Driver:     driverName,
+		Subnet:     (&net.IPNet{IP: network.IP.Mask(network.Mask), Mask: network.Mask}).String(),
+		Gateway:    network.IP.String(),
+		containers: make(map[string]struct{}),
+		configPath: filepath.Join(r.configPath, id+".json"),
+	}
+	if err := n.toDisk(); err != nil {
+		driver.DeleteNetwork(id)
+		return nil, err
+	}
+	r.networks[id] = n
+	return n, nil
+}
+
+// Delete tears down the network with the given name or ID. Networks with
+// containers attached are not removed.
+func (r *Repository) Delete(name string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	n := r.get(name)
+	if n == nil {
+		return fmt.Errorf("No such network: %s", name)
+	}
+	if containers := n.Containers(); len(containers) > 0 {
+		return fmt.Errorf("Conflict, network %s is in use by containers %s", name, containers)
+	}
+	driver, err := networkdriver.GetDriver(n.Driver)
+	if err != nil {
+		return err
+	}
+	if err := driver.DeleteNetwork(n.ID); err != nil {
+		return err
+	}
+	if err := n.removeFromDisk(); err != nil {
+		return err
+	}
+	delete(r.networks, n.ID)
+	return nil
+}
+
+// Join allocates an interface for the container on the network with the
+// given name or ID.
+func (r *Repository) Join(name, containerID string, requestedIP net.IP, requestedMac string) (*networkdriver.Interface, error) {
+	n := r.Get(name)
+	if n == nil {
+		return nil, fmt.Errorf("No such network: %s", name)
+	}
+	driver, err := networkdriver.GetDriver(n.Driver)
+	if err != nil {
+		return nil, err
+	}
+	iface, err := driver.Join(n.ID, containerID, requestedIP, requestedMac)
+	if err != nil {
+		return nil, err
+	}
+	n.addContainer(containerID)
+	return iface, nil
+}
+
+// Leave releases the interface of the container on the network with the
+// given name or ID.
+func (r *Repository) Leave(name, containerID string) error {
+	n := r.Get(name)
+	if n == nil {
+		return fmt.Errorf("No such network: %s", name)
+	}
+	driver, err := networkdriver.GetDriver(n.Driver)
+	if err != nil {
+		return err
+	}
+	n.removeContainer(containerID)
+	return driver.Leave(n.ID, containerID)
+}
+
+// ValidateName checks that name can be used for a user-defined network.
+func ValidateName(name string) error {
+	if !utils.RestrictedNamePattern.MatchString(name) {
+		return fmt.Errorf("Bad parameter: invalid network name (%s), only %s are allowed", name, utils.RestrictedNameChars)
+	}
+	if reservedNames[name] {
+		return fmt.Errorf("Bad parameter: network name %s is reserved", name)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package networks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/daemon/networkdriver"
+)
+
+// testDriver is a network driver keeping its networks in memory.
+type testDriver struct {
+	networks map[string]*net.IPNet
+	joined   map[string]string
+}
+
+func (d *testDriver) CreateNetwork(id, subnet string) (*net.IPNet, error) {
+	if subnet == "" {
+		subnet = "10.10.0.0/24"
+	}
+	ip, network, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return nil, err
+	}
+	network.IP = ip.To4()
+	network.IP[3] = 1
+	d.networks[id] = network
+	return network, nil
+}
+
+func (d *testDriver) DeleteNetwork(id string) error {
+	if _, exists := d.networks[id]; !exists {
+		return fmt.Errorf("No such network: %s", id)
+	}
+	delete(d.networks, id)
+	return nil
+}
+
+func (d *testDriver) Join(id, containerID string, requestedIP net.IP, requestedMac string) (*networkdriver.Interface, error) {
+	network, exists := d.networks[id]
+	if !exists {
+		return nil, fmt.Errorf("No such network: %s", id)
+	}
+	d.joined[containerID] = id
+	return &networkdriver.Interface{IP: requestedIP, Gateway: network.IP, Bridge: "test0"}, nil
+}
+
+func (d *testDriver) Leave(id, containerID string) error {
+	delete(d.joined, containerID)
+	return nil
+}
+
+var driver = &testDriver{networks: make(map[string]*net.IPNet), joined: make(map[string]string)}
+
+func init() {
+	networkdriver.Register("test", driver)
+}
+
+func TestCreateNetwork(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-networks-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	repo, err := NewRepository(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := repo.Create("staging", "test", "10.20.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Subnet != "10.20.0.0/16" || n.Gateway != "10.20.0.1" {
+		t.Fatalf("Unexpected subnet %s and gateway %s", n.Subnet, n.Gateway)
+	}
+	if _, err := repo.Create("staging", "test", ""); err == nil {
+		t.Fatal("Expected an error creating a network with a name already in use")
+	}
+	for _, name := range []string{"bridge", "host", "none", "../staging"} {
+		if _, err := repo.Create(name, "test", ""); err == nil {
+			t.Fatalf("Expected an error creating a network named %s", name)
+		}
+	}
+	if err := ValidateName("s"); err != nil {
+		t.Fatalf("Expected a network name of one character to be valid: %s", err)
+	}
+	if _, err := repo.Create("production", "missing", ""); err == nil {
+		t.Fatal("Expected an error creating a network with an unknown driver")
+	}
+	if repo.Get(n.ID) != n || repo.Get("staging") != n {
+		t.Fatal("Expected to find the network by its name and ID")
+	}
+
+	delete(driver.networks, n.ID)
+	restored, err := NewRepository(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.List()) != 1 || restored.Get("staging") == nil {
+		t.Fatal("Expected the network to be restored")
+	}
+	if _, exists := driver.networks[n.ID]; !exists {
+		t.Fatal("Expected the network to be set up again by its driver")
+	}
+}
+
+func TestJoinAndDeleteNetwork(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-networks-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	repo, err := NewRepository(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := repo.Create("staging", "test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iface, err := repo.Join("staging", "container", net.ParseIP("10.10.0.5"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iface.Bridge != "test0" || driver.joined["container"] != n.ID {
+		t.Fatalf("Expected the container to join network %s", n.ID)
+	}
+	if err := repo.Delete("staging"); err == nil {
+		t.Fatal("Expected an error removing a network in use")
+	}
+
+	if err := repo.Leave("staging", "container"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if repo.Get("staging") != nil {
+		t.Fatal("Expected the network to be removed")
+	}
+	if _, err := os.Stat(n.configPath); !os.IsNotExist(err) {
+		t.Fatalf("Expected the network configuration to be removed, got %v", err)
+	}
+}
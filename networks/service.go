@@ -0,0 +1,73 @@
+package networks
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/engine"
+)
+
+func (r *Repository) Install(eng *engine.Engine) error {
+	for name, handler := range map[string]engine.Handler{
+		"network_create":  r.CmdCreate,
+		"network_inspect": r.CmdInspect,
+		"networks":        r.CmdList,
+	} {
+		if err := eng.Register(name, handler); err != nil {
+			return fmt.Errorf("Could not register %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// CmdCreate creates a network. The driver and subnet are taken from the
+// Driver and Subnet environment variables, and are both optional.
+func (r *Repository) CmdCreate(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("usage: %s NAME", job.Name)
+	}
+	n, err := r.Create(job.Args[0], job.Getenv("Driver"), job.Getenv("Subnet"))
+	if err != nil {
+		return job.Error(err)
+	}
+	if _, err := networkEnv(n).WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (r *Repository) CmdInspect(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("usage: %s NAME", job.Name)
+	}
+	n := r.Get(job.Args[0])
+	if n == nil {
+		return job.Errorf("No such network: %s", job.Args[0])
+	}
+	if _, err := networkEnv(n).WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (r *Repository) CmdList(job *engine.Job) engine.Status {
+	outs := engine.NewTable("Name", 0)
+	for _, n := range r.List() {
+		outs.Add(networkEnv(n))
+	}
+	outs.Sort()
+	if _, err := outs.WriteListTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func networkEnv(n *Network) *engine.Env {
+	out := &engine.Env{}
+	out.Set("Name", n.Name)
+	out.Set("Id", n.ID)
+	out.Set("Driver", n.Driver)
+	out.Set("Subnet", n.Subnet)
+	out.Set("Gateway", n.Gateway)
+	out.SetList("Containers", n.Containers())
+	return out
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/docker/docker/api"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/ulimit"
 )
 
 var (
@@ -43,6 +44,14 @@ func MirrorListVar(values *[]string, names []string, usage string) {
 	flag.Var(newListOptsRef(values, ValidateMirror), names, usage)
 }
 
+func InsecureRegistryListVar(values *[]string, names []string, usage string) {
+	flag.Var(newListOptsRef(values, ValidateInsecureRegistry), names, usage)
+}
+
+func UlimitMapVar(values map[string]*ulimit.Ulimit, names []string, usage string) {
+	flag.Var(NewUlimitOpt(values), names, usage)
+}
+
 // ListOpts type
 type ListOpts struct {
 	values    *[]string
@@ -227,3 +236,17 @@ func ValidateMirror(val string) (string, error) {
 
 	return fmt.Sprintf("%s://%s/v1/", uri.Scheme, uri.Host), nil
 }
+
+// ValidateInsecureRegistry checks that val is a registry host[:port] or a
+// CIDR subnet of registries, without a scheme.
+func ValidateInsecureRegistry(val string) (string, error) {
+	if strings.Contains(val, "://") {
+		return "", fmt.Errorf("Insecure registry %s should not contain a scheme", val)
+	}
+	if strings.Contains(val, "/") {
+		if _, _, err := net.ParseCIDR(val); err != nil {
+			return "", fmt.Errorf("%s is not a valid CIDR subnet", val)
+		}
+	}
+	return val, nil
+}
@@ -88,3 +88,16 @@ func TestValidateDnsSearch(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateInsecureRegistry(t *testing.T) {
+	for _, val := range []string{"localhost:5000", "myregistry.example.com", "10.1.0.0/16"} {
+		if ret, err := ValidateInsecureRegistry(val); err != nil || ret != val {
+			t.Fatalf("ValidateInsecureRegistry(%q) got %q %v", val, ret, err)
+		}
+	}
+	for _, val := range []string{"http://localhost:5000", "10.1.0.0/abc", "example.com/path"} {
+		if _, err := ValidateInsecureRegistry(val); err == nil {
+			t.Fatalf("ValidateInsecureRegistry(%q) should have failed", val)
+		}
+	}
+}
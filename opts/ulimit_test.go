@@ -0,0 +1,33 @@
+package opts
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/ulimit"
+)
+
+func TestUlimitOpt(t *testing.T) {
+	ulimitOpt := NewUlimitOpt(map[string]*ulimit.Ulimit{
+		"nofile": {Name: "nofile", Hard: 1024, Soft: 512},
+	})
+
+	if err := ulimitOpt.Set("nofile=2048:4096"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ulimitOpt.Set("nproc=100"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ulimitOpt.Set("notarealtype=1"); err == nil {
+		t.Fatal("expected an error for an unknown ulimit type")
+	}
+
+	list := ulimitOpt.GetList()
+	if len(list) != 2 {
+		t.Fatalf("expected 2 ulimits, got %d", len(list))
+	}
+	for _, u := range list {
+		if u.Name == "nofile" && (u.Soft != 2048 || u.Hard != 4096) {
+			t.Fatalf("expected nofile=2048:4096 to replace the default, got %s", u)
+		}
+	}
+}
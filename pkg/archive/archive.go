@@ -22,6 +22,7 @@ import (
 	"github.com/docker/docker/pkg/log"
 	"github.com/docker/docker/pkg/pools"
 	"github.com/docker/docker/pkg/promise"
+	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/system"
 )
 
@@ -219,6 +220,23 @@ func addTarFile(path, name string, tw *tar.Writer, twBuf *bufio.Writer) error {
 	return nil
 }
 
+// resolveInScope joins name to root. Symlinks in the parent directories of
+// name are followed, but never outside of root; the last element of name is
+// not followed, so that an entry replaces a symlink instead of writing
+// through it.
+func resolveInScope(root, name string) (string, error) {
+	name = filepath.Clean(name)
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("%s: path escapes the destination %s", name, root)
+	}
+
+	parent, err := symlink.FollowSymlinkInScope(filepath.Join(root, filepath.Dir(name)), root)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(name)), nil
+}
+
 func createTarFile(path, extractDir string, hdr *tar.Header, reader io.Reader, Lchown bool) error {
 	// hdr.Mode is in linux format, which we can use for sycalls,
 	// but for os.Foo() calls we need the mode converted to os.FileMode,
@@ -263,7 +281,11 @@ func createTarFile(path, extractDir string, hdr *tar.Header, reader io.Reader, L
 		}
 
 	case tar.TypeLink:
-		if err := os.Link(filepath.Join(extractDir, hdr.Linkname), path); err != nil {
+		targetPath, err := resolveInScope(extractDir, hdr.Linkname)
+		if err != nil {
+			return err
+		}
+		if err := os.Link(targetPath, path); err != nil {
 			return err
 		}
 
@@ -457,10 +479,17 @@ loop:
 			}
 		}
 
+		// Don't let the entry escape dest, neither through ".." nor through
+		// symlinks that already exist in dest or were created by earlier
+		// entries of the archive.
+		path, err := resolveInScope(dest, hdr.Name)
+		if err != nil {
+			return err
+		}
+
 		if !strings.HasSuffix(hdr.Name, "/") {
 			// Not the root directory, ensure that the parent directory exists
-			parent := filepath.Dir(hdr.Name)
-			parentPath := filepath.Join(dest, parent)
+			parentPath := filepath.Dir(path)
 			if _, err := os.Lstat(parentPath); err != nil && os.IsNotExist(err) {
 				err = os.MkdirAll(parentPath, 0777)
 				if err != nil {
@@ -469,8 +498,6 @@ loop:
 			}
 		}
 
-		path := filepath.Join(dest, hdr.Name)
-
 		// If path exits we almost always just want to remove and replace it
 		// The only exception is when it is a directory *and* the file from
 		// the layer is also a directory. Then we want to merge them (i.e.
@@ -535,6 +562,56 @@ func UntarPath(src, dst string) error {
 	return nil
 }
 
+// RebaseArchiveEntries rewrites the entries of the uncompressed tar archive
+// `srcContent` so that `oldBase`, and everything under it, is named
+// `newBase` instead. It is used to copy a file or directory to a different
+// name than the one it has at the source.
+func RebaseArchiveEntries(srcContent ArchiveReader, oldBase, newBase string) Archive {
+	rebased, w := io.Pipe()
+
+	rebase := func(name string) string {
+		if name == oldBase || strings.HasPrefix(name, oldBase+"/") {
+			return newBase + strings.TrimPrefix(name, oldBase)
+		}
+		return name
+	}
+
+	go func() {
+		srcTar := tar.NewReader(srcContent)
+		rebasedTar := tar.NewWriter(w)
+
+		for {
+			hdr, err := srcTar.Next()
+			if err == io.EOF {
+				// Signals end of archive.
+				rebasedTar.Close()
+				w.Close()
+				return
+			}
+			if err != nil {
+				w.CloseWithError(err)
+				return
+			}
+
+			hdr.Name = rebase(hdr.Name)
+			if hdr.Typeflag == tar.TypeLink {
+				hdr.Linkname = rebase(hdr.Linkname)
+			}
+
+			if err := rebasedTar.WriteHeader(hdr); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(rebasedTar, srcTar); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return rebased
+}
+
 // CopyWithTar creates a tar archive of filesystem path `src`, and
 // unpacks it at filesystem path `dst`.
 // The archive is streamed directly with fixed buffering and no
@@ -204,6 +204,113 @@ func TestUntarUstarGnuConflict(t *testing.T) {
 	}
 }
 
+func untarHeaders(headers []*tar.Header, dest string) error {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, hdr := range headers {
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return Untar(buf, dest, &TarOptions{NoLchown: true})
+}
+
+func TestUntarDotDotEscape(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-test-untar-escape")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	dest := path.Join(tmp, "dest")
+	if err := os.Mkdir(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err = untarHeaders([]*tar.Header{
+		{Name: "../victim", Typeflag: tar.TypeReg, Mode: 0644},
+	}, dest)
+	if err == nil {
+		t.Fatal("expected an error for an entry outside of the destination")
+	}
+	if _, err := os.Lstat(path.Join(tmp, "victim")); !os.IsNotExist(err) {
+		t.Fatalf("file was written outside of the destination: %v", err)
+	}
+}
+
+func TestUntarSymlinkEscape(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-test-untar-escape")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	dest := path.Join(tmp, "dest")
+	if err := os.Mkdir(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err = untarHeaders([]*tar.Header{
+		{Name: "dir", Typeflag: tar.TypeSymlink, Linkname: tmp, Mode: 0777},
+		{Name: "rel", Typeflag: tar.TypeSymlink, Linkname: "../", Mode: 0777},
+		{Name: "dir/victim", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "rel/victim2", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "hardlink", Typeflag: tar.TypeLink, Linkname: "rel/victim2"},
+	}, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"victim", "victim2", "hardlink"} {
+		if _, err := os.Lstat(path.Join(tmp, name)); !os.IsNotExist(err) {
+			t.Fatalf("%s was written outside of the destination: %v", name, err)
+		}
+	}
+	// the absolute symlink is resolved against dest instead
+	if _, err := os.Stat(path.Join(dest, tmp, "victim")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRebaseArchiveEntries(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "src", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "src/file", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "src/link", Typeflag: tar.TypeLink, Linkname: "src/file"},
+		{Name: "srcfoo", Typeflag: tar.TypeReg, Mode: 0644},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+
+	rebased := RebaseArchiveEntries(buf, "src", "dst")
+	defer rebased.Close()
+
+	expected := []string{"dst", "dst/file", "dst/link:dst/file", "srcfoo"}
+	tr := tar.NewReader(rebased)
+	for _, name := range expected {
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := hdr.Name
+		if hdr.Linkname != "" {
+			got += ":" + hdr.Linkname
+		}
+		if got != name {
+			t.Fatalf("Expected %s, got %s", name, got)
+		}
+	}
+	if _, err := tr.Next(); err != io.EOF {
+		t.Fatalf("Expected end of archive, got %v", err)
+	}
+}
+
 func prepareUntarSourceDirectory(numberOfFiles int, targetPath string) (int, error) {
 	fileData := []byte("fooo")
 	for n := 0; n < numberOfFiles; n++ {
@@ -16,13 +16,18 @@ var defaultContent = map[string]string{
 	"ip6-allrouters":                       "ff02::2",
 }
 
-func Build(path, IP, hostname, domainname string, extraContent *map[string]string) error {
+// Build writes the hosts file at path, with entries for hostname on IP and on
+// the IPv6 address IPv6 when they are not empty.
+func Build(path, IP, IPv6, hostname, domainname string, extraContent *map[string]string) error {
 	content := bytes.NewBuffer(nil)
-	if IP != "" {
+	for _, ip := range []string{IP, IPv6} {
+		if ip == "" {
+			continue
+		}
 		if domainname != "" {
-			content.WriteString(fmt.Sprintf("%s\t%s.%s %s\n", IP, hostname, domainname, hostname))
+			content.WriteString(fmt.Sprintf("%s\t%s.%s %s\n", ip, hostname, domainname, hostname))
 		} else {
-			content.WriteString(fmt.Sprintf("%s\t%s\n", IP, hostname))
+			content.WriteString(fmt.Sprintf("%s\t%s\n", ip, hostname))
 		}
 	}
 
@@ -51,3 +56,14 @@ func Update(path, IP, hostname string) error {
 	var re = regexp.MustCompile(fmt.Sprintf("(\\S*)(\\t%s)", regexp.QuoteMeta(hostname)))
 	return ioutil.WriteFile(path, re.ReplaceAll(old, []byte(IP+"$2")), 0644)
 }
+
+// Rename replaces the hostname oldName with newName in the entries of the
+// hosts file at path that are for oldName alone, like the entries of links.
+func Rename(path, oldName, newName string) error {
+	old, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var re = regexp.MustCompile(fmt.Sprintf("(?m)^(\\S+\\t)%s$", regexp.QuoteMeta(oldName)))
+	return ioutil.WriteFile(path, re.ReplaceAll(old, []byte("${1}"+newName)), 0644)
+}
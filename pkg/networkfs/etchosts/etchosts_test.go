@@ -14,7 +14,7 @@ func TestBuildHostnameDomainname(t *testing.T) {
 	}
 	defer os.Remove(file.Name())
 
-	err = Build(file.Name(), "10.11.12.13", "testhostname", "testdomainname", nil)
+	err = Build(file.Name(), "10.11.12.13", "", "testhostname", "testdomainname", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -36,7 +36,7 @@ func TestBuildHostname(t *testing.T) {
 	}
 	defer os.Remove(file.Name())
 
-	err = Build(file.Name(), "10.11.12.13", "testhostname", "", nil)
+	err = Build(file.Name(), "10.11.12.13", "", "testhostname", "", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -51,6 +51,33 @@ func TestBuildHostname(t *testing.T) {
 	}
 }
 
+func TestBuildIPv6(t *testing.T) {
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	err = Build(file.Name(), "10.11.12.13", "2001:db8::2", "testhostname", "testdomainname", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"10.11.12.13\ttesthostname.testdomainname testhostname\n",
+		"2001:db8::2\ttesthostname.testdomainname testhostname\n",
+	} {
+		if !bytes.Contains(content, []byte(expected)) {
+			t.Fatalf("Expected to find '%s' got '%s'", expected, content)
+		}
+	}
+}
+
 func TestBuildNoIP(t *testing.T) {
 	file, err := ioutil.TempFile("", "")
 	if err != nil {
@@ -58,7 +85,7 @@ func TestBuildNoIP(t *testing.T) {
 	}
 	defer os.Remove(file.Name())
 
-	err = Build(file.Name(), "", "testhostname", "", nil)
+	err = Build(file.Name(), "", "", "testhostname", "", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -80,7 +107,7 @@ func TestUpdate(t *testing.T) {
 	}
 	defer os.Remove(file.Name())
 
-	if err := Build(file.Name(), "10.11.12.13", "testhostname", "testdomainname", nil); err != nil {
+	if err := Build(file.Name(), "10.11.12.13", "", "testhostname", "testdomainname", nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -106,3 +133,33 @@ func TestUpdate(t *testing.T) {
 		t.Fatalf("Expected to find '%s' got '%s'", expected, content)
 	}
 }
+
+func TestRename(t *testing.T) {
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	extraContent := map[string]string{"db": "172.17.0.2", "dbadmin": "172.17.0.3"}
+	if err := Build(file.Name(), "10.11.12.13", "", "testhostname", "", &extraContent); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Rename(file.Name(), "db", "database"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "172.17.0.2\tdatabase\n"; !bytes.Contains(content, []byte(expected)) {
+		t.Fatalf("Expected to find '%s' got '%s'", expected, content)
+	}
+
+	if expected := "172.17.0.3\tdbadmin\n"; !bytes.Contains(content, []byte(expected)) {
+		t.Fatalf("Expected to find '%s' got '%s'", expected, content)
+	}
+}
@@ -49,6 +49,31 @@ func ToParam(a Args) (string, error) {
 	return string(buf), nil
 }
 
+// MatchKVList reports whether the key/value map sources, such as the labels
+// of a container, passes every value of the field filter. A value is either
+// "key", which matches when the key is present, or "key=value".
+func (filters Args) MatchKVList(field string, sources map[string]string) bool {
+	fieldValues := filters[field]
+	if len(fieldValues) == 0 {
+		return true
+	}
+	if len(sources) == 0 {
+		return false
+	}
+
+	for _, value := range fieldValues {
+		parts := strings.SplitN(value, "=", 2)
+		v, exists := sources[parts[0]]
+		if !exists {
+			return false
+		}
+		if len(parts) == 2 && v != parts[1] {
+			return false
+		}
+	}
+	return true
+}
+
 // unpacks the filter Args
 func FromParam(p string) (Args, error) {
 	args := Args{}
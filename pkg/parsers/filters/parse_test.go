@@ -76,3 +76,27 @@ func TestEmpty(t *testing.T) {
 		t.Errorf("these should both be empty sets")
 	}
 }
+
+func TestMatchKVList(t *testing.T) {
+	labels := map[string]string{"owner": "web-team", "env": "staging"}
+	cases := []struct {
+		filters Args
+		match   bool
+	}{
+		{Args{}, true},
+		{Args{"label": {"owner"}}, true},
+		{Args{"label": {"owner=web-team"}}, true},
+		{Args{"label": {"owner=web-team", "env=staging"}}, true},
+		{Args{"label": {"owner=db-team"}}, false},
+		{Args{"label": {"owner=web-team", "env=prod"}}, false},
+		{Args{"label": {"region"}}, false},
+	}
+	for _, c := range cases {
+		if match := c.filters.MatchKVList("label", labels); match != c.match {
+			t.Errorf("%v: expected match %t, got %t", c.filters, c.match, match)
+		}
+	}
+	if (Args{"label": {"owner"}}).MatchKVList("label", nil) {
+		t.Errorf("a label filter should not match a container without labels")
+	}
+}
@@ -69,7 +69,12 @@ func ParseHost(defaultHost string, defaultUnix, addr string) (string, error) {
 // Get a repos name and returns the right reposName + tag
 // The tag can be confusing because of a port in a repository name.
 //     Ex: localhost.localdomain:5000/samalba/hipache:latest
+// The tag of a reference by digest, name@digest, is the digest.
+//     Ex: ubuntu@sha256:9c2a5c8b...
 func ParseRepositoryTag(repos string) (string, string) {
+	if n := strings.Index(repos, "@"); n >= 0 {
+		return repos[:n], repos[n+1:]
+	}
 	n := strings.LastIndex(repos, ":")
 	if n < 0 {
 		return repos, ""
@@ -108,3 +113,33 @@ func ParseKeyValueOpt(opt string) (string, string, error) {
 	}
 	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
 }
+
+// ParsePortRange parses a port or a range of ports like 8000-8100, and
+// returns its first and last port.
+func ParsePortRange(ports string) (uint64, uint64, error) {
+	if ports == "" {
+		return 0, 0, fmt.Errorf("Empty string specified for ports.")
+	}
+	if !strings.Contains(ports, "-") {
+		start, err := strconv.ParseUint(ports, 10, 16)
+		end := start
+		return start, end, err
+	}
+
+	parts := strings.Split(ports, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("Invalid range specified for ports: %s", ports)
+	}
+	start, err := strconv.ParseUint(parts[0], 10, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := strconv.ParseUint(parts[1], 10, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("Invalid range specified for ports: %s", ports)
+	}
+	return start, end, nil
+}
@@ -60,6 +60,9 @@ func TestParseRepositoryTag(t *testing.T) {
 	if repo, tag := ParseRepositoryTag("url:5000/repo:tag"); repo != "url:5000/repo" || tag != "tag" {
 		t.Errorf("Expected repo: '%s' and tag: '%s', got '%s' and '%s'", "url:5000/repo", "tag", repo, tag)
 	}
+	if repo, digest := ParseRepositoryTag("url:5000/repo@sha256:abc"); repo != "url:5000/repo" || digest != "sha256:abc" {
+		t.Errorf("Expected repo: '%s' and digest: '%s', got '%s' and '%s'", "url:5000/repo", "sha256:abc", repo, digest)
+	}
 }
 
 func TestParsePortMapping(t *testing.T) {
@@ -81,3 +84,25 @@ func TestParsePortMapping(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestParsePortRange(t *testing.T) {
+	for ports, expected := range map[string][2]uint64{
+		"8000":      {8000, 8000},
+		"8000-8100": {8000, 8100},
+		"1-65535":   {1, 65535},
+	} {
+		start, end, err := ParsePortRange(ports)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start != expected[0] || end != expected[1] {
+			t.Fatalf("Expected %v for %s, got %d-%d", expected, ports, start, end)
+		}
+	}
+
+	for _, ports := range []string{"", "8000-", "-8000", "8100-8000", "8000-8100-8200", "80000", "8000-foo"} {
+		if _, _, err := ParsePortRange(ports); err == nil {
+			t.Fatalf("Expected an error parsing %q", ports)
+		}
+	}
+}
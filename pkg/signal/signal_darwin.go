@@ -4,6 +4,9 @@ import (
 	"syscall"
 )
 
+// maxSignal is the highest signal number, the last signal, NSIG - 1
+const maxSignal = 31
+
 var SignalMap = map[string]syscall.Signal{
 	"ABRT":   syscall.SIGABRT,
 	"ALRM":   syscall.SIGALRM,
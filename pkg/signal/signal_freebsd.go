@@ -4,6 +4,9 @@ import (
 	"syscall"
 )
 
+// maxSignal is the highest signal number, SIGRTMAX
+const maxSignal = 126
+
 var SignalMap = map[string]syscall.Signal{
 	"ABRT":   syscall.SIGABRT,
 	"ALRM":   syscall.SIGALRM,
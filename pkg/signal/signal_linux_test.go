@@ -0,0 +1,30 @@
+package signal
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	for raw, expected := range map[string]syscall.Signal{
+		"9":       syscall.SIGKILL,
+		"KILL":    syscall.SIGKILL,
+		"SIGQUIT": syscall.SIGQUIT,
+		"sigint":  syscall.SIGINT,
+		"64":      syscall.Signal(64),
+	} {
+		sig, err := ParseSignal(raw)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %s", raw, err)
+		}
+		if sig != expected {
+			t.Fatalf("expected %q to be parsed as %d, got %d", raw, expected, sig)
+		}
+	}
+
+	for _, raw := range []string{"", "0", "-1", "65", "4294967305", "SIGNOTASIGNAL"} {
+		if _, err := ParseSignal(raw); err == nil {
+			t.Fatalf("expected an error parsing %q", raw)
+		}
+	}
+}
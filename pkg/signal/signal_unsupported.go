@@ -6,4 +6,7 @@ import (
 	"syscall"
 )
 
+// maxSignal is the highest signal number on linux, where the daemon runs
+const maxSignal = 64
+
 var SignalMap = map[string]syscall.Signal{}
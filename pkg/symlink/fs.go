@@ -67,14 +67,13 @@ func FollowSymlinkInScope(link, root string) (string, error) {
 					return "", err
 				}
 
+				// Resolve the target as if root were "/", so that
+				// neither absolute targets nor ".." can leave root.
 				if path.IsAbs(dest) {
-					prev = filepath.Join(root, dest)
+					prev = filepath.Join(root, filepath.Clean("/"+dest))
 				} else {
-					prev, _ = filepath.Abs(prev)
-
-					if prev = filepath.Clean(filepath.Join(filepath.Dir(prev), dest)); len(prev) < len(root) {
-						prev = filepath.Join(root, filepath.Base(dest))
-					}
+					dir := strings.TrimPrefix(filepath.Dir(prev), root)
+					prev = filepath.Join(root, filepath.Clean("/"+filepath.Join(dir, dest)))
 				}
 			} else {
 				break
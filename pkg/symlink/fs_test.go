@@ -119,3 +119,26 @@ func TestFollowSymLinkRelativeLinkScope(t *testing.T) {
 		t.Fatalf("Expected %s got %s", expected, rewrite)
 	}
 }
+
+func TestFollowSymLinkRelativeParentEscape(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-fs-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root")
+	os.Mkdir(root, 0700)
+	os.Symlink("../", filepath.Join(root, "up"))
+	os.Symlink("/../../", filepath.Join(root, "abs"))
+
+	for _, link := range []string{"up/victim", "abs/victim"} {
+		rewrite, err := FollowSymlinkInScope(filepath.Join(root, link), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := filepath.Join(root, "victim"); expected != rewrite {
+			t.Fatalf("Expected %s got %s", expected, rewrite)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package ulimit parses resource limits given as `name=soft[:hard]`, like
+// `nofile=1024:2048`, and converts them to limits for setrlimit(2).
+package ulimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Human friendly version of Rlimit
+type Ulimit struct {
+	Name string
+	Hard int64
+	Soft int64
+}
+
+type Rlimit struct {
+	Type int    `json:"type,omitempty"`
+	Hard uint64 `json:"hard,omitempty"`
+	Soft uint64 `json:"soft,omitempty"`
+}
+
+const (
+	// the resource numbers of setrlimit(2) on linux, the syscall package
+	// doesn't define all of them and isn't available to every client
+	rlimitCpu        = 0x0
+	rlimitFsize      = 0x1
+	rlimitData       = 0x2
+	rlimitStack      = 0x3
+	rlimitCore       = 0x4
+	rlimitRss        = 0x5
+	rlimitNproc      = 0x6
+	rlimitNofile     = 0x7
+	rlimitMemlock    = 0x8
+	rlimitAs         = 0x9
+	rlimitLocks      = 0xa
+	rlimitSigpending = 0xb
+	rlimitMsgqueue   = 0xc
+	rlimitNice       = 0xd
+	rlimitRtprio     = 0xe
+	rlimitRttime     = 0xf
+)
+
+var ulimitNameMapping = map[string]int{
+	"as":         rlimitAs,
+	"core":       rlimitCore,
+	"cpu":        rlimitCpu,
+	"data":       rlimitData,
+	"fsize":      rlimitFsize,
+	"locks":      rlimitLocks,
+	"memlock":    rlimitMemlock,
+	"msgqueue":   rlimitMsgqueue,
+	"nice":       rlimitNice,
+	"nofile":     rlimitNofile,
+	"nproc":      rlimitNproc,
+	"rss":        rlimitRss,
+	"rtprio":     rlimitRtprio,
+	"rttime":     rlimitRttime,
+	"sigpending": rlimitSigpending,
+	"stack":      rlimitStack,
+}
+
+// Parse parses a ulimit in the `name=soft[:hard]` format. The hard limit
+// defaults to the soft limit.
+func Parse(val string) (*Ulimit, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid ulimit argument: %s", val)
+	}
+
+	if _, exists := ulimitNameMapping[parts[0]]; !exists {
+		return nil, fmt.Errorf("invalid ulimit type: %s", parts[0])
+	}
+
+	limitVals := strings.SplitN(parts[1], ":", 2)
+
+	soft, err := strconv.ParseInt(limitVals[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	hard := soft // in case no hard was set
+	if len(limitVals) == 2 {
+		hard, err = strconv.ParseInt(limitVals[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if soft < 0 || hard < 0 {
+		return nil, fmt.Errorf("invalid ulimit argument: %s, limits can't be negative", val)
+	}
+	if soft > hard {
+		return nil, fmt.Errorf("ulimit soft limit must be less than or equal to hard limit: %d > %d", soft, hard)
+	}
+
+	return &Ulimit{Name: parts[0], Soft: soft, Hard: hard}, nil
+}
+
+// GetRlimit returns the limit to pass to setrlimit(2).
+func (u *Ulimit) GetRlimit() (*Rlimit, error) {
+	t, exists := ulimitNameMapping[u.Name]
+	if !exists {
+		return nil, fmt.Errorf("invalid ulimit name %s", u.Name)
+	}
+
+	return &Rlimit{Type: t, Soft: uint64(u.Soft), Hard: uint64(u.Hard)}, nil
+}
+
+func (u *Ulimit) String() string {
+	return fmt.Sprintf("%s=%d:%d", u.Name, u.Soft, u.Hard)
+}
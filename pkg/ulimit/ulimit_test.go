@@ -0,0 +1,51 @@
+package ulimit
+
+import "testing"
+
+func TestParseValid(t *testing.T) {
+	u1 := &Ulimit{"nofile", 2048, 1024}
+	if u2, _ := Parse("nofile=1024:2048"); *u1 != *u2 {
+		t.Fatalf("expected %q, but got %q", u1, u2)
+	}
+
+	u1 = &Ulimit{"nproc", 512, 512}
+	if u2, _ := Parse("nproc=512"); *u1 != *u2 {
+		t.Fatalf("expected %q, but got %q", u1, u2)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, val := range []string{
+		"nofile",
+		"notarealtype=1024:1024",
+		"nofile=asdf",
+		"nofile=1024:asdf",
+		"nofile=2048:1024",
+		"nofile=-1",
+	} {
+		if _, err := Parse(val); err == nil {
+			t.Fatalf("expected error parsing %q", val)
+		}
+	}
+}
+
+func TestGetRlimit(t *testing.T) {
+	u, err := Parse("nofile=1024:2048")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := u.GetRlimit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Type != rlimitNofile || r.Soft != 1024 || r.Hard != 2048 {
+		t.Fatalf("unexpected rlimit %+v", r)
+	}
+}
+
+func TestString(t *testing.T) {
+	u := &Ulimit{"nofile", 2048, 1024}
+	if s := u.String(); s != "nofile=1024:2048" {
+		t.Fatalf("expected String to return nofile=1024:2048, but got %q", s)
+	}
+}
@@ -2,11 +2,12 @@ package registry
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 
 	"github.com/docker/docker/pkg/log"
@@ -34,7 +35,10 @@ func scanForApiVersion(hostname string) (string, APIVersion) {
 	return hostname, DefaultAPIVersion
 }
 
-func NewEndpoint(hostname string) (*Endpoint, error) {
+// NewEndpoint returns the endpoint of the registry at hostname. Registries
+// are reached over HTTPS with certificate verification, except for the
+// insecureRegistries, which may have any certificate or fall back to HTTP.
+func NewEndpoint(hostname string, insecureRegistries []string) (*Endpoint, error) {
 	var (
 		endpoint        Endpoint
 		trimmedHostname string
@@ -48,14 +52,18 @@ func NewEndpoint(hostname string) (*Endpoint, error) {
 	if err != nil {
 		return nil, err
 	}
+	endpoint.Insecure = !isSecure(endpoint.URL.Host, insecureRegistries)
+	endpoint.insecureRegistries = insecureRegistries
 
 	endpoint.URL.Scheme = "https"
 	if _, err := endpoint.Ping(); err != nil {
+		if !endpoint.Insecure {
+			return nil, fmt.Errorf("Invalid registry endpoint %s: %v. If this private registry supports only HTTP or HTTPS with an unknown CA certificate, add `--insecure-registry %s` to the daemon's arguments. For HTTPS with a private CA, place its certificate in %s instead.", endpoint, err, endpoint.URL.Host, path.Join(CertsDir, endpoint.URL.Host, "ca.crt"))
+		}
 		log.Debugf("Registry %s does not work (%s), falling back to http", endpoint, err)
-		// TODO: Check if http fallback is enabled
 		endpoint.URL.Scheme = "http"
 		if _, err = endpoint.Ping(); err != nil {
-			return nil, errors.New("Invalid Registry endpoint: " + err.Error())
+			return nil, fmt.Errorf("Invalid registry endpoint %s: %v", endpoint, err)
 		}
 	}
 
@@ -63,8 +71,61 @@ func NewEndpoint(hostname string) (*Endpoint, error) {
 }
 
 type Endpoint struct {
-	URL     *url.URL
-	Version APIVersion
+	URL      *url.URL
+	Version  APIVersion
+	Insecure bool // true for the registries reached without TLS verification
+
+	// the insecure registries the mirrors and endpoints a session on this
+	// endpoint is sent to are checked against
+	insecureRegistries []string
+}
+
+// lookupIP resolves the hostnames of registries, it is replaced in tests
+var lookupIP = net.LookupIP
+
+// isSecure returns false if the registry at hostname, a host with an optional
+// port, is one of the insecureRegistries, or one of its addresses is in one of
+// the insecure networks. The registries on the loopback network are always
+// insecure.
+func isSecure(hostname string, insecureRegistries []string) bool {
+	if index, err := url.Parse(IndexServerAddress()); err == nil && index.Host == hostname {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(hostname)
+	if err != nil {
+		// no port
+		host = hostname
+	}
+
+	var addrs []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		addrs = []net.IP{ip}
+	} else if addrs, err = lookupIP(host); err != nil {
+		log.Debugf("Unable to resolve the registry %s: %s", host, err)
+	}
+	for _, addr := range addrs {
+		if addr.IsLoopback() {
+			return false
+		}
+	}
+
+	for _, r := range insecureRegistries {
+		if r == hostname {
+			return false
+		}
+		_, network, err := net.ParseCIDR(r)
+		if err != nil {
+			// a hostname, not a network
+			continue
+		}
+		for _, addr := range addrs {
+			if network.Contains(addr) {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 // Get the formated URL for the root of this registry Endpoint
@@ -88,7 +149,7 @@ func (e Endpoint) Ping() (RegistryInfo, error) {
 		return RegistryInfo{Standalone: false}, err
 	}
 
-	resp, _, err := doRequest(req, nil, ConnectTimeout)
+	resp, _, err := doRequest(req, nil, ConnectTimeout, !e.Insecure)
 	if err != nil {
 		return RegistryInfo{Standalone: false}, err
 	}
@@ -27,6 +27,11 @@ var (
 	validRepo                = regexp.MustCompile(`^([a-z0-9-_.]+)$`)
 )
 
+// CertsDir holds a directory of certificates for each registry host, with
+// the CA certificates (*.crt) and client certificates (*.cert and *.key) to
+// use for it
+const CertsDir = "/etc/docker/certs.d"
+
 type TimeoutType uint32
 
 const (
@@ -35,8 +40,12 @@ const (
 	ConnectTimeout
 )
 
-func newClient(jar http.CookieJar, roots *x509.CertPool, cert *tls.Certificate, timeout TimeoutType) *http.Client {
-	tlsConfig := tls.Config{RootCAs: roots}
+func newClient(jar http.CookieJar, roots *x509.CertPool, cert *tls.Certificate, timeout TimeoutType, secure bool) *http.Client {
+	tlsConfig := tls.Config{
+		RootCAs: roots,
+		// insecure registries may have self-signed or expired certificates
+		InsecureSkipVerify: !secure,
+	}
 
 	if cert != nil {
 		tlsConfig.Certificates = append(tlsConfig.Certificates, *cert)
@@ -78,7 +87,7 @@ func newClient(jar http.CookieJar, roots *x509.CertPool, cert *tls.Certificate,
 	}
 }
 
-func doRequest(req *http.Request, jar http.CookieJar, timeout TimeoutType) (*http.Response, *http.Client, error) {
+func doRequest(req *http.Request, jar http.CookieJar, timeout TimeoutType, secure bool) (*http.Response, *http.Client, error) {
 	hasFile := func(files []os.FileInfo, name string) bool {
 		for _, f := range files {
 			if f.Name() == name {
@@ -88,7 +97,7 @@ func doRequest(req *http.Request, jar http.CookieJar, timeout TimeoutType) (*htt
 		return false
 	}
 
-	hostDir := path.Join("/etc/docker/certs.d", req.URL.Host)
+	hostDir := path.Join(CertsDir, req.URL.Host)
 	fs, err := ioutil.ReadDir(hostDir)
 	if err != nil && !os.IsNotExist(err) {
 		return nil, nil, err
@@ -132,7 +141,7 @@ func doRequest(req *http.Request, jar http.CookieJar, timeout TimeoutType) (*htt
 	}
 
 	if len(certs) == 0 {
-		client := newClient(jar, pool, nil, timeout)
+		client := newClient(jar, pool, nil, timeout, secure)
 		res, err := client.Do(req)
 		if err != nil {
 			return nil, nil, err
@@ -140,7 +149,7 @@ func doRequest(req *http.Request, jar http.CookieJar, timeout TimeoutType) (*htt
 		return res, client, nil
 	}
 	for i, cert := range certs {
-		client := newClient(jar, pool, cert, timeout)
+		client := newClient(jar, pool, cert, timeout, secure)
 		res, err := client.Do(req)
 		// If this is the last cert, otherwise, continue to next cert if 403 or 5xx
 		if i == len(certs)-1 || err == nil && res.StatusCode != 403 && res.StatusCode < 500 {
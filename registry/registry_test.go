@@ -2,6 +2,7 @@ package registry
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -18,7 +19,7 @@ var (
 
 func spawnTestRegistrySession(t *testing.T) *Session {
 	authConfig := &AuthConfig{}
-	endpoint, err := NewEndpoint(makeURL("/v1/"))
+	endpoint, err := NewEndpoint(makeURL("/v1/"), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -30,7 +31,7 @@ func spawnTestRegistrySession(t *testing.T) *Session {
 }
 
 func TestPingRegistryEndpoint(t *testing.T) {
-	ep, err := NewEndpoint(makeURL("/v1/"))
+	ep, err := NewEndpoint(makeURL("/v1/"), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -316,3 +317,80 @@ func TestAddRequiredHeadersToRedirectedRequests(t *testing.T) {
 		}
 	}
 }
+
+func TestIsSecure(t *testing.T) {
+	defer func(f func(string) ([]net.IP, error)) { lookupIP = f }(lookupIP)
+	lookupIP = func(host string) ([]net.IP, error) {
+		switch host {
+		case "localhost":
+			return []net.IP{net.ParseIP("127.0.0.1")}, nil
+		case "internal.example.com":
+			return []net.IP{net.ParseIP("10.1.2.3")}, nil
+		}
+		return []net.IP{net.ParseIP("203.0.113.10")}, nil
+	}
+
+	insecureRegistries := []string{"myregistry.example.com:5000", "10.1.0.0/16"}
+	tests := []struct {
+		hostname string
+		secure   bool
+	}{
+		{"localhost:5000", false},
+		{"127.0.0.1:5000", false},
+		{"myregistry.example.com:5000", false},
+		{"myregistry.example.com", true},
+		{"internal.example.com:5000", false},
+		{"10.1.3.4", false},
+		{"10.2.3.4", true},
+		{"example.com", true},
+	}
+	for _, tt := range tests {
+		if secure := isSecure(tt.hostname, insecureRegistries); secure != tt.secure {
+			t.Fatalf("isSecure(%q) = %v, expected %v", tt.hostname, secure, tt.secure)
+		}
+	}
+}
+
+func TestSessionIsSecure(t *testing.T) {
+	defer func(f func(string) ([]net.IP, error)) { lookupIP = f }(lookupIP)
+	lookupIP = func(host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("203.0.113.10")}, nil
+	}
+
+	index, err := url.Parse("http://myregistry.example.com:5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := &Endpoint{URL: index, Version: APIVersion1, Insecure: true, insecureRegistries: []string{"myregistry.example.com:5000", "mirror.example.com"}}
+	r, err := NewSession(&AuthConfig{}, utils.NewHTTPRequestFactory(), endpoint, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for host, secure := range map[string]bool{
+		"myregistry.example.com:5000": false,
+		"mirror.example.com":          false,
+		"cdn.example.com":             true,
+	} {
+		if r.isSecure(host) != secure {
+			t.Fatalf("Expected %s to be secure: %v", host, secure)
+		}
+	}
+
+	// a mirror or endpoint doesn't inherit the index's insecurity
+	req, err := r.reqFactory.NewRequest("GET", "http://cdn.example.com/v1/images/"+IMAGE_ID+"/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.doRequest(req); err == nil || !strings.Contains(err.Error(), "Refusing") {
+		t.Fatalf("Expected a plain HTTP request to a secure registry to be refused, got %v", err)
+	}
+
+	// endpoints verify TLS unless told otherwise
+	r, err = NewSession(&AuthConfig{}, utils.NewHTTPRequestFactory(), &Endpoint{URL: index, Version: APIVersion1}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.isSecure(index.Host) {
+		t.Fatal("Expected an endpoint to be secure by default")
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/pkg/httputils"
@@ -28,12 +29,16 @@ type Session struct {
 	indexEndpoint *Endpoint
 	jar           *cookiejar.Jar
 	timeout       TimeoutType
+
+	secureLock sync.Mutex
+	secure     map[string]bool // whether the hosts other than the index are secure
 }
 
 func NewSession(authConfig *AuthConfig, factory *utils.HTTPRequestFactory, endpoint *Endpoint, timeout bool) (r *Session, err error) {
 	r = &Session{
 		authConfig:    authConfig,
 		indexEndpoint: endpoint,
+		secure:        make(map[string]bool),
 	}
 
 	if timeout {
@@ -63,8 +68,31 @@ func NewSession(authConfig *AuthConfig, factory *utils.HTTPRequestFactory, endpo
 	return r, nil
 }
 
+// isSecure reports whether host, the index or one of the mirrors and
+// registry endpoints the session is sent to, must be reached over HTTPS with
+// certificate verification.
+func (r *Session) isSecure(host string) bool {
+	if host == r.indexEndpoint.URL.Host {
+		return !r.indexEndpoint.Insecure
+	}
+	r.secureLock.Lock()
+	defer r.secureLock.Unlock()
+	secure, ok := r.secure[host]
+	if !ok {
+		secure = isSecure(host, r.indexEndpoint.insecureRegistries)
+		r.secure[host] = secure
+	}
+	return secure
+}
+
+// doRequest sends req with the TLS verification its host requires, and
+// refuses to send it over HTTP unless the host is an insecure registry.
 func (r *Session) doRequest(req *http.Request) (*http.Response, *http.Client, error) {
-	return doRequest(req, r.jar, r.timeout)
+	secure := r.isSecure(req.URL.Host)
+	if secure && req.URL.Scheme == "http" {
+		return nil, nil, fmt.Errorf("Refusing to reach %s over HTTP. If it is a registry you trust, add `--insecure-registry %s` to the daemon's arguments.", req.URL.Host, req.URL.Host)
+	}
+	return doRequest(req, r.jar, r.timeout, secure)
 }
 
 // Retrieve the history of a given image from the Registry.
@@ -188,8 +216,8 @@ func (r *Session) GetRemoteImageLayer(imgID, registry string, token []string, im
 
 	if res.StatusCode != 200 {
 		res.Body.Close()
-		return nil, fmt.Errorf("Server error: Status %d while fetching image layer (%s)",
-			res.StatusCode, imgID)
+		return nil, utils.NewHTTPRequestError(fmt.Sprintf("Server error: Status %d while fetching image layer (%s)",
+			res.StatusCode, imgID), res)
 	}
 
 	if res.Header.Get("Accept-Ranges") == "bytes" && imgSize > 0 {
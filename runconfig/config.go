@@ -1,6 +1,8 @@
 package runconfig
 
 import (
+	"time"
+
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/nat"
 )
@@ -33,6 +35,23 @@ type Config struct {
 	NetworkDisabled bool
 	OnBuild         []string
 	SecurityOpt     []string
+	Labels          map[string]string // Arbitrary key/value metadata, e.g. owner or environment
+	Healthcheck     *HealthConfig     // How to check that the container is healthy
+	StopSignal      string            // Signal to stop the container, SIGTERM if empty
+}
+
+// HealthConfig holds the configuration of a container's health check
+type HealthConfig struct {
+	// Test is the command that checks the container's health, either
+	// ["CMD", args...] to run args directly, ["CMD-SHELL", command] to run
+	// command with /bin/sh -c, or ["NONE"] to disable the check of the image.
+	// An empty Test inherits the image's command.
+	Test []string
+
+	// Zero values use the image's or the daemon's defaults
+	Interval time.Duration // Time between the start of two checks
+	Timeout  time.Duration // Time after which a check is considered to have failed
+	Retries  int           // Consecutive failures needed to be unhealthy
 }
 
 func ContainerConfigFromJob(job *engine.Job) *Config {
@@ -52,10 +71,13 @@ func ContainerConfigFromJob(job *engine.Job) *Config {
 		StdinOnce:       job.GetenvBool("StdinOnce"),
 		Image:           job.Getenv("Image"),
 		WorkingDir:      job.Getenv("WorkingDir"),
+		StopSignal:      job.Getenv("StopSignal"),
 		NetworkDisabled: job.GetenvBool("NetworkDisabled"),
 	}
 	job.GetenvJson("ExposedPorts", &config.ExposedPorts)
 	job.GetenvJson("Volumes", &config.Volumes)
+	job.GetenvJson("Labels", &config.Labels)
+	job.GetenvJson("Healthcheck", &config.Healthcheck)
 	config.SecurityOpt = job.GetenvList("SecurityOpt")
 	if PortSpecs := job.GetenvList("PortSpecs"); PortSpecs != nil {
 		config.PortSpecs = PortSpecs
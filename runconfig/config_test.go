@@ -125,6 +125,20 @@ func TestParseRunVolumes(t *testing.T) {
 		t.Fatalf("Error parsing volume flags, `-v /containerVar` is missing from volumes. Received %v", config.Volumes)
 	}
 
+	if config, hostConfig := mustParse(t, "-v data:/containerData"); hostConfig.Binds == nil || hostConfig.Binds[0] != "data:/containerData" {
+		t.Fatalf("Error parsing volume flags, `-v data:/containerData` should mount the named volume data into /containerData. Received %v", hostConfig.Binds)
+	} else if _, exists := config.Volumes["data:/containerData"]; exists {
+		t.Fatalf("Error parsing volume flags, `-v data:/containerData` should not be in volumes. Received %v", config.Volumes)
+	}
+
+	if _, _, err := parse(t, "-v ../data:/containerData"); err == nil {
+		t.Fatalf("Error parsing volume flags, `-v ../data:/containerData` should fail but didn't")
+	}
+
+	if _, hostConfig := mustParse(t, "-v d:/containerData"); hostConfig.Binds == nil || hostConfig.Binds[0] != "d:/containerData" {
+		t.Fatalf("Error parsing volume flags, `-v d:/containerData` should mount the named volume d into /containerData. Received %v", hostConfig.Binds)
+	}
+
 	if config, hostConfig := mustParse(t, ""); hostConfig.Binds != nil {
 		t.Fatalf("Error parsing volume flags, without volume, nothing should be mount-binded. Received %v", hostConfig.Binds)
 	} else if len(config.Volumes) != 0 {
@@ -161,6 +175,23 @@ func TestParseRunVolumes(t *testing.T) {
 	}
 }
 
+func TestParseRunLabels(t *testing.T) {
+	config, _ := mustParse(t, "--label owner=web-team -l env=staging=eu -l canary")
+	expected := map[string]string{"owner": "web-team", "env": "staging=eu", "canary": ""}
+	if len(config.Labels) != len(expected) {
+		t.Fatalf("Expected labels %v, received: %v", expected, config.Labels)
+	}
+	for k, v := range expected {
+		if config.Labels[k] != v {
+			t.Fatalf("Expected labels %v, received: %v", expected, config.Labels)
+		}
+	}
+
+	if _, _, err := parse(t, "--label =value"); err == nil {
+		t.Fatalf("Error parsing labels. `--label =value` should be an error but is not")
+	}
+}
+
 func TestCompare(t *testing.T) {
 	volumes1 := make(map[string]struct{})
 	volumes1["/test1"] = struct{}{}
@@ -190,6 +221,14 @@ func TestCompare(t *testing.T) {
 	if !Compare(&config1, &config1) {
 		t.Fatalf("Compare should return true")
 	}
+
+	config6 := config1
+	config6.Labels = map[string]string{"owner": "a"}
+	config7 := config1
+	config7.Labels = map[string]string{"owner": "b"}
+	if Compare(&config6, &config7) {
+		t.Fatalf("Compare should return false, Labels are different")
+	}
 }
 
 func TestMerge(t *testing.T) {
@@ -200,6 +239,7 @@ func TestMerge(t *testing.T) {
 		PortSpecs: []string{"1111:1111", "2222:2222"},
 		Env:       []string{"VAR1=1", "VAR2=2"},
 		Volumes:   volumesImage,
+		Labels:    map[string]string{"owner": "image", "env": "prod"},
 	}
 
 	volumesUser := make(map[string]struct{})
@@ -208,6 +248,7 @@ func TestMerge(t *testing.T) {
 		PortSpecs: []string{"3333:2222", "3333:3333"},
 		Env:       []string{"VAR2=3", "VAR3=3"},
 		Volumes:   volumesUser,
+		Labels:    map[string]string{"owner": "user"},
 	}
 
 	if err := Merge(configUser, configImage); err != nil {
@@ -240,6 +281,10 @@ func TestMerge(t *testing.T) {
 		}
 	}
 
+	if len(configUser.Labels) != 2 || configUser.Labels["owner"] != "user" || configUser.Labels["env"] != "prod" {
+		t.Fatalf("Expected labels owner=user and env=prod, found %v", configUser.Labels)
+	}
+
 	ports, _, err := nat.ParsePortSpecs([]string{"0000"})
 	if err != nil {
 		t.Error(err)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/docker/docker/engine"
 	"github.com/docker/docker/nat"
+	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/utils"
 )
 
@@ -28,6 +29,77 @@ func (n NetworkMode) IsNone() bool {
 	return n == "none"
 }
 
+// IsBridge indicates whether the container uses the default bridge
+func (n NetworkMode) IsBridge() bool {
+	return n == "bridge" || n == ""
+}
+
+// IsUserDefined indicates whether the container is attached to a
+// user-defined network, named by the network mode
+func (n NetworkMode) IsUserDefined() bool {
+	return n.IsPrivate() && !n.IsBridge()
+}
+
+type IpcMode string
+
+// IsPrivate indicates whether container use it's private ipc stack
+func (n IpcMode) IsPrivate() bool {
+	return !(n.IsHost() || n.IsContainer())
+}
+
+func (n IpcMode) IsHost() bool {
+	return n == "host"
+}
+
+func (n IpcMode) IsContainer() bool {
+	parts := strings.SplitN(string(n), ":", 2)
+	return len(parts) > 1 && parts[0] == "container"
+}
+
+func (n IpcMode) Valid() bool {
+	parts := strings.Split(string(n), ":")
+	switch mode := parts[0]; mode {
+	case "", "host":
+	case "container":
+		if len(parts) != 2 || parts[1] == "" {
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// Container returns the name of the container whose ipc stack is shared
+func (n IpcMode) Container() string {
+	parts := strings.SplitN(string(n), ":", 2)
+	if len(parts) > 1 {
+		return parts[1]
+	}
+	return ""
+}
+
+type PidMode string
+
+// IsPrivate indicates whether container use it's private pid stack
+func (n PidMode) IsPrivate() bool {
+	return !(n.IsHost())
+}
+
+func (n PidMode) IsHost() bool {
+	return n == "host"
+}
+
+func (n PidMode) Valid() bool {
+	parts := strings.Split(string(n), ":")
+	switch mode := parts[0]; mode {
+	case "", "host":
+	default:
+		return false
+	}
+	return true
+}
+
 type DeviceMapping struct {
 	PathOnHost        string
 	PathInContainer   string
@@ -39,11 +111,20 @@ type RestartPolicy struct {
 	MaximumRetryCount int
 }
 
+// LogConfig selects the logging driver for a container's stdout and
+// stderr along with the driver's options
+type LogConfig struct {
+	Type   string
+	Config map[string]string
+}
+
 type HostConfig struct {
 	Binds           []string
+	VolumeDriver    string
 	ContainerIDFile string
 	LxcConf         []utils.KeyValuePair
 	Privileged      bool
+	ReadonlyRootfs  bool
 	PortBindings    nat.PortMap
 	Links           []string
 	PublishAllPorts bool
@@ -53,9 +134,14 @@ type HostConfig struct {
 	VolumesFrom     []string
 	Devices         []DeviceMapping
 	NetworkMode     NetworkMode
+	IpcMode         IpcMode
+	PidMode         PidMode
 	CapAdd          []string
 	CapDrop         []string
 	RestartPolicy   RestartPolicy
+	LogConfig       LogConfig
+	Ulimits         []*ulimit.Ulimit
+	StorageOpt      map[string]string
 }
 
 // This is used by the create command when you want to set both the
@@ -80,16 +166,23 @@ func ContainerHostConfigFromJob(job *engine.Job) *HostConfig {
 	}
 
 	hostConfig := &HostConfig{
+		VolumeDriver:    job.Getenv("VolumeDriver"),
 		ContainerIDFile: job.Getenv("ContainerIDFile"),
 		Privileged:      job.GetenvBool("Privileged"),
+		ReadonlyRootfs:  job.GetenvBool("ReadonlyRootfs"),
 		PublishAllPorts: job.GetenvBool("PublishAllPorts"),
 		NetworkMode:     NetworkMode(job.Getenv("NetworkMode")),
+		IpcMode:         IpcMode(job.Getenv("IpcMode")),
+		PidMode:         PidMode(job.Getenv("PidMode")),
 	}
 
 	job.GetenvJson("LxcConf", &hostConfig.LxcConf)
 	job.GetenvJson("PortBindings", &hostConfig.PortBindings)
 	job.GetenvJson("Devices", &hostConfig.Devices)
 	job.GetenvJson("RestartPolicy", &hostConfig.RestartPolicy)
+	job.GetenvJson("LogConfig", &hostConfig.LogConfig)
+	job.GetenvJson("Ulimits", &hostConfig.Ulimits)
+	job.GetenvJson("StorageOpt", &hostConfig.StorageOpt)
 	if Binds := job.GetenvList("Binds"); Binds != nil {
 		hostConfig.Binds = Binds
 	}
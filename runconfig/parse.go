@@ -5,12 +5,15 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/nat"
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/sysinfo"
+	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/pkg/units"
 	"github.com/docker/docker/utils"
 )
@@ -44,9 +47,14 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		flCapAdd      = opts.NewListOpts(nil)
 		flCapDrop     = opts.NewListOpts(nil)
 		flSecurityOpt = opts.NewListOpts(nil)
+		flLoggingOpts = opts.NewListOpts(nil)
+		flLabels      = opts.NewListOpts(nil)
+		flUlimits     = opts.NewUlimitOpt(make(map[string]*ulimit.Ulimit))
+		flStorageOpt  = opts.NewListOpts(nil)
 
 		flNetwork         = cmd.Bool([]string{"#n", "#-networking"}, true, "Enable networking for this container")
 		flPrivileged      = cmd.Bool([]string{"#privileged", "-privileged"}, false, "Give extended privileges to this container")
+		flReadonlyRootfs  = cmd.Bool([]string{"-read-only"}, false, "Mount the container's root filesystem as read only")
 		flPublishAll      = cmd.Bool([]string{"P", "-publish-all"}, false, "Publish all exposed ports to the host interfaces")
 		flStdin           = cmd.Bool([]string{"i", "-interactive"}, false, "Keep STDIN open even if not attached")
 		flTty             = cmd.Bool([]string{"t", "-tty"}, false, "Allocate a pseudo-TTY")
@@ -58,20 +66,31 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		flWorkingDir      = cmd.String([]string{"w", "-workdir"}, "", "Working directory inside the container")
 		flCpuShares       = cmd.Int64([]string{"c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
 		flCpuset          = cmd.String([]string{"-cpuset"}, "", "CPUs in which to allow execution (0-3, 0,1)")
-		flNetMode         = cmd.String([]string{"-net"}, "bridge", "Set the Network mode for the container\n'bridge': creates a new network stack for the container on the docker bridge\n'none': no networking for this container\n'container:<name|id>': reuses another container network stack\n'host': use the host network stack inside the container.  Note: the host mode gives the container full access to local system services such as D-bus and is therefore considered insecure.")
+		flNetMode         = cmd.String([]string{"-net"}, "bridge", "Set the Network mode for the container\n'bridge': creates a new network stack for the container on the docker bridge\n'none': no networking for this container\n'container:<name|id>': reuses another container network stack\n'host': use the host network stack inside the container.  Note: the host mode gives the container full access to local system services such as D-bus and is therefore considered insecure.\n'<network-name>': connects the container to a user-defined network created with 'docker network create'")
+		flIpcMode         = cmd.String([]string{"-ipc"}, "", "Default is to create a private IPC namespace (POSIX SysV IPC) for the container\n'container:<name|id>': reuses another container shared memory, semaphores and message queues\n'host': use the host shared memory, semaphores and message queues inside the container.  Note: the host mode gives the container full access to local shared memory and is therefore considered insecure.")
+		flPidMode         = cmd.String([]string{"-pid"}, "", "Default is to create a private PID namespace for the container\n'host': use the host PID namespace inside the container.  Note: the host mode gives the container full access to processes on the system and is therefore considered insecure.")
 		flRestartPolicy   = cmd.String([]string{"-restart"}, "", "Restart policy to apply when a container exits (no, on-failure[:max-retry], always)")
+		flLoggingDriver   = cmd.String([]string{"-log-driver"}, "", "Logging driver for the container (json-file, syslog, none), defaults to the daemon's")
+		flVolumeDriver    = cmd.String([]string{"-volume-driver"}, "", "Volume driver for the volumes created for the container (defaults to local)")
+		flHealthCmd       = cmd.String([]string{"-health-cmd"}, "", "Command to run to check health")
+		flHealthInterval  = cmd.Duration([]string{"-health-interval"}, 0, "Time between running the check (e.g. 30s, defaults to 30s)")
+		flHealthTimeout   = cmd.Duration([]string{"-health-timeout"}, 0, "Maximum time to allow one check to run (e.g. 30s, defaults to 30s)")
+		flHealthRetries   = cmd.Int([]string{"-health-retries"}, 0, "Consecutive failures needed to report unhealthy (defaults to 3)")
+		flNoHealthcheck   = cmd.Bool([]string{"-no-healthcheck"}, false, "Disable any container-specified HEALTHCHECK")
+		flStopSignal      = cmd.String([]string{"-stop-signal"}, "", "Signal to stop a container, SIGTERM by default")
 	)
 
 	cmd.Var(&flAttach, []string{"a", "-attach"}, "Attach to STDIN, STDOUT or STDERR.")
-	cmd.Var(&flVolumes, []string{"v", "-volume"}, "Bind mount a volume (e.g., from the host: -v /host:/container, from Docker: -v /container)")
+	cmd.Var(&flVolumes, []string{"v", "-volume"}, "Bind mount a volume (e.g., from the host: -v /host:/container, from a named volume: -v name:/container, from Docker: -v /container)")
 	cmd.Var(&flLinks, []string{"#link", "-link"}, "Add link to another container in the form of name:alias")
 	cmd.Var(&flDevices, []string{"-device"}, "Add a host device to the container (e.g. --device=/dev/sdc:/dev/xvdc)")
 
 	cmd.Var(&flEnv, []string{"e", "-env"}, "Set environment variables")
 	cmd.Var(&flEnvFile, []string{"-env-file"}, "Read in a line delimited file of environment variables")
+	cmd.Var(&flLabels, []string{"l", "-label"}, "Set metadata on the container (e.g. --label owner=web-team)")
 
-	cmd.Var(&flPublish, []string{"p", "-publish"}, fmt.Sprintf("Publish a container's port to the host\nformat: %s\n(use 'docker port' to see the actual mapping)", nat.PortSpecTemplateFormat))
-	cmd.Var(&flExpose, []string{"#expose", "-expose"}, "Expose a port from the container without publishing it to your host")
+	cmd.Var(&flPublish, []string{"p", "-publish"}, fmt.Sprintf("Publish a container's port(s) to the host\nformat: %s\nboth ports can be ranges, e.g. 8000-8100:8000-8100\n(use 'docker port' to see the actual mapping)", nat.PortSpecTemplateFormat))
+	cmd.Var(&flExpose, []string{"#expose", "-expose"}, "Expose a port or a range of ports from the container without publishing it to your host")
 	cmd.Var(&flDns, []string{"#dns", "-dns"}, "Set custom DNS servers")
 	cmd.Var(&flDnsSearch, []string{"-dns-search"}, "Set custom DNS search domains")
 	cmd.Var(&flExtraHosts, []string{"-add-host"}, "Add a custom host-to-IP mapping (host:ip)")
@@ -81,6 +100,9 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 	cmd.Var(&flCapAdd, []string{"-cap-add"}, "Add Linux capabilities")
 	cmd.Var(&flCapDrop, []string{"-cap-drop"}, "Drop Linux capabilities")
 	cmd.Var(&flSecurityOpt, []string{"-security-opt"}, "Security Options")
+	cmd.Var(&flLoggingOpts, []string{"-log-opt"}, "Log driver options (e.g. --log-opt max-size=10m)")
+	cmd.Var(flUlimits, []string{"-ulimit"}, "Ulimit options (e.g. --ulimit nofile=1024:2048)")
+	cmd.Var(&flStorageOpt, []string{"-storage-opt"}, "Storage driver options for the container (e.g. --storage-opt size=10G)")
 
 	if err := cmd.Parse(args); err != nil {
 		return nil, nil, cmd, err
@@ -147,6 +169,10 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 			if arr[1] == "/" {
 				return nil, nil, cmd, fmt.Errorf("Invalid bind mount: destination can't be '/'")
 			}
+			// a source which is not a host path is the name of a volume
+			if !path.IsAbs(arr[0]) && !utils.RestrictedNamePattern.MatchString(arr[0]) {
+				return nil, nil, cmd, fmt.Errorf("Invalid volume name: %s", arr[0])
+			}
 			// after creating the bind mount we want to delete it from the flVolumes values because
 			// we do not want bind mounts being committed to image configs
 			binds = append(binds, bind)
@@ -197,9 +223,24 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		if strings.Contains(e, ":") {
 			return nil, nil, cmd, fmt.Errorf("Invalid port format for --expose: %s", e)
 		}
-		p := nat.NewPort(nat.SplitProtoPort(e))
-		if _, exists := ports[p]; !exists {
-			ports[p] = struct{}{}
+		// a range of ports like 8000-8100 exposes every port of it
+		proto, port := nat.SplitProtoPort(e)
+		start, end, err := parsers.ParsePortRange(port)
+		if err != nil {
+			return nil, nil, cmd, fmt.Errorf("Invalid range format for --expose: %s, error: %s", e, err)
+		}
+		exposed := []string{port}
+		if strings.Contains(port, "-") {
+			exposed = nil
+			for i := start; i <= end; i++ {
+				exposed = append(exposed, strconv.FormatUint(i, 10))
+			}
+		}
+		for _, port := range exposed {
+			p := nat.NewPort(proto, port)
+			if _, exists := ports[p]; !exists {
+				ports[p] = struct{}{}
+			}
 		}
 	}
 
@@ -225,16 +266,52 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 	// parse the '-e' and '--env' after, to allow override
 	envVariables = append(envVariables, flEnv.GetAll()...)
 
+	labels, err := ParseLabels(flLabels.GetAll())
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
 	netMode, err := parseNetMode(*flNetMode)
 	if err != nil {
 		return nil, nil, cmd, fmt.Errorf("--net: invalid net mode: %v", err)
 	}
 
+	healthConfig, err := parseHealthConfig(*flHealthCmd, *flHealthInterval, *flHealthTimeout, *flHealthRetries, *flNoHealthcheck)
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
+	if *flStopSignal != "" {
+		if _, err := signal.ParseSignal(*flStopSignal); err != nil {
+			return nil, nil, cmd, fmt.Errorf("--stop-signal: %v", err)
+		}
+	}
+
+	ipcMode := IpcMode(*flIpcMode)
+	if !ipcMode.Valid() {
+		return nil, nil, cmd, fmt.Errorf("--ipc: invalid IPC mode")
+	}
+
+	pidMode := PidMode(*flPidMode)
+	if !pidMode.Valid() {
+		return nil, nil, cmd, fmt.Errorf("--pid: invalid PID mode")
+	}
+
 	restartPolicy, err := parseRestartPolicy(*flRestartPolicy)
 	if err != nil {
 		return nil, nil, cmd, err
 	}
 
+	loggingOpts, err := ParseLogOpts(flLoggingOpts.GetAll())
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
+	storageOpt, err := parseStorageOpts(flStorageOpt.GetAll())
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
 	config := &Config{
 		Hostname:        hostname,
 		Domainname:      domainname,
@@ -257,13 +334,18 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		Entrypoint:      entrypoint,
 		WorkingDir:      *flWorkingDir,
 		SecurityOpt:     flSecurityOpt.GetAll(),
+		Labels:          labels,
+		Healthcheck:     healthConfig,
+		StopSignal:      *flStopSignal,
 	}
 
 	hostConfig := &HostConfig{
 		Binds:           binds,
+		VolumeDriver:    *flVolumeDriver,
 		ContainerIDFile: *flContainerIDFile,
 		LxcConf:         lxcConf,
 		Privileged:      *flPrivileged,
+		ReadonlyRootfs:  *flReadonlyRootfs,
 		PortBindings:    portBindings,
 		Links:           flLinks.GetAll(),
 		PublishAllPorts: *flPublishAll,
@@ -272,10 +354,15 @@ func Parse(cmd *flag.FlagSet, args []string, sysInfo *sysinfo.SysInfo) (*Config,
 		ExtraHosts:      flExtraHosts.GetAll(),
 		VolumesFrom:     flVolumesFrom.GetAll(),
 		NetworkMode:     netMode,
+		IpcMode:         ipcMode,
+		PidMode:         pidMode,
 		Devices:         deviceMappings,
 		CapAdd:          flCapAdd.GetAll(),
 		CapDrop:         flCapDrop.GetAll(),
 		RestartPolicy:   restartPolicy,
+		LogConfig:       LogConfig{Type: *flLoggingDriver, Config: loggingOpts},
+		Ulimits:         flUlimits.GetList(),
+		StorageOpt:      storageOpt,
 	}
 
 	if sysInfo != nil && flMemory > 0 && !sysInfo.SwapLimit {
@@ -349,6 +436,58 @@ func parseDriverOpts(opts opts.ListOpts) (map[string][]string, error) {
 	return out, nil
 }
 
+// ParseLogOpts parses a list of log driver options in the key=value format
+func ParseLogOpts(opts []string) (map[string]string, error) {
+	out := make(map[string]string, len(opts))
+	for _, o := range opts {
+		k, v, err := parsers.ParseKeyValueOpt(o)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// parseStorageOpts parses a list of storage driver options in the key=value
+// format, like size=10G. The driver checks the options when it creates the
+// root filesystem of the container.
+func parseStorageOpts(storageOpts []string) (map[string]string, error) {
+	if len(storageOpts) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(storageOpts))
+	for _, o := range storageOpts {
+		k, v, err := parsers.ParseKeyValueOpt(o)
+		if err != nil {
+			return nil, fmt.Errorf("--storage-opt: %v", err)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// ParseLabels parses a list of labels in the key=value format. A label
+// given without a value is set to the empty string.
+func ParseLabels(labels []string) (map[string]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(labels))
+	for _, l := range labels {
+		parts := strings.SplitN(l, "=", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("Invalid label %q: the key can not be empty", l)
+		}
+		if len(parts) == 1 {
+			out[parts[0]] = ""
+		} else {
+			out[parts[0]] = parts[1]
+		}
+	}
+	return out, nil
+}
+
 func parseKeyValueOpts(opts opts.ListOpts) ([]utils.KeyValuePair, error) {
 	out := make([]utils.KeyValuePair, opts.Len())
 	for i, o := range opts.GetAll() {
@@ -370,11 +509,49 @@ func parseNetMode(netMode string) (NetworkMode, error) {
 			return "", fmt.Errorf("invalid container format container:<name|id>")
 		}
 	default:
-		return "", fmt.Errorf("invalid --net: %s", netMode)
+		// Anything else is the name of a user-defined network
+		if len(parts) > 1 || !utils.RestrictedNamePattern.MatchString(mode) {
+			return "", fmt.Errorf("invalid --net: %s", netMode)
+		}
 	}
 	return NetworkMode(netMode), nil
 }
 
+// parseHealthConfig returns the health check set with the --health-*
+// options, or nil if none is set so the image's check is used.
+func parseHealthConfig(command string, interval, timeout time.Duration, retries int, disable bool) (*HealthConfig, error) {
+	if disable {
+		if command != "" || interval != 0 || timeout != 0 || retries != 0 {
+			return nil, fmt.Errorf("--no-healthcheck conflicts with --health-* options")
+		}
+		return &HealthConfig{Test: []string{"NONE"}}, nil
+	}
+
+	if interval < 0 {
+		return nil, fmt.Errorf("--health-interval cannot be negative")
+	}
+	if timeout < 0 {
+		return nil, fmt.Errorf("--health-timeout cannot be negative")
+	}
+	if retries < 0 {
+		return nil, fmt.Errorf("--health-retries cannot be negative")
+	}
+
+	if command == "" && interval == 0 && timeout == 0 && retries == 0 {
+		return nil, nil
+	}
+
+	health := &HealthConfig{
+		Interval: interval,
+		Timeout:  timeout,
+		Retries:  retries,
+	}
+	if command != "" {
+		health.Test = []string{"CMD-SHELL", command}
+	}
+	return health, nil
+}
+
 func ParseDevice(device string) (DeviceMapping, error) {
 	src := ""
 	dst := ""
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"github.com/docker/docker/nat"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/sysinfo"
@@ -58,3 +59,98 @@ func TestNetHostname(t *testing.T) {
 		t.Fatalf("Expected error ErrConflictNetworkHostname, got: %s", err)
 	}
 }
+
+func TestNetUserDefined(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--net=staging", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !hostConfig.NetworkMode.IsUserDefined() || !hostConfig.NetworkMode.IsPrivate() {
+		t.Fatalf("Expected staging to be a user-defined network, got %s", hostConfig.NetworkMode)
+	}
+	if _, _, _, err := parseRun([]string{"--net=s", "img", "cmd"}, nil); err != nil {
+		t.Fatalf("Expected a network name of one character to be valid: %s", err)
+	}
+
+	for _, mode := range []string{"bridge", "host", "none", "container:other"} {
+		if NetworkMode(mode).IsUserDefined() {
+			t.Fatalf("Expected %s not to be a user-defined network", mode)
+		}
+	}
+
+	for _, net := range []string{"--net=staging:other", "--net=../staging"} {
+		if _, _, _, err := parseRun([]string{net, "img", "cmd"}, nil); err == nil {
+			t.Fatalf("Expected an error parsing %s", net)
+		}
+	}
+}
+
+func TestIpcAndPidModes(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--ipc=container:other", "--pid=host", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !hostConfig.IpcMode.IsContainer() || hostConfig.IpcMode.Container() != "other" {
+		t.Fatalf("Expected to share the ipc namespace of other, got %s", hostConfig.IpcMode)
+	}
+	if !hostConfig.PidMode.IsHost() {
+		t.Fatalf("Expected to use the host pid namespace, got %s", hostConfig.PidMode)
+	}
+
+	for _, args := range [][]string{
+		{"--ipc=container:", "img", "cmd"},
+		{"--ipc=bridge", "img", "cmd"},
+		{"--pid=container:other", "img", "cmd"},
+	} {
+		if _, _, _, err := parseRun(args, nil); err == nil {
+			t.Fatalf("Expected an error parsing %v", args)
+		}
+	}
+}
+
+func TestParseStopSignal(t *testing.T) {
+	config, _, _, err := parseRun([]string{"--stop-signal=SIGQUIT", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if config.StopSignal != "SIGQUIT" {
+		t.Fatalf("Expected the stop signal to be SIGQUIT, got %s", config.StopSignal)
+	}
+
+	if _, _, _, err := parseRun([]string{"--stop-signal=SIGNOTASIGNAL", "img", "cmd"}, nil); err == nil {
+		t.Fatal("Expected an error parsing an invalid stop signal")
+	}
+}
+
+func TestParseStorageOpt(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--storage-opt", "size=10G", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if size := hostConfig.StorageOpt["size"]; size != "10G" {
+		t.Fatalf("Expected the size storage option to be 10G, got %q", size)
+	}
+
+	if _, _, _, err := parseRun([]string{"--storage-opt", "size", "img", "cmd"}, nil); err == nil {
+		t.Fatal("Expected an error parsing a storage option without a value")
+	}
+}
+
+func TestParseExposeRange(t *testing.T) {
+	config, _, _, err := parseRun([]string{"--expose", "8000-8002/udp", "img", "cmd"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(config.ExposedPorts) != 3 {
+		t.Fatalf("Expected 3 exposed ports, got %v", config.ExposedPorts)
+	}
+	for _, port := range []nat.Port{"8000/udp", "8001/udp", "8002/udp"} {
+		if _, exists := config.ExposedPorts[port]; !exists {
+			t.Fatalf("Expected %s to be exposed, got %v", port, config.ExposedPorts)
+		}
+	}
+
+	if _, _, _, err := parseRun([]string{"--expose", "8002-8000", "img", "cmd"}, nil); err == nil {
+		t.Fatal("Expected an error parsing an invalid port range")
+	}
+}
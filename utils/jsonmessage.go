@@ -80,9 +80,13 @@ type JSONMessage struct {
 	ProgressMessage string        `json:"progress,omitempty"` //deprecated
 	ID              string        `json:"id,omitempty"`
 	From            string        `json:"from,omitempty"`
+	Name            string        `json:"name,omitempty"`
 	Time            int64         `json:"time,omitempty"`
 	Error           *JSONError    `json:"errorDetail,omitempty"`
 	ErrorMessage    string        `json:"error,omitempty"` //deprecated
+	// Labels of the container an event is about. They are only used to
+	// filter events, and are not sent to clients.
+	Labels map[string]string `json:"-"`
 }
 
 func (jm *JSONMessage) Display(out io.Writer, isTerminal bool) error {
@@ -0,0 +1,10 @@
+package utils
+
+import "regexp"
+
+// RestrictedNameChars are the characters allowed in the names of volumes and
+// networks: an alphanumeric character, followed by any of these.
+const RestrictedNameChars = `[a-zA-Z0-9][a-zA-Z0-9_.-]`
+
+// RestrictedNamePattern matches the names made of RestrictedNameChars.
+var RestrictedNamePattern = regexp.MustCompile(`^` + RestrictedNameChars + `*$`)
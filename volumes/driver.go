@@ -0,0 +1,137 @@
+package volumes
+
+import (
+	"fmt"
+	"net"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/daemon/graphdriver"
+)
+
+// DefaultDriver is the name of the built-in volume driver, used when a
+// volume does not ask for a specific one.
+const DefaultDriver = "local"
+
+// Driver is implemented by volume drivers. Volumes are referred to by their
+// name; Mount makes the volume available on the host and returns the path
+// to bind-mount into containers, Path returns that same path without
+// mounting anything.
+type Driver interface {
+	Create(name string) error
+	Remove(name string) error
+	Path(name string) (string, error)
+	Mount(name string) (string, error)
+	Unmount(name string) error
+}
+
+// localDriver is the built-in driver. It keeps volume data on the host
+// through a graphdriver, which has nothing to mount.
+type localDriver struct {
+	driver graphdriver.Driver
+}
+
+func (d *localDriver) Create(name string) error {
+	return d.driver.Create(name, "", nil)
+}
+
+func (d *localDriver) Remove(name string) error {
+	return d.driver.Remove(name)
+}
+
+func (d *localDriver) Path(name string) (string, error) {
+	path, err := d.driver.Get(name, "")
+	if err != nil {
+		return "", fmt.Errorf("Driver %s failed to get volume rootfs %s: %s", d.driver, name, err)
+	}
+	return path, nil
+}
+
+func (d *localDriver) Mount(name string) (string, error) {
+	return d.Path(name)
+}
+
+func (d *localDriver) Unmount(name string) error {
+	d.driver.Put(name)
+	return nil
+}
+
+// PluginsDir is where volume plugins put their sockets. A plugin named
+// "foo" serves JSON-RPC requests on PluginsDir/foo.sock.
+var PluginsDir = "/run/docker/plugins"
+
+// pluginTimeout bounds every call to a plugin so that a stuck plugin does
+// not hang the daemon.
+const pluginTimeout = 2 * time.Minute
+
+// PluginRequest is the argument of every VolumeDriver.* JSON-RPC method.
+type PluginRequest struct {
+	Name string
+}
+
+// PluginResponse is the reply to every VolumeDriver.* JSON-RPC method.
+// Mountpoint is only set by Path and Mount.
+type PluginResponse struct {
+	Mountpoint string
+}
+
+// pluginDriver forwards the driver calls to an out-of-process plugin over
+// its unix socket, as the methods VolumeDriver.Create, VolumeDriver.Remove,
+// VolumeDriver.Path, VolumeDriver.Mount and VolumeDriver.Unmount.
+type pluginDriver struct {
+	name string
+	addr string
+}
+
+func lookupPlugin(name string) (Driver, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	addr := filepath.Join(PluginsDir, name+".sock")
+	if _, err := os.Stat(addr); err != nil {
+		return nil, fmt.Errorf("No such volume driver: %s", name)
+	}
+	return &pluginDriver{name: name, addr: addr}, nil
+}
+
+func (p *pluginDriver) call(method, name string) (string, error) {
+	conn, err := net.DialTimeout("unix", p.addr, pluginTimeout)
+	if err != nil {
+		return "", fmt.Errorf("Volume driver %s is not available: %s", p.name, err)
+	}
+	conn.SetDeadline(time.Now().Add(pluginTimeout))
+	client := jsonrpc.NewClient(conn)
+	defer client.Close()
+
+	var res PluginResponse
+	if err := client.Call("VolumeDriver."+method, &PluginRequest{Name: name}, &res); err != nil {
+		return "", fmt.Errorf("Volume driver %s failed to %s volume %s: %s", p.name, strings.ToLower(method), name, err)
+	}
+	return res.Mountpoint, nil
+}
+
+func (p *pluginDriver) Create(name string) error {
+	_, err := p.call("Create", name)
+	return err
+}
+
+func (p *pluginDriver) Remove(name string) error {
+	_, err := p.call("Remove", name)
+	return err
+}
+
+func (p *pluginDriver) Path(name string) (string, error) {
+	return p.call("Path", name)
+}
+
+func (p *pluginDriver) Mount(name string) (string, error) {
+	return p.call("Mount", name)
+}
+
+func (p *pluginDriver) Unmount(name string) error {
+	_, err := p.call("Unmount", name)
+	return err
+}
@@ -0,0 +1,366 @@
+package volumes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPlugin is a stand-in volume plugin keeping its volumes in
+// directories under root and counting the mounts of each volume. If
+// blockCreate is set, Create sends the name of the volume on creating and
+// waits for blockCreate to be closed. Remove fails while failRemove is set.
+type testPlugin struct {
+	root        string
+	lock        sync.Mutex
+	mounts      map[string]int
+	creating    chan string
+	blockCreate chan struct{}
+	failRemove  bool
+}
+
+func (p *testPlugin) path(name string) string {
+	return filepath.Join(p.root, name)
+}
+
+func (p *testPlugin) Create(req *PluginRequest, res *PluginResponse) error {
+	p.lock.Lock()
+	creating, block := p.creating, p.blockCreate
+	p.lock.Unlock()
+	if block != nil {
+		creating <- req.Name
+		<-block
+	}
+	return os.MkdirAll(p.path(req.Name), 0755)
+}
+
+func (p *testPlugin) Remove(req *PluginRequest, res *PluginResponse) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.failRemove {
+		return fmt.Errorf("volume %s is busy", req.Name)
+	}
+	return os.RemoveAll(p.path(req.Name))
+}
+
+func (p *testPlugin) Path(req *PluginRequest, res *PluginResponse) error {
+	if _, err := os.Stat(p.path(req.Name)); err != nil {
+		return err
+	}
+	res.Mountpoint = p.path(req.Name)
+	return nil
+}
+
+func (p *testPlugin) Mount(req *PluginRequest, res *PluginResponse) error {
+	p.lock.Lock()
+	p.mounts[req.Name]++
+	p.lock.Unlock()
+	return p.Path(req, res)
+}
+
+func (p *testPlugin) Unmount(req *PluginRequest, res *PluginResponse) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.mounts[req.Name] == 0 {
+		return fmt.Errorf("volume %s is not mounted", req.Name)
+	}
+	p.mounts[req.Name]--
+	return nil
+}
+
+func (p *testPlugin) mounted(name string) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.mounts[name]
+}
+
+func serveTestPlugin(t *testing.T, root, name string) (*testPlugin, net.Listener) {
+	plugin := &testPlugin{root: filepath.Join(root, name), mounts: make(map[string]int)}
+	if err := os.MkdirAll(plugin.root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("VolumeDriver", plugin); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("unix", filepath.Join(PluginsDir, name+".sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	return plugin, l
+}
+
+func TestPluginVolumeDriver(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	defer func(dir string) { PluginsDir = dir }(PluginsDir)
+	PluginsDir = filepath.Join(root, "plugins")
+	if err := os.MkdirAll(PluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	plugin, l := serveTestPlugin(t, root, "test")
+	defer l.Close()
+
+	repo := newTestRepository(t, root)
+	if _, err := repo.Create("data", "missing"); err == nil {
+		t.Fatal("Expected an error creating a volume with an unknown driver")
+	}
+
+	v, err := repo.Create("data", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.DriverName() != "test" || v.Path != plugin.path("data") {
+		t.Fatalf("Expected volume at %s from driver test, got %s from %s", plugin.path("data"), v.Path, v.DriverName())
+	}
+
+	path, err := v.Mount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != v.Path {
+		t.Fatalf("Expected mountpoint %s, got %s", v.Path, path)
+	}
+	if n := plugin.mounted("data"); n != 1 {
+		t.Fatalf("Expected the volume to be mounted once, got %d", n)
+	}
+	if err := v.Unmount(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Unmount(); err == nil {
+		t.Fatal("Expected the plugin error to be returned when unmounting twice")
+	}
+
+	restored := newTestRepository(t, root)
+	rv := restored.GetByName("data")
+	if rv == nil || rv.DriverName() != "test" || rv.Path != v.Path {
+		t.Fatal("Expected the plugin volume to be restored")
+	}
+	if err := restored.Delete(rv.Path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(plugin.path("data")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the plugin to remove the volume data, got %v", err)
+	}
+}
+
+func TestRestorePluginVolume(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	defer func(dir string) { PluginsDir = dir }(PluginsDir)
+	PluginsDir = filepath.Join(root, "plugins")
+	if err := os.MkdirAll(PluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	plugin, l := serveTestPlugin(t, root, "test")
+
+	repo := newTestRepository(t, root)
+	v, err := repo.Create("data", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Path != plugin.path("data") {
+		t.Fatalf("Expected volume at %s, got %s", plugin.path("data"), v.Path)
+	}
+	l.Close()
+
+	// the plugin is down when the daemon starts
+	restored := newTestRepository(t, root)
+	rv := restored.GetByName("data")
+	if rv == nil || rv.DriverName() != "test" || rv.Path != v.Path {
+		t.Fatal("Expected the volume of a plugin that is down to be restored")
+	}
+	if restored.Get(v.Path) != rv {
+		t.Fatalf("Expected to find the volume by its path %s", v.Path)
+	}
+	if _, err := rv.Mount(); err == nil {
+		t.Fatal("Expected an error mounting the volume of a plugin that is down")
+	}
+
+	// the plugin comes up with the volume somewhere else
+	moved, l := serveTestPlugin(t, filepath.Join(root, "moved"), "test")
+	defer l.Close()
+	if err := os.Mkdir(moved.path("data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path, err := rv.Mount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != moved.path("data") || rv.Path != path {
+		t.Fatalf("Expected the volume to be mounted at %s, got %s (%s)", moved.path("data"), path, rv.Path)
+	}
+	if restored.Get(path) != rv || restored.Get(v.Path) != nil {
+		t.Fatalf("Expected the volume to be found by its new path %s only", path)
+	}
+	if err := rv.Unmount(); err != nil {
+		t.Fatal(err)
+	}
+
+	if rv = newTestRepository(t, root).GetByName("data"); rv == nil || rv.Path != path {
+		t.Fatalf("Expected the new path %s of the volume to be restored, got %v", path, rv)
+	}
+}
+
+func TestPluginCallsDontLockRepository(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	defer func(dir string) { PluginsDir = dir }(PluginsDir)
+	PluginsDir = filepath.Join(root, "plugins")
+	if err := os.MkdirAll(PluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	plugin, l := serveTestPlugin(t, root, "test")
+	defer l.Close()
+	repo := newTestRepository(t, root)
+
+	block := make(chan struct{})
+	plugin.lock.Lock()
+	plugin.creating, plugin.blockCreate = make(chan string, 2), block
+	plugin.lock.Unlock()
+	created := make(chan *Volume)
+	for i := 0; i < 2; i++ {
+		go func() {
+			v, err := repo.FindOrCreateNamedVolume("data", "test")
+			if err != nil {
+				t.Error(err)
+			}
+			created <- v
+		}()
+	}
+
+	// the repository can be used while the plugin is creating the volume
+	<-plugin.creating
+	listed := make(chan []*Volume)
+	go func() { listed <- repo.List() }()
+	select {
+	case volumes := <-listed:
+		if len(volumes) != 0 {
+			t.Fatalf("Expected the volume being created not to be listed, got %v", volumes)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Listing the volumes blocked on the plugin")
+	}
+
+	close(block)
+	if v1, v2 := <-created, <-created; v1 == nil || v1 != v2 {
+		t.Fatalf("Expected a single volume to be created, got %v and %v", v1, v2)
+	}
+	if n := len(plugin.creating); n != 0 {
+		t.Fatalf("Expected the plugin to create the volume once, got %d more calls", n)
+	}
+}
+
+func TestPluginVolumeErrors(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	defer func(dir string) { PluginsDir = dir }(PluginsDir)
+	PluginsDir = filepath.Join(root, "plugins")
+	if err := os.MkdirAll(PluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	plugin, l := serveTestPlugin(t, root, "test")
+	defer l.Close()
+	repo := newTestRepository(t, root)
+
+	// a volume whose data the plugin fails to remove is kept
+	v, err := repo.Create("data", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin.lock.Lock()
+	plugin.failRemove = true
+	plugin.lock.Unlock()
+	if err := repo.Delete(v.Path); err == nil {
+		t.Fatal("Expected the error of the plugin to be returned")
+	}
+	if repo.GetByName("data") != v {
+		t.Fatal("Expected the volume to be kept")
+	}
+	if _, err := os.Stat(v.configPath); err != nil {
+		t.Fatalf("Expected the configuration of the volume to be kept: %s", err)
+	}
+	plugin.lock.Lock()
+	plugin.failRemove = false
+	plugin.lock.Unlock()
+	if err := repo.Delete(v.Path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(v.configPath); !os.IsNotExist(err) {
+		t.Fatalf("Expected the configuration of the volume to be removed, got %v", err)
+	}
+
+	// the data the plugin created is removed if the volume can't be added,
+	// here because its path is bind-mounted already
+	if err := os.MkdirAll(plugin.path("taken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindOrCreateVolume(plugin.path("taken"), "", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create("taken", "test"); err == nil {
+		t.Fatal("Expected an error creating a volume at the path of another")
+	}
+	if _, err := os.Stat(plugin.path("taken")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the plugin to be asked to remove the volume, got %v", err)
+	}
+}
+
+func TestLocalVolumeDriver(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	repo := newTestRepository(t, root)
+
+	v, err := repo.Create("data", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.DriverName() != DefaultDriver {
+		t.Fatalf("Expected driver %s, got %s", DefaultDriver, v.DriverName())
+	}
+	path, err := v.Mount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != v.Path {
+		t.Fatalf("Expected mountpoint %s, got %s", v.Path, path)
+	}
+	if err := v.Unmount(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -8,13 +8,15 @@ import (
 	"sync"
 
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/pkg/log"
 	"github.com/docker/docker/utils"
 )
 
 type Repository struct {
 	configPath string
-	driver     graphdriver.Driver
+	local      Driver
 	volumes    map[string]*Volume
+	pending    map[string]chan struct{} // The names of the volumes being created or removed
 	lock       sync.Mutex
 }
 
@@ -30,51 +32,123 @@ func NewRepository(configPath string, driver graphdriver.Driver) (*Repository, e
 	}
 
 	repo := &Repository{
-		driver:     driver,
+		local:      &localDriver{driver},
 		configPath: abspath,
 		volumes:    make(map[string]*Volume),
+		pending:    make(map[string]chan struct{}),
 	}
 
 	return repo, repo.restore()
 }
 
-func (r *Repository) newVolume(path string, writable bool) (*Volume, error) {
-	var (
-		isBindMount bool
-		err         error
-		id          = utils.GenerateRandomID()
-	)
-	if path != "" {
-		isBindMount = true
+// getDriver returns the volume driver with the given name, looking for a
+// plugin unless it is the built-in driver.
+func (r *Repository) getDriver(name string) (Driver, error) {
+	if name == "" || name == DefaultDriver {
+		return r.local, nil
 	}
+	return lookupPlugin(name)
+}
 
-	if path == "" {
-		path, err = r.createNewVolumePath(id)
-		if err != nil {
-			return nil, err
-		}
+// newBindMount adds the bind-mount volume of path. r must be locked.
+func (r *Repository) newBindMount(path string, writable bool) (*Volume, error) {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	v := r.newVolumeConfig(utils.GenerateRandomID(), path, "", "", writable)
+	v.IsBindMount = true
+	if err := v.initialize(); err != nil {
+		return nil, err
 	}
+	return v, r.add(v)
+}
 
-	path, err = filepath.EvalSymlinks(path)
+// newVolume creates a volume with the given driver, named name unless it is
+// anonymous, and adds it to the repository. A plugin may take long to
+// answer, so the driver is called without r.lock: the name of a named
+// volume must have been reserved with r.reserve.
+func (r *Repository) newVolume(name, driverName string, writable bool) (*Volume, error) {
+	if driverName == "" {
+		driverName = DefaultDriver
+	}
+	driver, err := r.getDriver(driverName)
 	if err != nil {
 		return nil, err
 	}
 
-	v := &Volume{
-		ID:          id,
-		Path:        path,
-		repository:  r,
-		Writable:    writable,
-		containers:  make(map[string]struct{}),
-		configPath:  r.configPath + "/" + id,
-		IsBindMount: isBindMount,
+	id := utils.GenerateRandomID()
+	driverVolumeName := name
+	if driverVolumeName == "" {
+		driverVolumeName = id
+	}
+	if err := driver.Create(driverVolumeName); err != nil {
+		return nil, err
 	}
+	v, err := r.addCreatedVolume(driver, id, name, driverName, writable)
+	if err != nil {
+		// don't leave the data of a volume nothing refers to with the driver
+		if err := driver.Remove(driverVolumeName); err != nil {
+			log.Errorf("Error removing volume %s from driver %s: %s", driverVolumeName, driverName, err)
+		}
+		return nil, err
+	}
+	return v, nil
+}
 
-	if err := v.initialize(); err != nil {
+// addCreatedVolume records the volume its driver created, and adds it to
+// the repository.
+func (r *Repository) addCreatedVolume(driver Driver, id, name, driverName string, writable bool) (*Volume, error) {
+	v := r.newVolumeConfig(id, "", name, driverName, writable)
+	path, err := driver.Path(v.DisplayName())
+	if err != nil {
+		return nil, err
+	}
+	if v.Path, err = filepath.EvalSymlinks(path); err != nil {
 		return nil, err
 	}
 
-	return v, r.add(v)
+	if err = v.initialize(); err == nil {
+		r.lock.Lock()
+		err = r.add(v)
+		r.lock.Unlock()
+	}
+	if err != nil {
+		os.RemoveAll(v.configPath)
+		return nil, err
+	}
+	return v, nil
+}
+
+func (r *Repository) newVolumeConfig(id, path, name, driverName string, writable bool) *Volume {
+	return &Volume{
+		ID:         id,
+		Name:       name,
+		Driver:     driverName,
+		Path:       path,
+		repository: r,
+		Writable:   writable,
+		containers: make(map[string]struct{}),
+		configPath: r.configPath + "/" + id,
+	}
+}
+
+// reserve marks the volume name as being created or removed until release
+// is called, or returns a channel closed once the pending operation on name
+// is done. r must be locked.
+func (r *Repository) reserve(name string) (bool, <-chan struct{}) {
+	if c, exists := r.pending[name]; exists {
+		return false, c
+	}
+	r.pending[name] = make(chan struct{})
+	return true, nil
+}
+
+func (r *Repository) release(name string) {
+	r.lock.Lock()
+	close(r.pending[name])
+	delete(r.pending, name)
+	r.lock.Unlock()
 }
 
 func (r *Repository) restore() error {
@@ -83,11 +157,22 @@ func (r *Repository) restore() error {
 		return err
 	}
 
-	var ids []string
 	for _, v := range dir {
 		id := v.Name()
-		if r.driver.Exists(id) {
-			ids = append(ids, id)
+		vol := &Volume{
+			ID:         id,
+			repository: r,
+			containers: make(map[string]struct{}),
+			configPath: r.configPath + "/" + id,
+		}
+		// The recorded path is trusted without asking the driver, a plugin
+		// may not be up yet. Mount resolves it when the volume is used.
+		if err := vol.FromDisk(); err != nil {
+			log.Debugf("Error restoring volume %s: %s", id, err)
+			continue
+		}
+		if err := r.add(vol); err != nil {
+			log.Debugf("Error restoring volume %s: %s", id, err)
 		}
 	}
 	return nil
@@ -101,6 +186,10 @@ func (r *Repository) Get(path string) *Volume {
 }
 
 func (r *Repository) get(path string) *Volume {
+	// the path of a volume whose driver isn't up may not exist
+	if vol, exists := r.volumes[path]; exists {
+		return vol
+	}
 	path, err := filepath.EvalSymlinks(path)
 	if err != nil {
 		return nil
@@ -108,6 +197,42 @@ func (r *Repository) get(path string) *Volume {
 	return r.volumes[path]
 }
 
+// GetByName returns the volume with the given name or ID, or nil if no
+// such volume exists. Bind-mounts are never returned.
+func (r *Repository) GetByName(name string) *Volume {
+	r.lock.Lock()
+	vol := r.getByName(name)
+	r.lock.Unlock()
+	return vol
+}
+
+func (r *Repository) getByName(name string) *Volume {
+	for _, vol := range r.volumes {
+		if vol.IsBindMount {
+			continue
+		}
+		if vol.Name == name || vol.ID == name {
+			return vol
+		}
+	}
+	return nil
+}
+
+// List returns all the volumes managed by the repository, leaving out
+// bind-mounts.
+func (r *Repository) List() []*Volume {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var volumes []*Volume
+	for _, vol := range r.volumes {
+		if !vol.IsBindMount {
+			volumes = append(volumes, vol)
+		}
+	}
+	return volumes
+}
+
 func (r *Repository) Add(volume *Volume) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -122,6 +247,28 @@ func (r *Repository) add(volume *Volume) error {
 	return nil
 }
 
+// move records that the driver of volume now has it at path.
+func (r *Repository) move(volume *Volume, path string) error {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if path == volume.Path {
+		return nil
+	}
+	if vol := r.get(path); vol != nil {
+		return fmt.Errorf("Volume exists: %s", vol.ID)
+	}
+	log.Debugf("Volume %s moved from %s to %s", volume.DisplayName(), volume.Path, path)
+	delete(r.volumes, volume.Path)
+	volume.Path = path
+	r.volumes[path] = volume
+	return volume.ToDisk()
+}
+
 func (r *Repository) Remove(volume *Volume) {
 	r.lock.Lock()
 	r.remove(volume)
@@ -132,64 +279,121 @@ func (r *Repository) remove(volume *Volume) {
 	delete(r.volumes, volume.Path)
 }
 
+// Delete removes the volume at path, and its data with its driver. The
+// driver is called without r.lock, the volume is left out of the repository
+// meanwhile.
 func (r *Repository) Delete(path string) error {
 	r.lock.Lock()
-	defer r.lock.Unlock()
-	path, err := filepath.EvalSymlinks(path)
-	if err != nil {
-		return err
-	}
 	volume := r.get(path)
 	if volume == nil {
+		r.lock.Unlock()
 		return fmt.Errorf("Volume %s does not exist", path)
 	}
 
 	if volume.IsBindMount {
+		r.lock.Unlock()
 		return fmt.Errorf("Volume %s is a bind-mount and cannot be removed", volume.Path)
 	}
 	containers := volume.Containers()
 	if len(containers) > 0 {
+		r.lock.Unlock()
 		return fmt.Errorf("Volume %s is being used and cannot be removed: used by containers %s", volume.Path, containers)
 	}
-
-	if err := os.RemoveAll(volume.configPath); err != nil {
-		return err
+	name := volume.DisplayName()
+	if reserved, _ := r.reserve(name); !reserved {
+		r.lock.Unlock()
+		return fmt.Errorf("Volume %s is being created or removed", name)
 	}
+	r.remove(volume)
+	r.lock.Unlock()
+	defer r.release(name)
 
-	if err := r.driver.Remove(volume.ID); err != nil {
-		if !os.IsNotExist(err) {
-			return err
+	driver, err := r.getDriver(volume.Driver)
+	if err == nil {
+		if err = driver.Remove(name); os.IsNotExist(err) {
+			err = nil
 		}
 	}
+	if err != nil {
+		r.lock.Lock()
+		r.volumes[volume.Path] = volume
+		r.lock.Unlock()
+		return err
+	}
 
-	r.remove(volume)
-	return nil
+	// the configuration goes last, so that a volume whose data couldn't be
+	// removed is still known after a restart
+	return os.RemoveAll(volume.configPath)
 }
 
-func (r *Repository) createNewVolumePath(id string) (string, error) {
-	if err := r.driver.Create(id, ""); err != nil {
-		return "", err
+// FindOrCreateVolume returns the bind-mount volume for path, creating it if
+// needed. An empty path creates a new anonymous volume with the given driver.
+func (r *Repository) FindOrCreateVolume(path, driverName string, writable bool) (*Volume, error) {
+	if path == "" {
+		return r.newVolume("", driverName, writable)
 	}
 
-	path, err := r.driver.Get(id, "")
-	if err != nil {
-		return "", fmt.Errorf("Driver %s failed to get volume rootfs %s: %s", r.driver, id, err)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if v := r.get(path); v != nil {
+		return v, nil
 	}
-
-	return path, nil
+	return r.newBindMount(path, writable)
 }
 
-func (r *Repository) FindOrCreateVolume(path string, writable bool) (*Volume, error) {
+// Create creates a new volume with the given name and driver. If name is
+// empty the volume is only known by its ID.
+func (r *Repository) Create(name, driverName string) (*Volume, error) {
+	if name == "" {
+		return r.newVolume("", driverName, true)
+	}
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
 	r.lock.Lock()
-	defer r.lock.Unlock()
+	if r.getByName(name) != nil {
+		r.lock.Unlock()
+		return nil, fmt.Errorf("Conflict, volume %s already exists", name)
+	}
+	if reserved, _ := r.reserve(name); !reserved {
+		r.lock.Unlock()
+		return nil, fmt.Errorf("Conflict, volume %s is being created or removed", name)
+	}
+	r.lock.Unlock()
+	defer r.release(name)
 
-	if path == "" {
-		return r.newVolume(path, writable)
+	return r.newVolume(name, driverName, true)
+}
+
+// FindOrCreateNamedVolume returns the volume with the given name or ID,
+// creating a new named volume with the given driver if none exists.
+func (r *Repository) FindOrCreateNamedVolume(name, driverName string) (*Volume, error) {
+	for {
+		r.lock.Lock()
+		if v := r.getByName(name); v != nil {
+			r.lock.Unlock()
+			return v, nil
+		}
+		reserved, done := r.reserve(name)
+		r.lock.Unlock()
+		if reserved {
+			break
+		}
+		// look again once the volume is created or removed
+		<-done
 	}
+	defer r.release(name)
 
-	if v := r.get(path); v != nil {
-		return v, nil
+	if err := validateName(name); err != nil {
+		return nil, err
 	}
+	return r.newVolume(name, driverName, true)
+}
 
-	return r.newVolume(path, writable)
+func validateName(name string) error {
+	if !utils.RestrictedNamePattern.MatchString(name) {
+		return fmt.Errorf("Invalid volume name (%s), only %s are allowed", name, utils.RestrictedNameChars)
+	}
+	return nil
 }
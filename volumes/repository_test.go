@@ -0,0 +1,106 @@
+package volumes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver/vfs"
+)
+
+func newTestRepository(t *testing.T, root string) *Repository {
+	driver, err := vfs.Init(filepath.Join(root, "vfs"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := NewRepository(filepath.Join(root, "volumes"), driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestCreateNamedVolume(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	repo := newTestRepository(t, root)
+
+	v, err := repo.Create("data", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.DisplayName() != "data" {
+		t.Fatalf("Expected volume name data, got %s", v.DisplayName())
+	}
+	if _, err := repo.Create("data", ""); err == nil {
+		t.Fatal("Expected an error creating a volume with a name already in use")
+	}
+	if _, err := repo.Create("../data", ""); err == nil {
+		t.Fatal("Expected an error creating a volume with an invalid name")
+	}
+	if _, err := repo.Create("d", ""); err != nil {
+		t.Fatalf("Expected a volume name of one character to be valid: %s", err)
+	}
+
+	found, err := repo.FindOrCreateNamedVolume("data", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != v {
+		t.Fatalf("Expected to find volume %s, got %s", v.ID, found.ID)
+	}
+	if repo.GetByName(v.ID) != v {
+		t.Fatal("Expected to find the volume by its ID")
+	}
+}
+
+func TestListAndRestoreVolumes(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-volumes-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	repo := newTestRepository(t, root)
+
+	named, err := repo.Create("data", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindOrCreateVolume("", "", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindOrCreateVolume(root, "", true); err != nil {
+		t.Fatal(err)
+	}
+	if volumes := repo.List(); len(volumes) != 2 {
+		t.Fatalf("Expected 2 volumes, bind-mounts excluded, got %d", len(volumes))
+	}
+
+	restored := newTestRepository(t, root)
+	if volumes := restored.List(); len(volumes) != 2 {
+		t.Fatalf("Expected 2 restored volumes, got %d", len(volumes))
+	}
+	v := restored.GetByName("data")
+	if v == nil {
+		t.Fatal("Expected named volume to be restored")
+	}
+	if v.ID != named.ID || v.Path != named.Path {
+		t.Fatalf("Restored volume %s (%s) does not match %s (%s)", v.ID, v.Path, named.ID, named.Path)
+	}
+
+	v.AddContainer("container")
+	if err := restored.Delete(v.Path); err == nil {
+		t.Fatal("Expected an error removing a volume in use")
+	}
+	v.RemoveContainer("container")
+	if err := restored.Delete(v.Path); err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetByName("data") != nil {
+		t.Fatal("Expected volume to be removed")
+	}
+}
@@ -0,0 +1,128 @@
+package volumes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/engine"
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+func (r *Repository) Install(eng *engine.Engine) error {
+	for name, handler := range map[string]engine.Handler{
+		"volume_create":  r.CmdCreate,
+		"volume_inspect": r.CmdInspect,
+		"volume_rm":      r.CmdRm,
+		"volumes":        r.CmdList,
+	} {
+		if err := eng.Register(name, handler); err != nil {
+			return fmt.Errorf("Could not register %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// CmdCreate creates a new volume. The name is optional; without it the
+// volume is only known by its ID. The volume driver is taken from the
+// Driver environment variable, defaulting to the local driver.
+func (r *Repository) CmdCreate(job *engine.Job) engine.Status {
+	if len(job.Args) > 1 {
+		return job.Errorf("usage: %s [NAME]", job.Name)
+	}
+	var name string
+	if len(job.Args) == 1 {
+		name = job.Args[0]
+	}
+	v, err := r.Create(name, job.Getenv("Driver"))
+	if err != nil {
+		return job.Error(err)
+	}
+	if _, err := volumeEnv(v).WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func (r *Repository) CmdInspect(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("usage: %s NAME", job.Name)
+	}
+	name := job.Args[0]
+	v := r.GetByName(name)
+	if v == nil {
+		return job.Errorf("No such volume: %s", name)
+	}
+	if _, err := volumeEnv(v).WriteTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// CmdRm removes a volume and its data. Volumes still used by a container
+// are not removed.
+func (r *Repository) CmdRm(job *engine.Job) engine.Status {
+	if len(job.Args) != 1 {
+		return job.Errorf("usage: %s NAME", job.Name)
+	}
+	name := job.Args[0]
+	v := r.GetByName(name)
+	if v == nil {
+		return job.Errorf("No such volume: %s", name)
+	}
+	if containers := v.Containers(); len(containers) > 0 {
+		return job.Errorf("Conflict, volume %s is being used and cannot be removed: used by containers %s", name, containers)
+	}
+	if err := r.Delete(v.Path); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+// CmdList lists the volumes. The "dangling" filter restricts the list to
+// volumes which are (true) or are not (false) unused by any container.
+func (r *Repository) CmdList(job *engine.Job) engine.Status {
+	volFilters, err := filters.FromParam(job.Getenv("filters"))
+	if err != nil {
+		return job.Error(err)
+	}
+	var (
+		filtDangling bool
+		dangling     bool
+	)
+	if values, ok := volFilters["dangling"]; ok {
+		filtDangling = true
+		for _, value := range values {
+			switch strings.ToLower(value) {
+			case "true", "1":
+				dangling = true
+			case "false", "0":
+				dangling = false
+			default:
+				return job.Errorf("Invalid filter 'dangling=%s'", value)
+			}
+		}
+	}
+
+	outs := engine.NewTable("Name", 0)
+	for _, v := range r.List() {
+		if filtDangling && (len(v.Containers()) == 0) != dangling {
+			continue
+		}
+		outs.Add(volumeEnv(v))
+	}
+	outs.Sort()
+	if _, err := outs.WriteListTo(job.Stdout); err != nil {
+		return job.Error(err)
+	}
+	return engine.StatusOK
+}
+
+func volumeEnv(v *Volume) *engine.Env {
+	out := &engine.Env{}
+	out.Set("Name", v.DisplayName())
+	out.Set("Id", v.ID)
+	out.Set("Driver", v.DriverName())
+	out.Set("Path", v.Path)
+	out.SetList("Containers", v.Containers())
+	return out
+}
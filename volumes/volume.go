@@ -12,6 +12,8 @@ import (
 
 type Volume struct {
 	ID          string
+	Name        string
+	Driver      string `json:",omitempty"`
 	Path        string
 	IsBindMount bool
 	Writable    bool
@@ -21,6 +23,59 @@ type Volume struct {
 	lock        sync.Mutex
 }
 
+// DisplayName returns the name the volume is known by to users: its name
+// for named volumes, or its ID otherwise.
+func (v *Volume) DisplayName() string {
+	if v.Name != "" {
+		return v.Name
+	}
+	return v.ID
+}
+
+// DriverName returns the name of the driver managing the volume's data.
+func (v *Volume) DriverName() string {
+	if v.Driver != "" {
+		return v.Driver
+	}
+	return DefaultDriver
+}
+
+// Mount asks the volume driver to make the volume available on the host
+// and returns its path there. Bind-mounts are always available. The path
+// of the volume follows the driver's, which may have changed since the
+// volume was restored.
+func (v *Volume) Mount() (string, error) {
+	if v.IsBindMount {
+		return v.Path, nil
+	}
+	driver, err := v.repository.getDriver(v.Driver)
+	if err != nil {
+		return "", err
+	}
+	path, err := driver.Mount(v.DisplayName())
+	if err != nil {
+		return "", err
+	}
+	if err := v.repository.move(v, path); err != nil {
+		driver.Unmount(v.DisplayName())
+		return "", err
+	}
+	return v.Path, nil
+}
+
+// Unmount tells the volume driver that the volume is no longer needed on
+// the host by the caller of a previous Mount.
+func (v *Volume) Unmount() error {
+	if v.IsBindMount {
+		return nil
+	}
+	driver, err := v.repository.getDriver(v.Driver)
+	if err != nil {
+		return err
+	}
+	return driver.Unmount(v.DisplayName())
+}
+
 func (v *Volume) IsDir() (bool, error) {
 	stat, err := os.Stat(v.Path)
 	if err != nil {